@@ -0,0 +1,130 @@
+// sentiric-user-service/cmd/audit/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/audit"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+const serviceName = "user-service-audit"
+
+const usage = `Kullanım: audit <komut>
+
+Komutlar:
+  query [-tenant T] [-actor A] [-since ZAMAN] [-until ZAMAN] [-limit N]
+             Audit kayıtlarını en yeniden eskiye listeler (ZAMAN: RFC3339)
+  verify     Hash zincirini baştan doğrular
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var filter audit.Filter
+	switch os.Args[1] {
+	case "query":
+		filter = parseFilter(os.Args[2:])
+	case "verify":
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	_ = godotenv.Load()
+	log := logger.New(
+		serviceName,
+		config.GetEnv("SERVICE_VERSION", "1.0.0"),
+		config.GetEnv("ENV", "production"),
+		config.GetEnv("NODE_HOSTNAME", "localhost"),
+		config.GetEnv("LOG_LEVEL", "info"),
+		config.GetEnv("LOG_FORMAT", "json"),
+	)
+
+	db, err := database.Connect(config.GetEnvOrFail("POSTGRES_URL"), 3, log)
+	if err != nil {
+		os.Exit(1)
+	}
+	defer db.Close()
+	store := audit.NewSQLStore(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if os.Args[1] == "verify" {
+		checked, err := store.Verify(ctx)
+		if err != nil {
+			log.Fatal().
+				Str("event", logger.EventCommandFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().
+					Str("command", "verify").
+					Int64("checked", checked)).
+				Msg("Audit zinciri doğrulanamadı")
+		}
+		fmt.Printf("%d kayıt doğrulandı, zincir sağlam\n", checked)
+		return
+	}
+
+	events, err := store.Query(ctx, filter)
+	if err != nil {
+		log.Fatal().
+			Str("event", logger.EventCommandFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("command", "query")).
+			Msg("Audit kayıtları sorgulanamadı")
+	}
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer out.Flush()
+	fmt.Fprintln(out, "ID\tZAMAN\tAKTÖR\tTENANT\tİŞLEM\tHEDEF\tÖNCE\tSONRA\tTRACE")
+	for _, e := range events {
+		fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\t%s/%s\t%s\t%s\t%s\n", e.ID, e.OccurredAt.Format(time.RFC3339), e.Actor, e.TenantID,
+			e.Action, e.TargetType, e.TargetID, orDash(e.Before), orDash(e.After), e.TraceID)
+	}
+}
+
+func parseFilter(args []string) audit.Filter {
+	var f audit.Filter
+	var since, until string
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	fs.StringVar(&f.TenantID, "tenant", "", "tenant ID")
+	fs.StringVar(&f.Actor, "actor", "", "istemci kimliği (SPIFFE ID veya CN)")
+	fs.StringVar(&since, "since", "", "bu zamandan itibaren (RFC3339)")
+	fs.StringVar(&until, "until", "", "bu zamana kadar, hariç (RFC3339)")
+	fs.IntVar(&f.Limit, "limit", 100, "en fazla kayıt sayısı")
+	fs.Parse(args)
+
+	var err error
+	if since != "" {
+		if f.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			fmt.Fprintf(os.Stderr, "Geçersiz -since: %s\n", since)
+			os.Exit(2)
+		}
+	}
+	if until != "" {
+		if f.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			fmt.Fprintf(os.Stderr, "Geçersiz -until: %s\n", until)
+			os.Exit(2)
+		}
+	}
+	return f
+}
+
+func orDash(b []byte) string {
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
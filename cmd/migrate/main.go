@@ -0,0 +1,94 @@
+// sentiric-user-service/cmd/migrate/main.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+const serviceName = "user-service-migrate"
+
+const usage = `Kullanım: migrate <komut>
+
+Komutlar:
+  up         Bekleyen tüm migration'ları uygular
+  down [N]   Son N migration'ı geri alır (varsayılan 1); down dosyası olmayan
+             migration'da (0001 baseline, 0005 audit) durur
+  version    Güncel şema versiyonunu yazdırır
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Migration yalnızca veritabanı bağlantısına ihtiyaç duyar; TLS gibi
+	// servis ayarlarını zorunlu kılan config.Load burada bilinçli olarak kullanılmaz.
+	_ = godotenv.Load()
+	log := logger.New(
+		serviceName,
+		config.GetEnv("SERVICE_VERSION", "1.0.0"),
+		config.GetEnv("ENV", "production"),
+		config.GetEnv("NODE_HOSTNAME", "localhost"),
+		config.GetEnv("LOG_LEVEL", "info"),
+		config.GetEnv("LOG_FORMAT", "json"),
+	)
+
+	db, err := database.Connect(config.GetEnvOrFail("POSTGRES_URL"), 3, log)
+	if err != nil {
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		log.Fatal().
+			Str("event", logger.EventCommandFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("command", os.Args[1])).
+			Msg("Migration'lar yüklenemedi")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	switch os.Args[1] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			if steps, err = strconv.Atoi(os.Args[2]); err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "Geçersiz adım sayısı: %s\n", os.Args[2])
+				os.Exit(2)
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "version":
+		var version int
+		if version, err = migrator.Version(ctx); err == nil {
+			fmt.Println(version)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal().
+			Str("event", logger.EventCommandFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("command", os.Args[1])).
+			Msgf("Migration komutu başarısız: %s", os.Args[1])
+	}
+}
@@ -26,6 +26,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Redaction, ilk log satırından önce ayarlanmalıdır.
+	err = logger.ConfigureRedaction(logger.RedactionPolicy{
+		Contact:     cfg.LogRedactContact,
+		Name:        cfg.LogRedactName,
+		SipUsername: cfg.LogRedactSipUsername,
+		Key:         cfg.LogRedactionKey,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Kritik Hata: Log redaction ayarlanamadı: %v\n", err)
+		os.Exit(1)
+	}
+
 	log := logger.New(
 		serviceName,
 		cfg.ServiceVersion,
@@ -0,0 +1,149 @@
+// sentiric-user-service/cmd/webhooks/main.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/webhook"
+)
+
+const serviceName = "user-service-webhooks"
+
+const usage = `Kullanım: webhooks <komut>
+
+Komutlar:
+  subscribe <tenant> <url> [tip,tip]   Abonelik oluşturur, id ve imza anahtarını yazdırır
+                                       (tip verilmezse tüm olaylar gönderilir)
+  subscriptions <tenant>               Tenant'ın aboneliklerini listeler
+  unsubscribe <id>                     Aboneliği ve teslimat geçmişini siler
+  deliveries [tenant] [durum]          Son teslimatları listeler (pending, delivered, dead)
+  attempts <teslimat_id>               Bir teslimatın denemelerini listeler
+  replay <teslimat_id>...              Teslimatları deneme sayacını sıfırlayarak tekrar kuyruğa alır
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	args := os.Args[2:]
+
+	_ = godotenv.Load()
+	log := logger.New(
+		serviceName,
+		config.GetEnv("SERVICE_VERSION", "1.0.0"),
+		config.GetEnv("ENV", "production"),
+		config.GetEnv("NODE_HOSTNAME", "localhost"),
+		config.GetEnv("LOG_LEVEL", "info"),
+		config.GetEnv("LOG_FORMAT", "json"),
+	)
+
+	db, err := database.Connect(config.GetEnvOrFail("POSTGRES_URL"), 3, log)
+	if err != nil {
+		os.Exit(1)
+	}
+	defer db.Close()
+	// http ve iç ağ adresli aboneliklere yalnızca geliştirme profillerinde izin verilir.
+	store := webhook.NewSQLStore(db, config.IsDevelopmentEnv(config.GetEnv("ENV", "production")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer out.Flush()
+
+	switch {
+	case os.Args[1] == "subscribe" && (len(args) == 2 || len(args) == 3):
+		var types []string
+		if len(args) == 3 {
+			types = strings.Split(args[2], ",")
+		}
+		var sub *webhook.Subscription
+		if sub, err = store.CreateSubscription(ctx, args[0], args[1], types); err == nil {
+			fmt.Fprintf(out, "id\t%s\nsecret\t%s\n", sub.ID, sub.Secret)
+		}
+	case os.Args[1] == "subscriptions" && len(args) == 1:
+		var subs []webhook.Subscription
+		if subs, err = store.ListSubscriptions(ctx, args[0]); err == nil {
+			fmt.Fprintln(out, "ID\tURL\tOLAYLAR\tAKTİF\tOLUŞTURULMA")
+			for _, s := range subs {
+				types := strings.Join(s.EventTypes, ",")
+				if types == "" {
+					types = "*"
+				}
+				fmt.Fprintf(out, "%s\t%s\t%s\t%t\t%s\n", s.ID, s.URL, types, s.Active, s.CreatedAt.Format(time.RFC3339))
+			}
+		}
+	case os.Args[1] == "unsubscribe" && len(args) == 1:
+		err = store.DeleteSubscription(ctx, args[0])
+	case os.Args[1] == "deliveries" && len(args) <= 2:
+		var filter webhook.DeliveryFilter
+		if len(args) > 0 {
+			filter.TenantID = args[0]
+		}
+		if len(args) > 1 {
+			filter.Status = args[1]
+		}
+		var deliveries []webhook.Delivery
+		if deliveries, err = store.ListDeliveries(ctx, filter); err == nil {
+			fmt.Fprintln(out, "ID\tTENANT\tOLAY\tDURUM\tDENEME\tSONRAKİ\tSON HATA")
+			for _, d := range deliveries {
+				fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%d\t%s\t%s\n", d.ID, d.TenantID, d.EventType, d.Status, d.Attempts, d.NextAttemptAt.Format(time.RFC3339), d.LastError)
+			}
+		}
+	case os.Args[1] == "attempts" && len(args) == 1:
+		var id int64
+		if id, err = parseID(args[0]); err != nil {
+			break
+		}
+		var attempts []webhook.Attempt
+		if attempts, err = store.ListAttempts(ctx, id); err == nil {
+			fmt.Fprintln(out, "DENEME\tZAMAN\tHTTP\tSÜRE\tHATA")
+			for _, a := range attempts {
+				fmt.Fprintf(out, "%d\t%s\t%d\t%s\t%s\n", a.Attempt, a.AttemptedAt.Format(time.RFC3339), a.StatusCode, a.Duration, a.Error)
+			}
+		}
+	case os.Args[1] == "replay" && len(args) > 0:
+		for _, arg := range args {
+			var id int64
+			if id, err = parseID(arg); err != nil {
+				break
+			}
+			if err = store.Replay(ctx, id); err != nil {
+				break
+			}
+			fmt.Fprintf(out, "%d\ttekrar kuyruğa alındı\n", id)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		out.Flush()
+		log.Fatal().
+			Str("event", logger.EventCommandFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("command", os.Args[1])).
+			Msgf("Webhook komutu başarısız: %s", os.Args[1])
+	}
+}
+
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("geçersiz teslimat id: %s", s)
+	}
+	return id, nil
+}
@@ -3,6 +3,7 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,13 +11,27 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/audit"
 	"github.com/sentiric/sentiric-user-service/internal/config"
 	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/health"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/outbox"
 	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/repository/audited"
+	"github.com/sentiric/sentiric-user-service/internal/repository/cached"
+	"github.com/sentiric/sentiric-user-service/internal/repository/instrumented"
+	"github.com/sentiric/sentiric-user-service/internal/repository/memory"
 	"github.com/sentiric/sentiric-user-service/internal/repository/postgres"
 	"github.com/sentiric/sentiric-user-service/internal/server"
 	"github.com/sentiric/sentiric-user-service/internal/service"
+	"github.com/sentiric/sentiric-user-service/internal/telemetry"
+	"github.com/sentiric/sentiric-user-service/internal/webhook"
 )
 
 type App struct {
@@ -29,71 +44,452 @@ func NewApp(cfg *config.Config, log zerolog.Logger) *App {
 }
 
 func (a *App) Run() {
-	// 1. Altyapı Bağlantısı
-	db, err := database.Connect(a.Cfg.DatabaseURL, a.Cfg.MaxDBRetries, a.Log)
+	// 0. Tracing (OTLP / stdout)
+	shutdownTracing, err := telemetry.Setup(context.Background(), telemetry.Options{
+		ServiceName:  "user-service",
+		Version:      a.Cfg.ServiceVersion,
+		Env:          a.Cfg.Env,
+		Exporter:     a.Cfg.TracesExporter,
+		OtlpEndpoint: a.Cfg.OtlpEndpoint,
+		OtlpInsecure: a.Cfg.OtlpInsecure,
+		SampleRatio:  a.Cfg.TraceSampleRatio,
+	})
 	if err != nil {
-		os.Exit(1)
+		a.Log.Fatal().
+			Str("event", logger.EventStartupFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("exporter", a.Cfg.TracesExporter)).
+			Msg("Tracing başlatılamadı")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			a.Log.Error().
+				Str("event", logger.EventSystemShutdown).
+				Err(err).
+				Dict("attributes", zerolog.Dict()).
+				Msg("Trace exporter düzgün kapatılamadı")
+		}
+	}()
+
+	// 1. Altyapı Bağlantısı + Repository
+	// Replika kontrolleri ve NOTIFY dinleyicisi servis kapanana kadar arka planda çalışır.
+	repoCtx, stopRepo := context.WithCancel(context.Background())
+	defer stopRepo()
+
+	var db *sql.DB
+	var userRepo repository.UserRepository
+	if a.Cfg.DBDriver == config.DBDriverMemory {
+		a.Log.Warn().
+			Str("event", logger.EventConfigWarning).
+			Dict("attributes", zerolog.Dict().
+				Str("setting", "DB_DRIVER").
+				Strs("tenants", a.Cfg.MemoryTenants)).
+			Msg("In-memory repository kullanılıyor, veriler kalıcı değil (yalnızca demo/test için)")
+		userRepo = instrumented.New(memory.New(a.Cfg.MemoryTenants...))
+		if a.Cfg.AuditEnabled {
+			userRepo = audited.New(userRepo, audit.NewMemoryStore(), a.Log)
+		}
+		if a.Cfg.OutboxPublisher != config.OutboxPublisherNone || a.Cfg.WebhooksEnabled {
+			a.Log.Warn().
+				Str("event", logger.EventConfigWarning).
+				Dict("attributes", zerolog.Dict().Str("setting", "DB_DRIVER")).
+				Msg("Memory sürücüsünde outbox ve webhook yoktur, OUTBOX_PUBLISHER ve WEBHOOKS_ENABLED yok sayılıyor")
+		}
+	} else {
+		var closeDB func()
+		db, userRepo, closeDB = a.openPostgres(repoCtx)
+		defer closeDB()
 	}
-	defer db.Close()
 
 	// 2. DI: Repository -> Service -> Handler
-	var userRepo repository.UserRepository = postgres.NewPostgresRepository(db, a.Log)
+	metrics.RegisterAgentStatus(userRepo, a.Log)
 	userService := service.NewUserService(userRepo, a.Cfg, a.Log)
 
-	// 3. Server Katmanı
-	grpcServer := server.NewGrpcServer(userService, a.Cfg, a.Log)
-	httpServer := a.startHttpServer(a.Cfg.HttpPort)
+	// 3. Health Checker (gRPC health + /readyz aynı durumu paylaşır)
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	checker := health.NewChecker(db, a.Cfg.HealthCheckInterval, a.Cfg.HealthCheckTimeout, a.Log)
+	go checker.Run(healthCtx)
 
-	// 4. Sunucuyu Başlat
+	// 4. Server Katmanı
+	grpcServer := server.NewGrpcServer(userService, checker.GrpcServer(), a.Cfg, a.Log)
+	httpServer := a.startHttpServer(a.Cfg.HttpPort, checker)
+
+	// 5. Sunucuyu Başlat
 	go func() {
-		a.Log.Info().Str("port", a.Cfg.GRPCPort).Msg("gRPC sunucusu dinleniyor...")
+		a.Log.Info().
+			Str("event", logger.EventSystemStartup).
+			Dict("attributes", zerolog.Dict().
+				Str("component", "grpc").
+				Str("port", a.Cfg.GRPCPort)).
+			Msg("gRPC sunucusu dinleniyor...")
 		if err := server.Start(grpcServer, a.Cfg.GRPCPort); err != nil && err.Error() != "http: Server closed" {
-			a.Log.Error().Err(err).Msg("gRPC sunucusu başlatılamadı")
+			a.Log.Error().
+				Str("event", logger.EventStartupFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("port", a.Cfg.GRPCPort)).
+				Msg("gRPC sunucusu başlatılamadı")
 		}
 	}()
 
-	// 5. Graceful Shutdown
-	a.waitForShutdown(grpcServer, httpServer)
+	// 6. Graceful Shutdown
+	a.waitForShutdown(grpcServer, httpServer, checker)
+}
+
+// openPostgres, primary (ve varsa replika) bağlantılarını açar, gerekirse migration'ları
+// uygular ve ölçümleme/audit/önbellek katmanlarıyla sarılmış repository'yi döner.
+// pgxpool sürücüsünde *sql.DB havuzun üzerine açılır; migration ve health check
+// ayrı bağlantı açmadan aynı havuzu kullanır. Dönen fonksiyon tüm bağlantıları kapatır.
+func (a *App) openPostgres(ctx context.Context) (*sql.DB, repository.UserRepository, func()) {
+	var closers []func()
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var db *sql.DB
+	var pool *pgxpool.Pool
+	var err error
+	if a.Cfg.DBDriver == config.DBDriverPgxPool {
+		pool, err = database.ConnectPool(context.Background(), a.Cfg.DatabaseURL, a.poolOptions(), a.Cfg.MaxDBRetries, a.Log)
+		if err != nil {
+			a.Log.Fatal().
+				Str("event", logger.EventStartupFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("driver", a.Cfg.DBDriver)).
+				Msg("Veritabanı havuzu oluşturulamadı")
+		}
+		closers = append(closers, pool.Close)
+		db = stdlib.OpenDBFromPool(pool)
+	} else {
+		db, err = database.Connect(a.Cfg.DatabaseURL, a.Cfg.MaxDBRetries, a.Log)
+		if err != nil {
+			os.Exit(1)
+		}
+	}
+	closers = append(closers, func() { db.Close() })
+
+	if a.Cfg.MigrateOnStartup {
+		if err := a.migrate(db); err != nil {
+			a.Log.Fatal().
+				Str("event", logger.EventStartupFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict()).
+				Msg("Veritabanı migration'ları uygulanamadı")
+		}
+	}
+
+	replicaOpts := postgres.ReplicaOptions{
+		MaxLag:        a.Cfg.DBReplicaMaxLag,
+		CheckInterval: a.Cfg.DBReplicaCheckInterval,
+		CheckTimeout:  a.Cfg.HealthCheckTimeout,
+	}
+
+	var userRepo repository.UserRepository
+	if pool != nil {
+		replicas := make([]*pgxpool.Pool, 0, len(a.Cfg.DBReplicaURLs))
+		for _, url := range a.Cfg.DBReplicaURLs {
+			replica, err := database.OpenReplicaPool(ctx, url, a.poolOptions())
+			if err != nil {
+				a.Log.Fatal().
+					Str("event", logger.EventStartupFailed).
+					Err(err).
+					Dict("attributes", zerolog.Dict().Str("component", "replicas")).
+					Msg("Okuma replikası yapılandırılamadı")
+			}
+			closers = append(closers, replica.Close)
+			replicas = append(replicas, replica)
+		}
+		userRepo = postgres.NewPoolRepositoryWithReplicas(ctx, pool, replicas, replicaOpts, a.Log)
+		metrics.RegisterPoolStats(pool)
+	} else {
+		replicas := make([]*sql.DB, 0, len(a.Cfg.DBReplicaURLs))
+		for _, url := range a.Cfg.DBReplicaURLs {
+			replica, err := database.OpenReplica(url)
+			if err != nil {
+				a.Log.Fatal().
+					Str("event", logger.EventStartupFailed).
+					Err(err).
+					Dict("attributes", zerolog.Dict().Str("component", "replicas")).
+					Msg("Okuma replikası yapılandırılamadı")
+			}
+			closers = append(closers, func() { replica.Close() })
+			replicas = append(replicas, replica)
+		}
+		userRepo = postgres.NewPostgresRepositoryWithReplicas(ctx, db, replicas, replicaOpts, a.Log)
+		metrics.RegisterDBStats(db)
+	}
+	if len(a.Cfg.DBReplicaURLs) > 0 {
+		a.Log.Info().
+			Str("event", logger.EventSystemStartup).
+			Dict("attributes", zerolog.Dict().
+				Str("component", "replicas").
+				Int("replicas", len(a.Cfg.DBReplicaURLs)).
+				Dur("max_lag_ms", a.Cfg.DBReplicaMaxLag)).
+			Msg("Salt okunur sorgular replikalara yönlendirilecek")
+	}
+
+	userRepo = instrumented.New(userRepo)
+	if a.Cfg.AuditEnabled {
+		auditStore := audit.NewSQLStore(db)
+		checkCtx, cancel := context.WithTimeout(ctx, a.Cfg.HealthCheckTimeout)
+		err := auditStore.CheckSchema(checkCtx)
+		cancel()
+		if err != nil {
+			a.Log.Fatal().
+				Str("event", logger.EventStartupFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("setting", "AUDIT_ENABLED")).
+				Msg("AUDIT_ENABLED=true fakat audit tablosu hazır değil; migration'ları uygulayın (DB_MIGRATE_ON_STARTUP=true veya cmd/migrate) ya da AUDIT_ENABLED=false yapın")
+		}
+		userRepo = audited.New(userRepo, auditStore, a.Log)
+	}
+	if a.Cfg.CacheEnabled && a.cacheTriggersReady(ctx, db) {
+		cache := cached.New(userRepo, cached.Options{
+			MaxEntries:  a.Cfg.CacheMaxEntries,
+			TTL:         a.Cfg.CacheTTL,
+			NegativeTTL: a.Cfg.CacheNegativeTTL,
+		}, a.Log)
+		// Diğer replikalardaki yazmalar NOTIFY ile gelir; bağlantı koptuğunda önbellek temizlenir.
+		go database.Listen(ctx, a.Cfg.DatabaseURL, database.CacheChannel, cache.Purge, cache.HandleNotification, a.Log)
+		userRepo = cache
+	}
+
+	switch {
+	case a.Cfg.OutboxPublisher != config.OutboxPublisherNone:
+		closers = append(closers, a.startOutboxRelay(ctx, db))
+	case a.Cfg.OutboxRetention > 0:
+		closers = append(closers, a.startOutboxPruner(ctx, db))
+	}
+	if a.Cfg.WebhooksEnabled {
+		closers = append(closers, a.startWebhookDispatcher(ctx, db))
+	}
+	return db, userRepo, closeAll
+}
+
+// cacheTriggersReady, 0002 migration'ının NOTIFY tetikleyicilerini denetler. Tetikleyiciler
+// yoksa diğer replikalardaki yazmalar önbelleği hiç temizlemez; silinen bir SIP kimlik bilgisi
+// CACHE_TTL boyunca doğrulanmaya devam ederdi. Bu durumda önbellek devre dışı bırakılır.
+func (a *App) cacheTriggersReady(ctx context.Context, db *sql.DB) bool {
+	checkCtx, cancel := context.WithTimeout(ctx, a.Cfg.HealthCheckTimeout)
+	defer cancel()
+	if err := database.CheckCacheTriggers(checkCtx, db); err != nil {
+		a.Log.Warn().
+			Str("event", logger.EventConfigWarning).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("setting", "CACHE_ENABLED")).
+			Msg("Önbellek tetikleyicileri doğrulanamadı, önbellek devre dışı; migration'ları uygulayın (DB_MIGRATE_ON_STARTUP=true veya cmd/migrate)")
+		return false
+	}
+	return true
 }
 
-func (a *App) startHttpServer(port string) *http.Server {
+// startOutboxRelay, outbox'taki domain olaylarını yapılandırılan publisher'a ileten relay'i başlatır.
+// Relay, yeni olay NOTIFY'ı geldiğinde uyanır; bildirim kaçarsa poll aralığında yine yakalar.
+func (a *App) startOutboxRelay(ctx context.Context, db *sql.DB) func() {
+	pub, err := a.outboxPublisher()
+	if err != nil {
+		a.Log.Fatal().
+			Str("event", logger.EventStartupFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("publisher", a.Cfg.OutboxPublisher)).
+			Msg("Outbox publisher oluşturulamadı")
+	}
+	relay := outbox.NewRelay(outbox.NewSQLStore(db), pub, outbox.RelayOptions{
+		BatchSize:    a.Cfg.OutboxBatchSize,
+		PollInterval: a.Cfg.OutboxPollInterval,
+		Retention:    a.Cfg.OutboxRetention,
+	}, a.Log)
+
+	relayCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(relayCtx)
+	}()
+	go database.Listen(relayCtx, a.Cfg.DatabaseURL, database.OutboxChannel, relay.Wake, func(string) { relay.Wake() }, a.Log)
+
+	a.Log.Info().
+		Str("event", logger.EventSystemStartup).
+		Dict("attributes", zerolog.Dict().
+			Str("component", "outbox_relay").
+			Str("publisher", a.Cfg.OutboxPublisher)).
+		Msg("Outbox relay başlatıldı")
+	return func() {
+		stop()
+		<-done
+		if err := pub.Close(); err != nil {
+			a.Log.Warn().
+				Str("event", logger.EventSystemShutdown).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("publisher", a.Cfg.OutboxPublisher)).
+				Msg("Outbox publisher düzgün kapatılamadı")
+		}
+	}
+}
+
+// startOutboxPruner, publisher yokken outbox'ta biriken (hiç yayınlanmayacak) olayları
+// OUTBOX_RETENTION sonunda siler.
+func (a *App) startOutboxPruner(ctx context.Context, db *sql.DB) func() {
+	pruner := outbox.NewPruner(outbox.NewSQLStore(db), a.Cfg.OutboxRetention, a.Log)
+
+	pruneCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pruner.Run(pruneCtx)
+	}()
+	return func() {
+		stop()
+		<-done
+	}
+}
+
+// startWebhookDispatcher, tenant webhook teslimatlarını gönderen dispatcher'ı başlatır.
+func (a *App) startWebhookDispatcher(ctx context.Context, db *sql.DB) func() {
+	dispatcher := webhook.NewDispatcher(webhook.NewSQLStore(db, a.Cfg.IsDevelopment()), webhook.Options{
+		PollInterval:         a.Cfg.WebhookPollInterval,
+		Timeout:              a.Cfg.WebhookTimeout,
+		MaxAttempts:          a.Cfg.WebhookMaxAttempts,
+		Retention:            a.Cfg.WebhookRetention,
+		AllowInsecureTargets: a.Cfg.IsDevelopment(),
+	}, a.Log)
+
+	dispatchCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dispatcher.Run(dispatchCtx)
+	}()
+	go database.Listen(dispatchCtx, a.Cfg.DatabaseURL, database.WebhookChannel, dispatcher.Wake, func(string) { dispatcher.Wake() }, a.Log)
+
+	a.Log.Info().
+		Str("event", logger.EventSystemStartup).
+		Dict("attributes", zerolog.Dict().
+			Str("component", "webhook_dispatcher").
+			Int("max_attempts", a.Cfg.WebhookMaxAttempts)).
+		Msg("Webhook dispatcher başlatıldı")
+	return func() {
+		stop()
+		<-done
+	}
+}
+
+func (a *App) outboxPublisher() (outbox.Publisher, error) {
+	switch a.Cfg.OutboxPublisher {
+	case config.OutboxPublisherFile:
+		return outbox.NewFilePublisher(a.Cfg.OutboxFilePath)
+	case config.OutboxPublisherAMQP:
+		return outbox.NewAMQPPublisher(a.Cfg.OutboxAMQPURL, a.Cfg.OutboxAMQPExchange, a.Log)
+	default:
+		return outbox.NewWriterPublisher(os.Stdout), nil
+	}
+}
+
+// poolOptions, pgxpool ayarlarını config'den okur; primary ve replikalar aynı ayarları kullanır.
+func (a *App) poolOptions() database.PoolOptions {
+	return database.PoolOptions{
+		MaxConns:        int32(a.Cfg.DBPoolMaxConns),
+		MinConns:        int32(a.Cfg.DBPoolMinConns),
+		MaxConnIdleTime: a.Cfg.DBPoolMaxIdleTime,
+		MaxConnLifetime: a.Cfg.DBPoolMaxLifetime,
+		ExecMode:        a.Cfg.DBQueryExecMode,
+	}
+}
+
+// migrate, başlangıçta bekleyen migration'ları uygular (DB_MIGRATE_ON_STARTUP=true).
+// Ayrı bir adım olarak çalıştırmak için cmd/migrate kullanılabilir.
+func (a *App) migrate(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	migrator, err := database.NewMigrator(db, a.Log)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Up(ctx); err != nil {
+		return err
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	a.Log.Info().
+		Str("event", logger.EventDBMigrationApplied).
+		Dict("attributes", zerolog.Dict().Int("schema_version", version)).
+		Msg("Veritabanı şeması güncel")
+	return nil
+}
+
+func (a *App) startHttpServer(port string, checker *health.Checker) *http.Server {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"status": "ok"}`)
-	})
+	// /health geriye dönük uyumluluk için korunur ve artık /readyz ile aynı durumu yansıtır.
+	mux.HandleFunc("/health", checker.ReadyzHandler())
+	mux.HandleFunc("/livez", checker.LivezHandler())
+	mux.HandleFunc("/readyz", checker.ReadyzHandler())
+	mux.Handle("/metrics", promhttp.Handler())
 
 	addr := fmt.Sprintf(":%s", port)
 	srv := &http.Server{Addr: addr, Handler: mux}
 
 	go func() {
-		a.Log.Info().Str("port", port).Msg("HTTP sunucusu (health) dinleniyor")
+		a.Log.Info().
+			Str("event", logger.EventSystemStartup).
+			Dict("attributes", zerolog.Dict().
+				Str("component", "http").
+				Str("port", port)).
+			Msg("HTTP sunucusu (health, metrics) dinleniyor")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			a.Log.Fatal().Err(err).Msg("HTTP sunucusu başlatılamadı")
+			a.Log.Fatal().
+				Str("event", logger.EventStartupFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("port", port)).
+				Msg("HTTP sunucusu başlatılamadı")
 		}
 	}()
 	return srv
 }
 
-func (a *App) waitForShutdown(grpcSrv *server.GrpcServer, httpSrv *http.Server) {
+func (a *App) waitForShutdown(grpcSrv *server.GrpcServer, httpSrv *http.Server, checker *health.Checker) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	a.Log.Warn().Msg("Kapatma sinyali alındı, servisler durduruluyor...")
+	a.Log.Warn().
+		Str("event", logger.EventSystemShutdown).
+		Dict("attributes", zerolog.Dict().Str("phase", "signal")).
+		Msg("Kapatma sinyali alındı, servisler durduruluyor...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Önce NOT_SERVING'e geç ki load balancer yeni istek yönlendirmesin.
+	checker.Shutdown()
+
 	server.Stop(grpcSrv)
-	a.Log.Info().Msg("gRPC sunucusu durduruldu.")
+	a.Log.Info().
+		Str("event", logger.EventSystemShutdown).
+		Dict("attributes", zerolog.Dict().Str("component", "grpc")).
+		Msg("gRPC sunucusu durduruldu.")
 
 	if err := httpSrv.Shutdown(ctx); err != nil {
-		a.Log.Error().Err(err).Msg("HTTP sunucusu düzgün kapatılamadı.")
+		a.Log.Error().
+			Str("event", logger.EventSystemShutdown).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("component", "http")).
+			Msg("HTTP sunucusu düzgün kapatılamadı.")
 	} else {
-		a.Log.Info().Msg("HTTP sunucusu durduruldu.")
+		a.Log.Info().
+			Str("event", logger.EventSystemShutdown).
+			Dict("attributes", zerolog.Dict().Str("component", "http")).
+			Msg("HTTP sunucusu durduruldu.")
 	}
 
-	a.Log.Info().Msg("Servis başarıyla durduruldu.")
+	a.Log.Info().
+		Str("event", logger.EventSystemShutdown).
+		Dict("attributes", zerolog.Dict().Str("phase", "done")).
+		Msg("Servis başarıyla durduruldu.")
 }
@@ -0,0 +1,165 @@
+// sentiric-user-service/internal/audit/audit.go
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// Audit edilen işlemler (audit_events.action).
+const (
+	ActionUserCreate          = "user.create"
+	ActionSipCredentialCreate = "sip_credential.create"
+	ActionSipCredentialDelete = "sip_credential.delete"
+	ActionAgentProfileUpsert  = "agent_profile.upsert"
+)
+
+// Hedef tipleri (audit_events.target_type).
+const (
+	TargetUser          = "user"
+	TargetSipCredential = "sip_credential"
+	TargetAgentProfile  = "agent_profile"
+)
+
+// GenesisHash, zincirin ilk kaydının PrevHash değeridir.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// defaultQueryLimit: Filter.Limit verilmediğinde dönen en fazla kayıt sayısı.
+const defaultQueryLimit = 100
+
+// ErrChainBroken, bir kaydın hash'inin veya önceki kayda bağlantısının tutmadığını belirtir.
+var ErrChainBroken = errors.New("audit hash zinciri bozuk")
+
+// Event, tek bir yönetimsel değişikliğin kaydıdır.
+type Event struct {
+	ID         int64
+	OccurredAt time.Time
+	// Actor, isteği yapan istemcinin sertifika kimliğidir (authz.Identity.String).
+	Actor      string
+	TenantID   string
+	Action     string
+	TargetType string
+	TargetID   string
+	// Before ve After yalnızca değişen alanları içerir; oluşturmada Before, silmede After boştur.
+	Before  json.RawMessage
+	After   json.RawMessage
+	TraceID string
+
+	PrevHash string
+	Hash     string
+}
+
+// Filter, audit sorgusunu daraltır. Boş alanlar filtre uygulamaz; Until hariçtir.
+type Filter struct {
+	TenantID string
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// Store, audit kayıtlarını saklar.
+type Store interface {
+	// Append, kaydı zincirin sonuna ekler ve ID, zaman ve hash alanları doldurulmuş halini döner.
+	Append(ctx context.Context, e Event) (Event, error)
+	// Query, filtreye uyan kayıtları en yeniden eskiye döner.
+	Query(ctx context.Context, f Filter) ([]Event, error)
+	// Verify, tüm zinciri baştan doğrular ve kontrol edilen kayıt sayısını döner.
+	// Zincir bozuksa ErrChainBroken ile sarılmış bir hata döner.
+	Verify(ctx context.Context) (int64, error)
+}
+
+// TxStore, kaydı değişikliği yapan transaction içinde yazabilen Store'dur. Kayıt ve değişiklik
+// birlikte commit edilir; biri yazılamazsa ikisi de geri alınır.
+type TxStore interface {
+	Store
+	// AppendTx, kaydı tx içinde zincirin sonuna ekler.
+	AppendTx(ctx context.Context, tx repository.Tx, e Event) (Event, error)
+}
+
+// Changes, before ve after anlık görüntülerinden yalnızca değişen alanları JSON olarak döner.
+// Anlık görüntülerden biri nil ise diğeri olduğu gibi yazılır.
+func Changes(before, after map[string]any) (json.RawMessage, json.RawMessage) {
+	if before != nil && after != nil {
+		b, a := map[string]any{}, map[string]any{}
+		for k, v := range before {
+			if !reflect.DeepEqual(v, after[k]) {
+				b[k] = v
+			}
+		}
+		for k, v := range after {
+			if !reflect.DeepEqual(v, before[k]) {
+				a[k] = v
+			}
+		}
+		before, after = b, a
+	}
+	return marshalSnapshot(before), marshalSnapshot(after)
+}
+
+func marshalSnapshot(m map[string]any) json.RawMessage {
+	if m == nil {
+		return nil
+	}
+	// map anahtarları sıralı yazılır; aynı görüntü her zaman aynı metni üretir.
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// seal, kaydı prev'e bağlar: zamanı saklanan hassasiyete indirger ve hash'i hesaplar.
+func seal(e *Event, prev string) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	// PostgreSQL mikrosaniye saklar; hash okunan değerle aynı kalmalıdır.
+	e.OccurredAt = e.OccurredAt.UTC().Truncate(time.Microsecond)
+	if len(e.Before) == 0 {
+		e.Before = nil
+	}
+	if len(e.After) == 0 {
+		e.After = nil
+	}
+	e.PrevHash = prev
+	e.Hash = computeHash(*e)
+}
+
+// computeHash, kaydın ID dışındaki tüm alanlarını sabit sırayla kodlayıp SHA-256 alır.
+func computeHash(e Event) string {
+	canonical, _ := json.Marshal(struct {
+		PrevHash   string          `json:"prev_hash"`
+		OccurredAt string          `json:"occurred_at"`
+		Actor      string          `json:"actor"`
+		TenantID   string          `json:"tenant_id"`
+		Action     string          `json:"action"`
+		TargetType string          `json:"target_type"`
+		TargetID   string          `json:"target_id"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		TraceID    string          `json:"trace_id"`
+	}{e.PrevHash, e.OccurredAt.UTC().Format(time.RFC3339Nano), e.Actor, e.TenantID, e.Action,
+		e.TargetType, e.TargetID, e.Before, e.After, e.TraceID})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyLink, kaydın prev'e bağlı olduğunu ve hash'inin içeriğiyle tuttuğunu doğrular.
+func verifyLink(prev string, e Event) error {
+	if e.PrevHash != prev {
+		return fmt.Errorf("%w: id=%d önceki kayda bağlı değil", ErrChainBroken, e.ID)
+	}
+	if computeHash(e) != e.Hash {
+		return fmt.Errorf("%w: id=%d içeriği değiştirilmiş", ErrChainBroken, e.ID)
+	}
+	return nil
+}
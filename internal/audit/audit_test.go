@@ -0,0 +1,133 @@
+// sentiric-user-service/internal/audit/audit_test.go
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	before, after := Changes(
+		map[string]any{"status": "OFFLINE", "display_name": "Ayşe", "max_concurrent_calls": int32(1)},
+		map[string]any{"status": "ONLINE", "display_name": "Ayşe", "max_concurrent_calls": int32(1)},
+	)
+	if string(before) != `{"status":"OFFLINE"}` || string(after) != `{"status":"ONLINE"}` {
+		t.Fatalf("Changes = %s, %s", before, after)
+	}
+
+	before, after = Changes(nil, map[string]any{"user_id": "u1", "sip_username": "1001"})
+	if before != nil || string(after) != `{"sip_username":"1001","user_id":"u1"}` {
+		t.Fatalf("create Changes = %s, %s", before, after)
+	}
+
+	before, after = Changes(map[string]any{"user_id": "u1"}, nil)
+	if string(before) != `{"user_id":"u1"}` || after != nil {
+		t.Fatalf("delete Changes = %s, %s", before, after)
+	}
+}
+
+func appendEvents(t *testing.T, s Store, events ...Event) []Event {
+	t.Helper()
+	out := make([]Event, len(events))
+	for i, e := range events {
+		var err error
+		if out[i], err = s.Append(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out
+}
+
+func TestMemoryStoreChain(t *testing.T) {
+	s := NewMemoryStore()
+	events := appendEvents(t, s,
+		Event{Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetType: TargetUser, TargetID: "u1", After: []byte(`{"user_type":"agent"}`)},
+		Event{Actor: "svc-b", TenantID: "t1", Action: ActionSipCredentialCreate, TargetType: TargetSipCredential, TargetID: "1001"},
+		Event{Actor: "svc-a", TenantID: "t2", Action: ActionUserCreate, TargetType: TargetUser, TargetID: "u2"},
+	)
+
+	if events[0].PrevHash != GenesisHash {
+		t.Fatalf("first prev = %s", events[0].PrevHash)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].PrevHash != events[i-1].Hash {
+			t.Fatalf("event %d not linked to %d", i, i-1)
+		}
+	}
+	if checked, err := s.Verify(context.Background()); err != nil || checked != 3 {
+		t.Fatalf("Verify = %d, %v", checked, err)
+	}
+}
+
+func TestMemoryStoreVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(events []Event) []Event
+	}{
+		{"modified field", func(e []Event) []Event { e[1].Actor = "someone-else"; return e }},
+		{"modified diff", func(e []Event) []Event { e[0].After = []byte(`{"user_type":"admin"}`); return e }},
+		{"deleted row", func(e []Event) []Event { return append(e[:1], e[2:]...) }},
+		{"reordered rows", func(e []Event) []Event { e[1], e[2] = e[2], e[1]; return e }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			appendEvents(t, s,
+				Event{Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetID: "u1", After: []byte(`{"user_type":"agent"}`)},
+				Event{Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetID: "u2"},
+				Event{Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetID: "u3"},
+			)
+			s.events = tt.tamper(s.events)
+
+			if _, err := s.Verify(context.Background()); !errors.Is(err, ErrChainBroken) {
+				t.Fatalf("Verify = %v, want ErrChainBroken", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreQuery(t *testing.T) {
+	s := NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	appendEvents(t, s,
+		Event{OccurredAt: base, Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetID: "u1"},
+		Event{OccurredAt: base.Add(time.Hour), Actor: "svc-b", TenantID: "t1", Action: ActionUserCreate, TargetID: "u2"},
+		Event{OccurredAt: base.Add(2 * time.Hour), Actor: "svc-a", TenantID: "t2", Action: ActionUserCreate, TargetID: "u3"},
+		Event{OccurredAt: base.Add(3 * time.Hour), Actor: "svc-a", TenantID: "t1", Action: ActionUserCreate, TargetID: "u4"},
+	)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all, newest first", Filter{}, []string{"u4", "u3", "u2", "u1"}},
+		{"tenant", Filter{TenantID: "t1"}, []string{"u4", "u2", "u1"}},
+		{"actor", Filter{Actor: "svc-a"}, []string{"u4", "u3", "u1"}},
+		{"tenant and actor", Filter{TenantID: "t1", Actor: "svc-a"}, []string{"u4", "u1"}},
+		{"time window", Filter{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}, []string{"u3", "u2"}},
+		{"limit", Filter{Limit: 2}, []string{"u4", "u3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := s.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range events {
+				got = append(got, e.TargetID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// sentiric-user-service/internal/audit/memory.go
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore, audit zincirini süreç belleğinde tutar (DB_DRIVER=memory ve testler için).
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryStore, boş bir MemoryStore oluşturur.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Append(_ context.Context, e Event) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := GenesisHash
+	if n := len(s.events); n > 0 {
+		prev = s.events[n-1].Hash
+	}
+	e.ID = int64(len(s.events) + 1)
+	seal(&e, prev)
+	s.events = append(s.events, e)
+	return e, nil
+}
+
+func (s *MemoryStore) Query(_ context.Context, f Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	var out []Event
+	for i := len(s.events) - 1; i >= 0 && len(out) < limit; i-- {
+		e := s.events[i]
+		switch {
+		case f.TenantID != "" && e.TenantID != f.TenantID,
+			f.Actor != "" && e.Actor != f.Actor,
+			!f.Since.IsZero() && e.OccurredAt.Before(f.Since),
+			!f.Until.IsZero() && !e.OccurredAt.Before(f.Until):
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Verify(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := GenesisHash
+	for i, e := range s.events {
+		if err := verifyLink(prev, e); err != nil {
+			return int64(i), err
+		}
+		prev = e.Hash
+	}
+	return int64(len(s.events)), nil
+}
@@ -0,0 +1,167 @@
+// sentiric-user-service/internal/audit/store.go
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// auditLockID: Eşzamanlı yazmaların zinciri çatallamaması için transaction seviyesinde alınan
+// advisory lock anahtarı (migrationLockID ile çakışmaz).
+const auditLockID = 0x5e17_0002
+
+// verifyPageSize: Verify'ın tek sorguda okuduğu kayıt sayısı.
+const verifyPageSize = 1000
+
+const (
+	queryAuditTableExists = `SELECT to_regclass('audit_events') IS NOT NULL`
+	queryLastAuditHash    = `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`
+	queryInsertAudit      = `
+		INSERT INTO audit_events (occurred_at, actor, tenant_id, action, target_type, target_id, before, after, trace_id, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+	queryAuditEvents = `
+		SELECT ` + auditColumns + ` FROM audit_events
+		WHERE ($1::text = '' OR tenant_id = $1)
+		  AND ($2::text = '' OR actor = $2)
+		  AND ($3::timestamptz IS NULL OR occurred_at >= $3)
+		  AND ($4::timestamptz IS NULL OR occurred_at < $4)
+		ORDER BY id DESC
+		LIMIT $5`
+	queryAuditChain = `SELECT ` + auditColumns + ` FROM audit_events WHERE id > $1 ORDER BY id LIMIT $2`
+
+	auditColumns = `id, occurred_at, actor, tenant_id, action, target_type, target_id, before, after, trace_id, prev_hash, hash`
+)
+
+// SQLStore, audit_events tablosunu database/sql üzerinden yönetir.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore, bir SQLStore oluşturur.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// CheckSchema, audit_events tablosunun var olduğunu doğrular. AUDIT_ENABLED=true iken tablo
+// yoksa her değişiklik başarısız olacağından servis başlangıçta durdurulur.
+func (s *SQLStore) CheckSchema(ctx context.Context) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, queryAuditTableExists).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("audit_events tablosu yok (0005 migration'ı uygulanmamış)")
+	}
+	return nil
+}
+
+// Append, kaydı kendi transaction'ında zincire ekler.
+func (s *SQLStore) Append(ctx context.Context, e Event) (Event, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Event{}, err
+	}
+	defer tx.Rollback()
+
+	if e, err = s.AppendTx(ctx, sqlTx{tx}, e); err != nil {
+		return Event{}, err
+	}
+	return e, tx.Commit()
+}
+
+// AppendTx, son hash'i advisory lock altında okuyup kaydı verilen transaction içinde ona bağlar.
+// Lock commit'e kadar tutulduğu için id sırası zincir sırasıyla aynıdır; kayıt, transaction'daki
+// değişiklikle birlikte commit edilir ya da birlikte geri alınır.
+func (s *SQLStore) AppendTx(ctx context.Context, tx repository.Tx, e Event) (Event, error) {
+	if err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", auditLockID); err != nil {
+		return Event{}, err
+	}
+	prev := GenesisHash
+	if err := tx.QueryRow(ctx, queryLastAuditHash).Scan(&prev); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Event{}, err
+	}
+
+	seal(&e, prev)
+	err := tx.QueryRow(ctx, queryInsertAudit, e.OccurredAt, e.Actor, e.TenantID, e.Action, e.TargetType, e.TargetID,
+		nullJSON(e.Before), nullJSON(e.After), e.TraceID, e.PrevHash, e.Hash).Scan(&e.ID)
+	if err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func (s *SQLStore) Query(ctx context.Context, f Filter) ([]Event, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	return s.query(ctx, queryAuditEvents, f.TenantID, f.Actor, nullTime(f.Since), nullTime(f.Until), limit)
+}
+
+func (s *SQLStore) Verify(ctx context.Context) (int64, error) {
+	var checked, lastID int64
+	prev := GenesisHash
+	for {
+		page, err := s.query(ctx, queryAuditChain, lastID, verifyPageSize)
+		if err != nil {
+			return checked, err
+		}
+		for _, e := range page {
+			if err := verifyLink(prev, e); err != nil {
+				return checked, err
+			}
+			prev, lastID = e.Hash, e.ID
+			checked++
+		}
+		if len(page) < verifyPageSize {
+			return checked, nil
+		}
+	}
+}
+
+func (s *SQLStore) query(ctx context.Context, query string, args ...any) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.TenantID, &e.Action, &e.TargetType, &e.TargetID,
+			&before, &after, &e.TraceID, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		e.OccurredAt = e.OccurredAt.UTC()
+		e.Before, e.After = before, after
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// sqlTx, Append'in kendi *sql.Tx'ini AppendTx'e repository.Tx olarak verir.
+type sqlTx struct{ tx *sql.Tx }
+
+func (t sqlTx) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (t sqlTx) QueryRow(ctx context.Context, query string, args ...any) repository.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+func nullJSON(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: b != nil}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
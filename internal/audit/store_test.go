@@ -0,0 +1,123 @@
+// sentiric-user-service/internal/audit/store_test.go
+package audit
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+)
+
+const testDSNEnv = "USER_SERVICE_TEST_DSN"
+
+// TestSQLStore, hash zincirinin eşzamanlı yazmalarda çatallanmadığını, tablonun yalnızca
+// eklemeye açık olduğunu ve filtrelerin gerçek PostgreSQL'e karşı çalıştığını doğrular.
+func TestSQLStore(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s tanımlı değil, audit PostgreSQL testi atlandı", testDSNEnv)
+	}
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	db, err := database.Connect(dsn, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// audit_events silinemediği için her çalıştırma kendi tenant'ını kullanır.
+	tenant := "audit_" + uuid.NewString()
+	store := NewSQLStore(db)
+	if err := store.CheckSchema(ctx); err != nil {
+		t.Fatalf("CheckSchema after migrations = %v", err)
+	}
+	start := time.Now().Add(-time.Second)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			actor := "svc-a"
+			if i%2 == 1 {
+				actor = "svc-b"
+			}
+			_, err := store.Append(ctx, Event{
+				Actor: actor, TenantID: tenant, Action: ActionAgentProfileUpsert, TargetType: TargetAgentProfile,
+				TargetID: uuid.NewString(), Before: []byte(`{"status":"OFFLINE"}`), After: []byte(`{"status":"ONLINE"}`),
+				TraceID: "trace-1",
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := store.Verify(ctx); err != nil {
+		t.Fatalf("Verify after concurrent appends: %v", err)
+	}
+
+	events, err := store.Query(ctx, Filter{TenantID: tenant, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 20 {
+		t.Fatalf("query returned %d events, want 20", len(events))
+	}
+	if e := events[0]; string(e.Before) != `{"status":"OFFLINE"}` || e.TraceID != "trace-1" || e.Hash != computeHash(e) {
+		t.Fatalf("round trip = %+v", e)
+	}
+
+	byActor, err := store.Query(ctx, Filter{TenantID: tenant, Actor: "svc-b", Since: start, Until: time.Now().Add(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byActor) != 10 {
+		t.Fatalf("actor filter returned %d events, want 10", len(byActor))
+	}
+	if none, _ := store.Query(ctx, Filter{TenantID: tenant, Until: start}); len(none) != 0 {
+		t.Fatalf("until filter returned %d events", len(none))
+	}
+
+	for _, stmt := range []string{
+		`UPDATE audit_events SET actor = 'x' WHERE tenant_id = $1`,
+		`DELETE FROM audit_events WHERE tenant_id = $1`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt, tenant); err == nil {
+			t.Fatalf("%s succeeded on append-only table", stmt)
+		}
+	}
+
+	// AppendTx'in kaydı, transaction geri alınırsa zincire eklenmez.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolledBack := Event{Actor: "svc-a", TenantID: tenant, Action: ActionUserCreate, TargetType: TargetUser, TargetID: "rolled-back"}
+	if _, err := store.AppendTx(ctx, sqlTx{tx}, rolledBack); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := store.Query(ctx, Filter{TenantID: tenant, Limit: 100}); len(after) != 20 {
+		t.Fatalf("%d events after rolled back AppendTx, want 20", len(after))
+	}
+	if _, err := store.Verify(ctx); err != nil {
+		t.Fatalf("Verify after rolled back AppendTx: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// sentiric-user-service/internal/authz/identity.go
+package authz
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity, mTLS istemci sertifikasından çıkarılan servis kimliğidir.
+type Identity struct {
+	// SpiffeID, sertifikadaki ilk spiffe:// URI SAN'ıdır (varsa).
+	SpiffeID string
+	// CommonName, sertifika Subject CN alanıdır.
+	CommonName string
+	// DNSNames, sertifikadaki DNS SAN'larıdır.
+	DNSNames []string
+}
+
+// Names, policy eşleştirmesinde denenecek tüm isimleri öncelik sırasıyla döner.
+func (id Identity) Names() []string {
+	var names []string
+	if id.SpiffeID != "" {
+		names = append(names, id.SpiffeID)
+	}
+	if id.CommonName != "" {
+		names = append(names, id.CommonName)
+	}
+	return append(names, id.DNSNames...)
+}
+
+// String, log ve audit kayıtlarında kullanılacak tekil kimliği döner.
+func (id Identity) String() string {
+	switch {
+	case id.SpiffeID != "":
+		return id.SpiffeID
+	case id.CommonName != "":
+		return id.CommonName
+	case len(id.DNSNames) > 0:
+		return id.DNSNames[0]
+	default:
+		return "anonymous"
+	}
+}
+
+type identityKey struct{}
+
+// WithIdentity, kimliği sonraki katmanlar (rate limit, audit) için context'e koyar.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext, interceptor tarafından context'e konmuş kimliği döner.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// PeerIdentity, gRPC peer bilgisinden doğrulanmış istemci sertifikasını okur.
+// TLS yoksa veya istemci sertifika sunmadıysa false döner.
+func PeerIdentity(ctx context.Context) (Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return Identity{}, false
+	}
+
+	// Zincir doğrulanmışsa leaf sertifika VerifiedChains içindedir; bu, CA tarafından
+	// imzalanmamış bir sertifikanın kimlik olarak kullanılmasını engeller.
+	chains := tlsInfo.State.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return Identity{}, false
+	}
+	cert := chains[0][0]
+
+	id := Identity{
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			id.SpiffeID = uri.String()
+			break
+		}
+	}
+	return id, true
+}
@@ -0,0 +1,84 @@
+// sentiric-user-service/internal/authz/identity_test.go
+package authz
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestIdentityNamesAndString(t *testing.T) {
+	tests := []struct {
+		id        Identity
+		wantNames []string
+		wantStr   string
+	}{
+		{
+			Identity{SpiffeID: "spiffe://sentiric/a", CommonName: "a", DNSNames: []string{"a.local"}},
+			[]string{"spiffe://sentiric/a", "a", "a.local"}, "spiffe://sentiric/a",
+		},
+		{Identity{CommonName: "b", DNSNames: []string{"b.local"}}, []string{"b", "b.local"}, "b"},
+		{Identity{DNSNames: []string{"c.local", "c2.local"}}, []string{"c.local", "c2.local"}, "c.local"},
+		{Identity{}, nil, "anonymous"},
+	}
+	for _, tt := range tests {
+		if got := tt.id.Names(); !reflect.DeepEqual(got, tt.wantNames) {
+			t.Errorf("%+v.Names() = %v, want %v", tt.id, got, tt.wantNames)
+		}
+		if got := tt.id.String(); got != tt.wantStr {
+			t.Errorf("%+v.String() = %q, want %q", tt.id, got, tt.wantStr)
+		}
+	}
+}
+
+func peerContext(state tls.ConnectionState) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestPeerIdentity(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "dialplan"},
+		DNSNames: []string{"dialplan.sentiric.local"},
+		URIs: []*url.URL{
+			{Scheme: "https", Host: "sentiric.io"},
+			{Scheme: "spiffe", Host: "sentiric", Path: "/dialplan-service"},
+		},
+	}
+
+	id, ok := PeerIdentity(peerContext(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}))
+	if !ok {
+		t.Fatal("verified peer has no identity")
+	}
+	want := Identity{SpiffeID: "spiffe://sentiric/dialplan-service", CommonName: "dialplan", DNSNames: []string{"dialplan.sentiric.local"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("PeerIdentity = %+v, want %+v", id, want)
+	}
+
+	// Doğrulanmamış (yalnızca sunulmuş) sertifikalar kimlik sayılmaz.
+	if _, ok := PeerIdentity(peerContext(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})); ok {
+		t.Fatal("unverified certificate accepted as identity")
+	}
+	if _, ok := PeerIdentity(peer.NewContext(context.Background(), &peer.Peer{})); ok {
+		t.Fatal("non-TLS peer accepted as identity")
+	}
+	if _, ok := PeerIdentity(context.Background()); ok {
+		t.Fatal("context without peer accepted as identity")
+	}
+}
+
+func TestIdentityContext(t *testing.T) {
+	if _, ok := IdentityFromContext(context.Background()); ok {
+		t.Fatal("empty context has identity")
+	}
+	ctx := WithIdentity(context.Background(), Identity{CommonName: "a"})
+	if id, ok := IdentityFromContext(ctx); !ok || id.CommonName != "a" {
+		t.Fatalf("IdentityFromContext = %+v, %v", id, ok)
+	}
+}
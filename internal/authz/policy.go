@@ -0,0 +1,134 @@
+// sentiric-user-service/internal/authz/policy.go
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// Wildcard, bir kimliğin tüm metodlara erişebileceğini belirtir.
+const Wildcard = "*"
+
+// Rule, bir servis kimliğinin çağırabileceği metodları tanımlar.
+// Metodlar tam ("/sentiric.user.v1.UserService/GetUser") veya kısa ("GetUser") adla yazılabilir.
+// Kısa ad, policy oluşturulurken sunucudaki servislerden tam olarak birinin metoduna
+// çözülür; birden fazla serviste bulunan ya da hiçbirinde olmayan adlar reddedilir.
+type Rule struct {
+	Identity string   `json:"identity"`
+	Methods  []string `json:"methods"`
+}
+
+// Policy, kimlik -> izinli tam metod adı eşlemesidir.
+type Policy struct {
+	rules map[string]map[string]struct{}
+}
+
+// policyFile, JSON policy dosyasının şemasıdır.
+type policyFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadPolicy, JSON policy dosyasını okur. services, sunucuya kayıtlı servislerdir; metod adları
+// bunlara göre çözülür.
+//
+//	{"rules": [{"identity": "spiffe://sentiric/dialplan-service", "methods": ["FindUserByContact", "GetUser"]}]}
+func LoadPolicy(filePath string, services ...*grpc.ServiceDesc) (*Policy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("yetkilendirme politikası okunamadı: %w", err)
+	}
+	var pf policyFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("yetkilendirme politikası parse edilemedi: %w", err)
+	}
+	return NewPolicy(pf.Rules, services...)
+}
+
+// NewPolicy, kurallardan bir Policy oluşturur. Metod adları services'e göre çözülür; bilinmeyen
+// veya birden fazla servise uyan kısa adlar hata döner.
+func NewPolicy(rules []Rule, services ...*grpc.ServiceDesc) (*Policy, error) {
+	known, byName := serviceMethods(services)
+	p := &Policy{rules: make(map[string]map[string]struct{}, len(rules))}
+	for i, r := range rules {
+		if r.Identity == "" {
+			return nil, fmt.Errorf("kural %d: identity boş olamaz", i)
+		}
+		methods, ok := p.rules[r.Identity]
+		if !ok {
+			methods = make(map[string]struct{}, len(r.Methods))
+			p.rules[r.Identity] = methods
+		}
+		for _, m := range r.Methods {
+			full, err := resolveMethod(m, known, byName)
+			if err != nil {
+				return nil, fmt.Errorf("kural %d (%s): %w", i, r.Identity, err)
+			}
+			methods[full] = struct{}{}
+		}
+	}
+	return p, nil
+}
+
+// serviceMethods, servislerdeki tam metod adlarını ve kısa ad -> tam adlar eşlemesini döner.
+func serviceMethods(services []*grpc.ServiceDesc) (map[string]struct{}, map[string][]string) {
+	known := make(map[string]struct{})
+	byName := make(map[string][]string)
+	add := func(service, method string) {
+		full := "/" + service + "/" + method
+		known[full] = struct{}{}
+		byName[method] = append(byName[method], full)
+	}
+	for _, sd := range services {
+		for _, m := range sd.Methods {
+			add(sd.ServiceName, m.MethodName)
+		}
+		for _, st := range sd.Streams {
+			add(sd.ServiceName, st.StreamName)
+		}
+	}
+	return known, byName
+}
+
+// resolveMethod, kuraldaki metod adını tam "/paket.Servis/Metod" adına çevirir.
+func resolveMethod(m string, known map[string]struct{}, byName map[string][]string) (string, error) {
+	if m == Wildcard {
+		return Wildcard, nil
+	}
+	if strings.HasPrefix(m, "/") {
+		if _, ok := known[m]; !ok {
+			return "", fmt.Errorf("bilinmeyen metod %q", m)
+		}
+		return m, nil
+	}
+	switch full := byName[m]; len(full) {
+	case 0:
+		return "", fmt.Errorf("bilinmeyen metod %q", m)
+	case 1:
+		return full[0], nil
+	default:
+		sort.Strings(full)
+		return "", fmt.Errorf("metod adı %q belirsiz, tam adla yazılmalı: %s", m, strings.Join(full, ", "))
+	}
+}
+
+// Allowed, kimliğin isimlerinden herhangi biri verilen metoda yetkiliyse true döner.
+func (p *Policy) Allowed(id Identity, fullMethod string) bool {
+	for _, name := range id.Names() {
+		methods, ok := p.rules[name]
+		if !ok {
+			continue
+		}
+		if _, ok := methods[Wildcard]; ok {
+			return true
+		}
+		if _, ok := methods[fullMethod]; ok {
+			return true
+		}
+	}
+	return false
+}
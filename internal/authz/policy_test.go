@@ -0,0 +1,124 @@
+// sentiric-user-service/internal/authz/policy_test.go
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+const userService = "/sentiric.user.v1.UserService/"
+
+// testServices, sunucunun kaydettiği servislerin aynısıdır.
+var testServices = []*grpc.ServiceDesc{
+	&userv1.UserService_ServiceDesc,
+	&healthpb.Health_ServiceDesc,
+	&reflectionv1.ServerReflection_ServiceDesc,
+	&reflectionv1alpha.ServerReflection_ServiceDesc,
+}
+
+func TestPolicyAllowed(t *testing.T) {
+	p, err := NewPolicy([]Rule{
+		{Identity: "spiffe://sentiric/dialplan-service", Methods: []string{"FindUserByContact", userService + "GetUser"}},
+		{Identity: "spiffe://sentiric/admin", Methods: []string{Wildcard}},
+		{Identity: "sip-signaling", Methods: []string{"GetSipCredentials"}},
+		{Identity: "spiffe://sentiric/dialplan-service", Methods: []string{"GetAgentProfile"}},
+		{Identity: "spiffe://sentiric/monitor", Methods: []string{"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}},
+	}, testServices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dialplan := Identity{SpiffeID: "spiffe://sentiric/dialplan-service", CommonName: "dialplan"}
+	tests := []struct {
+		name   string
+		id     Identity
+		method string
+		want   bool
+	}{
+		{"short name rule", dialplan, userService + "FindUserByContact", true},
+		{"full name rule", dialplan, userService + "GetUser", true},
+		{"rules merged per identity", dialplan, userService + "GetAgentProfile", true},
+		{"method not listed", dialplan, userService + "CreateUser", false},
+		{"same name on another service", dialplan, "/other.v1.UserService/GetUser", false},
+		{"full name on one service only", Identity{SpiffeID: "spiffe://sentiric/monitor"}, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", true},
+		{"full name not on other version", Identity{SpiffeID: "spiffe://sentiric/monitor"}, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", false},
+		{"wildcard", Identity{SpiffeID: "spiffe://sentiric/admin"}, userService + "DeleteSipCredential", true},
+		{"wildcard covers streams", Identity{SpiffeID: "spiffe://sentiric/admin"}, "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", true},
+		{"common name fallback", Identity{SpiffeID: "spiffe://sentiric/unknown", CommonName: "sip-signaling"}, userService + "GetSipCredentials", true},
+		{"dns name fallback", Identity{DNSNames: []string{"other.local", "sip-signaling"}}, userService + "GetSipCredentials", true},
+		{"unknown identity", Identity{CommonName: "intruder"}, userService + "GetUser", false},
+		{"anonymous", Identity{}, userService + "GetUser", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Allowed(tt.id, tt.method); got != tt.want {
+				t.Fatalf("Allowed(%v, %s) = %v, want %v", tt.id, tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPolicyRejectsEmptyIdentity(t *testing.T) {
+	if _, err := NewPolicy([]Rule{{Identity: "", Methods: []string{Wildcard}}}, testServices...); err == nil {
+		t.Fatal("NewPolicy accepted a rule without identity")
+	}
+}
+
+func TestNewPolicyRejectsUnresolvableMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		wantErr string
+	}{
+		{"ambiguous short name", "ServerReflectionInfo", "belirsiz"},
+		{"unknown short name", "GetUsers", "bilinmeyen"},
+		{"unknown full name", "/sentiric.user.v1.UserService/GetUsers", "bilinmeyen"},
+		{"unknown service", "/other.v1.UserService/GetUser", "bilinmeyen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy([]Rule{{Identity: "spiffe://sentiric/admin", Methods: []string{tt.method}}}, testServices...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewPolicy(%q) error = %v, want %q", tt.method, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := LoadPolicy(write("policy.json", `{"rules": [{"identity": "spiffe://sentiric/dialplan-service", "methods": ["GetUser"]}]}`), testServices...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Allowed(Identity{SpiffeID: "spiffe://sentiric/dialplan-service"}, userService+"GetUser") {
+		t.Fatal("loaded rule not applied")
+	}
+
+	for name, path := range map[string]string{
+		"invalid json":     write("broken.json", `{"rules": [`),
+		"missing identity": write("empty.json", `{"rules": [{"methods": ["GetUser"]}]}`),
+		"ambiguous method": write("ambiguous.json", `{"rules": [{"identity": "admin", "methods": ["ServerReflectionInfo"]}]}`),
+		"missing file":     filepath.Join(dir, "missing.json"),
+	} {
+		if _, err := LoadPolicy(path, testServices...); err == nil {
+			t.Errorf("%s: LoadPolicy succeeded", name)
+		}
+	}
+}
@@ -5,10 +5,30 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Veritabanı sürücüleri (DB_DRIVER).
+const (
+	DBDriverSQL     = "sql"
+	DBDriverPgxPool = "pgxpool"
+	// DBDriverMemory: Veritabanısız, süreç içi repository (yerel demolar için).
+	DBDriverMemory = "memory"
+)
+
+// Outbox publisher'ları (OUTBOX_PUBLISHER).
+const (
+	// OutboxPublisherNone: Relay çalışmaz; olaylar yalnızca webhook dağıtımı için yazılır ve
+	// OUTBOX_RETENTION sonunda yayınlanmadan silinir.
+	OutboxPublisherNone   = "none"
+	OutboxPublisherStdout = "stdout"
+	OutboxPublisherFile   = "file"
+	OutboxPublisherAMQP   = "amqp"
+)
+
 type Config struct {
 	DatabaseURL    string
 	GRPCPort       string
@@ -23,6 +43,75 @@ type Config struct {
 	Env            string
 	NodeHostname   string
 	ServiceVersion string
+
+	// LogRedact*: Loglardaki kişisel verilerin sınıf başına redaction modu (mask, hash, drop, none).
+	// hash modu LOG_REDACTION_KEY ile anahtarlı HMAC üretir.
+	LogRedactContact     string
+	LogRedactName        string
+	LogRedactSipUsername string
+	LogRedactionKey      string
+
+	MigrateOnStartup bool
+
+	// DBDriver: "sql" (database/sql + simple protocol), "pgxpool" (native pool) veya "memory".
+	DBDriver          string
+	DBPoolMaxConns    int
+	DBPoolMinConns    int
+	DBPoolMaxIdleTime time.Duration
+	DBPoolMaxLifetime time.Duration
+	DBQueryExecMode   string
+
+	// DBReplicaURLs: Salt okunur sorguların yönlendirileceği replikalar (virgülle ayrılmış DSN'ler).
+	DBReplicaURLs          []string
+	DBReplicaMaxLag        time.Duration
+	DBReplicaCheckInterval time.Duration
+
+	// MemoryTenants: memory sürücüsünde önceden kayıtlı tenant'lar.
+	MemoryTenants []string
+
+	// CacheEnabled: 0002 tetikleyicileri kurulu değilse başlangıçta önbellek kapatılır.
+	CacheEnabled     bool
+	CacheMaxEntries  int
+	CacheTTL         time.Duration
+	CacheNegativeTTL time.Duration
+
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+
+	// OutboxPublisher: Domain olaylarının yayınlanacağı hedef (none, stdout, file, amqp).
+	OutboxPublisher    string
+	OutboxFilePath     string
+	OutboxAMQPURL      string
+	OutboxAMQPExchange string
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxRetention    time.Duration
+
+	// WebhooksEnabled: Tenant webhook aboneliklerine teslimat yapılır (0004 migration'ı gerekir).
+	WebhooksEnabled     bool
+	WebhookMaxAttempts  int
+	WebhookTimeout      time.Duration
+	WebhookPollInterval time.Duration
+	// WebhookRetention: Tamamlanmış (delivered, dead) teslimatların ve denemelerinin tutulma süresi.
+	WebhookRetention time.Duration
+
+	// AuditEnabled: Değiştiren repository çağrıları audit_events'e yazılır (0005 migration'ı gerekir).
+	AuditEnabled bool
+
+	TLSMode         string
+	AuthzPolicyPath string
+	RateLimits      string
+
+	// TenantRateLimits: Tenant başına (tüm istemciler toplamı) metod limitleri, GRPC_RATE_LIMITS formatında.
+	// Tenant yalnızca TenantTrustedIdentities'deki kimliklerin çağrılarında güvenilir sayılır;
+	// diğer istemcilerin gönderdiği tenant bu limite sayılmaz.
+	TenantRateLimits        string
+	TenantTrustedIdentities []string
+
+	TracesExporter   string
+	OtlpEndpoint     string
+	OtlpInsecure     bool
+	TraceSampleRatio float64
 }
 
 func Load() (*Config, error) {
@@ -33,13 +122,15 @@ func Load() (*Config, error) {
 		maxRetries = 10
 	}
 
-	return &Config{
-		DatabaseURL:    GetEnvOrFail("POSTGRES_URL"),
+	sampleRatio, err := strconv.ParseFloat(GetEnv("OTEL_TRACES_SAMPLER_ARG", "1.0"), 64)
+	if err != nil {
+		sampleRatio = 1.0
+	}
+
+	cfg := &Config{
+		DatabaseURL:    GetEnv("POSTGRES_URL", ""),
 		GRPCPort:       GetEnv("USER_SERVICE_GRPC_PORT", "12011"),
 		HttpPort:       GetEnv("USER_SERVICE_HTTP_PORT", "12010"),
-		CertPath:       GetEnvOrFail("USER_SERVICE_CERT_PATH"),
-		KeyPath:        GetEnvOrFail("USER_SERVICE_KEY_PATH"),
-		CaPath:         GetEnvOrFail("GRPC_TLS_CA_PATH"),
 		SipRealm:       GetEnvOrFail("SIP_SIGNALING_SERVICE_REALM"),
 		MaxDBRetries:   maxRetries,
 		LogLevel:       GetEnv("LOG_LEVEL", "info"),
@@ -47,7 +138,93 @@ func Load() (*Config, error) {
 		Env:            GetEnv("ENV", "production"),
 		NodeHostname:   GetEnv("NODE_HOSTNAME", "localhost"),
 		ServiceVersion: GetEnv("SERVICE_VERSION", "1.0.0"),
-	}, nil
+
+		LogRedactContact:     GetEnv("LOG_REDACT_CONTACT", "mask"),
+		LogRedactName:        GetEnv("LOG_REDACT_NAME", "mask"),
+		LogRedactSipUsername: GetEnv("LOG_REDACT_SIP_USERNAME", "mask"),
+		LogRedactionKey:      GetEnv("LOG_REDACTION_KEY", ""),
+
+		MigrateOnStartup: GetEnvBool("DB_MIGRATE_ON_STARTUP", false),
+
+		DBDriver:          GetEnv("DB_DRIVER", DBDriverSQL),
+		DBPoolMaxConns:    GetEnvInt("DB_POOL_MAX_CONNS", 20),
+		DBPoolMinConns:    GetEnvInt("DB_POOL_MIN_CONNS", 2),
+		DBPoolMaxIdleTime: GetEnvDuration("DB_POOL_MAX_CONN_IDLE_TIME", 5*time.Minute),
+		DBPoolMaxLifetime: GetEnvDuration("DB_POOL_MAX_CONN_LIFETIME", 30*time.Minute),
+		DBQueryExecMode:   GetEnv("DB_QUERY_EXEC_MODE", "cache_statement"),
+
+		DBReplicaURLs:          GetEnvList("DB_REPLICA_URLS"),
+		DBReplicaMaxLag:        GetEnvDuration("DB_REPLICA_MAX_LAG", 5*time.Second),
+		DBReplicaCheckInterval: GetEnvDuration("DB_REPLICA_CHECK_INTERVAL", 2*time.Second),
+
+		MemoryTenants: GetEnvList("MEMORY_TENANTS"),
+
+		CacheEnabled:     GetEnvBool("CACHE_ENABLED", true),
+		CacheMaxEntries:  GetEnvInt("CACHE_MAX_ENTRIES", 10000),
+		CacheTTL:         GetEnvDuration("CACHE_TTL", 30*time.Second),
+		CacheNegativeTTL: GetEnvDuration("CACHE_NEGATIVE_TTL", 5*time.Second),
+
+		HealthCheckInterval: GetEnvDuration("HEALTH_CHECK_INTERVAL", 5*time.Second),
+		HealthCheckTimeout:  GetEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+
+		OutboxPublisher:    GetEnv("OUTBOX_PUBLISHER", OutboxPublisherNone),
+		OutboxFilePath:     GetEnv("OUTBOX_FILE_PATH", ""),
+		OutboxAMQPURL:      GetEnv("OUTBOX_AMQP_URL", ""),
+		OutboxAMQPExchange: GetEnv("OUTBOX_AMQP_EXCHANGE", "sentiric.user.events"),
+		OutboxPollInterval: GetEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:    GetEnvInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxRetention:    GetEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+
+		WebhooksEnabled:     GetEnvBool("WEBHOOKS_ENABLED", false),
+		WebhookMaxAttempts:  GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeout:      GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookPollInterval: GetEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+		WebhookRetention:    GetEnvDuration("WEBHOOK_RETENTION", 30*24*time.Hour),
+
+		AuditEnabled: GetEnvBool("AUDIT_ENABLED", true),
+
+		TLSMode:         GetEnv("GRPC_TLS_MODE", TLSModeMutual),
+		AuthzPolicyPath: GetEnv("GRPC_AUTHZ_POLICY_PATH", ""),
+		RateLimits:      GetEnv("GRPC_RATE_LIMITS", ""),
+
+		TenantRateLimits:        GetEnv("GRPC_TENANT_RATE_LIMITS", ""),
+		TenantTrustedIdentities: GetEnvList("GRPC_TENANT_TRUSTED_IDENTITIES"),
+
+		TracesExporter:   GetEnv("OTEL_TRACES_EXPORTER", "none"),
+		OtlpEndpoint:     GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+		OtlpInsecure:     GetEnvBool("OTEL_EXPORTER_OTLP_INSECURE", false),
+		TraceSampleRatio: sampleRatio,
+	}
+
+	if err := cfg.loadTLS(); err != nil {
+		return nil, err
+	}
+	switch cfg.DBDriver {
+	case DBDriverSQL, DBDriverPgxPool:
+		if cfg.DatabaseURL == "" {
+			return nil, fmt.Errorf("gerekli ortam değişkeni tanımlı değil: POSTGRES_URL")
+		}
+	case DBDriverMemory:
+		if len(cfg.MemoryTenants) == 0 {
+			cfg.MemoryTenants = []string{"default"}
+		}
+	default:
+		return nil, fmt.Errorf("geçersiz DB_DRIVER: %q (sql, pgxpool veya memory olmalı)", cfg.DBDriver)
+	}
+	switch cfg.OutboxPublisher {
+	case OutboxPublisherNone, OutboxPublisherStdout:
+	case OutboxPublisherFile:
+		if cfg.OutboxFilePath == "" {
+			return nil, fmt.Errorf("OUTBOX_PUBLISHER=file için OUTBOX_FILE_PATH tanımlanmalı")
+		}
+	case OutboxPublisherAMQP:
+		if cfg.OutboxAMQPURL == "" {
+			return nil, fmt.Errorf("OUTBOX_PUBLISHER=amqp için OUTBOX_AMQP_URL tanımlanmalı")
+		}
+	default:
+		return nil, fmt.Errorf("geçersiz OUTBOX_PUBLISHER: %q (none, stdout, file veya amqp olmalı)", cfg.OutboxPublisher)
+	}
+	return cfg, nil
 }
 
 func GetEnv(key, fallback string) string {
@@ -57,6 +234,44 @@ func GetEnv(key, fallback string) string {
 	return fallback
 }
 
+// GetEnvInt, tam sayı değerleri okur. Geçersiz değerde fallback kullanılır.
+func GetEnvInt(key string, fallback int) int {
+	i, err := strconv.Atoi(GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return i
+}
+
+// GetEnvBool, "true", "1" gibi değerleri okur. Geçersiz değerde fallback kullanılır.
+func GetEnvBool(key string, fallback bool) bool {
+	b, err := strconv.ParseBool(GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// GetEnvDuration, "5s", "250ms" gibi değerleri okur. Geçersiz değerde fallback kullanılır.
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(GetEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// GetEnvList, virgülle ayrılmış değerleri okur; boş elemanlar atlanır.
+func GetEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(GetEnv(key, ""), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func GetEnvOrFail(key string) string {
 	value, exists := os.LookupEnv(key)
 	if !exists {
@@ -0,0 +1,60 @@
+// sentiric-user-service/internal/config/tls.go
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gRPC taşıma güvenliği modları (GRPC_TLS_MODE).
+const (
+	// TLSModeMutual: Varsayılan. Sunucu sertifikası + zorunlu istemci sertifikası (mTLS).
+	TLSModeMutual = "mtls"
+	// TLSModeServer: Yalnızca sunucu sertifikası. CA verilirse istemci sertifikası opsiyonel doğrulanır.
+	TLSModeServer = "tls"
+	// TLSModeInsecure: Şifrelemesiz. Sadece yerel geliştirme içindir.
+	TLSModeInsecure = "insecure"
+)
+
+// developmentEnvs: mTLS dışı modlara izin verilen profiller.
+var developmentEnvs = map[string]struct{}{
+	"development": {},
+	"dev":         {},
+	"local":       {},
+}
+
+// IsDevelopment, ENV değerinin bir geliştirme profili olup olmadığını döner.
+func (c *Config) IsDevelopment() bool {
+	return IsDevelopmentEnv(c.Env)
+}
+
+// IsDevelopmentEnv, verilen ENV değerinin bir geliştirme profili olup olmadığını döner.
+// Config yüklemeyen araçlar (cmd/webhooks gibi) için.
+func IsDevelopmentEnv(env string) bool {
+	_, ok := developmentEnvs[strings.ToLower(env)]
+	return ok
+}
+
+// loadTLS, moda göre sertifika yollarını okur. mTLS dışındaki modlar yalnızca
+// geliştirme profillerinde kabul edilir; production'da servis başlamayı reddeder.
+func (c *Config) loadTLS() error {
+	switch c.TLSMode {
+	case TLSModeMutual:
+		c.CertPath = GetEnvOrFail("USER_SERVICE_CERT_PATH")
+		c.KeyPath = GetEnvOrFail("USER_SERVICE_KEY_PATH")
+		c.CaPath = GetEnvOrFail("GRPC_TLS_CA_PATH")
+		return nil
+	case TLSModeServer:
+		c.CertPath = GetEnvOrFail("USER_SERVICE_CERT_PATH")
+		c.KeyPath = GetEnvOrFail("USER_SERVICE_KEY_PATH")
+		c.CaPath = GetEnv("GRPC_TLS_CA_PATH", "")
+	case TLSModeInsecure:
+	default:
+		return fmt.Errorf("geçersiz GRPC_TLS_MODE: %q (mtls, tls veya insecure olmalı)", c.TLSMode)
+	}
+
+	if !c.IsDevelopment() {
+		return fmt.Errorf("GRPC_TLS_MODE=%s yalnızca geliştirme profillerinde kullanılabilir, ENV=%s için mtls zorunludur", c.TLSMode, c.Env)
+	}
+	return nil
+}
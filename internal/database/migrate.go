@@ -0,0 +1,250 @@
+// sentiric-user-service/internal/database/migrate.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// migrationLockID: Tüm replikaların aynı advisory lock üzerinde sıraya girmesi için sabit anahtar.
+const migrationLockID = 0x5e17_0001
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration, tek bir şema versiyonunun up/down SQL'idir.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// LoadMigrations, gömülü SQL dosyalarını versiyon sırasına göre döner.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migration dizini okunamadı: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("geçersiz migration dosya adı: %s", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(migrationFS, "migrations/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d için farklı isimler: %s / %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d için up dosyası eksik", mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator, gömülü migration'ları schema_migrations tablosunu kullanarak uygular.
+// Aynı anda başlayan replikalardan yalnızca biri migration çalıştırır; diğerleri
+// advisory lock'ta bekler ve lock alındığında yapılacak iş kalmadığını görür.
+type Migrator struct {
+	db         *sql.DB
+	log        zerolog.Logger
+	migrations []Migration
+}
+
+// NewMigrator, gömülü migration'ları yükleyerek bir Migrator oluşturur.
+func NewMigrator(db *sql.DB, log zerolog.Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, log: log, migrations: migrations}, nil
+}
+
+// Up, uygulanmamış tüm migration'ları sırayla uygular.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig.Version, mig.Name, mig.Up, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down, son uygulanan steps adet migration'ı geri alır.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		for i := 0; i < steps; i++ {
+			current, err := currentVersion(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if current == 0 {
+				return nil
+			}
+			mig, ok := m.find(current)
+			if !ok {
+				return fmt.Errorf("veritabanı versiyonu %d için gömülü migration bulunamadı", current)
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d geri alınamaz (down dosyası yok)", current)
+			}
+			if err := m.apply(ctx, conn, mig.Version, mig.Name, mig.Down, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Version, veritabanındaki güncel şema versiyonunu döner (hiç uygulanmamışsa 0).
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return 0, err
+	}
+	return currentVersion(ctx, conn)
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// withLock, session seviyesinde advisory lock alır. Lock bağlantıya bağlı olduğu için
+// tüm işlemler aynı *sql.Conn üzerinden yapılır.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migration bağlantısı alınamadı: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("migration kilidi alınamadı: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			m.log.Warn().
+				Str("event", logger.EventDBMigrationUnlock).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Int64("lock_id", migrationLockID)).
+				Msg("Migration kilidi bırakılamadı")
+		}
+	}()
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// apply, migration SQL'ini ve versiyon kaydını tek transaction içinde çalıştırır.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, version int, name, body string, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migration %d_%s (%s) başarısız: %w", version, name, direction, err)
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", version, name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+	}
+	if err != nil {
+		return fmt.Errorf("migration versiyonu kaydedilemedi: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	m.log.Info().
+		Str("event", logger.EventDBMigrationApplied).
+		Dict("attributes", zerolog.Dict().
+			Int("version", version).
+			Str("name", name).
+			Str("direction", direction)).
+		Msg("Migration uygulandı")
+	return nil
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return fmt.Errorf("schema_migrations tablosu oluşturulamadı: %w", err)
+	}
+	return nil
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var version int
+	err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("şema versiyonu okunamadı: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,362 @@
+// sentiric-user-service/internal/database/migrate_test.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const testDSNEnv = "USER_SERVICE_TEST_DSN"
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mig := range migrations {
+		if mig.Version != i+1 {
+			t.Fatalf("migration %d has version %d, want contiguous versions starting at 1", i, mig.Version)
+		}
+		if mig.Up == "" {
+			t.Errorf("migration %d_%s has no up script", mig.Version, mig.Name)
+		}
+		if (mig.Down == "") != irreversible[mig.Version] {
+			t.Errorf("migration %d_%s: down script present = %t, want %t", mig.Version, mig.Name, mig.Down != "", !irreversible[mig.Version])
+		}
+	}
+}
+
+// testSchema, migration testleri için boş bir şema oluşturur ve o şemaya bağlı bir *sql.DB döner.
+// Testler Down ile tablo sildiği için paylaşılan şemada (public) çalışmaz; aksi halde paralel
+// çalışan diğer paketlerin PostgreSQL testleri bozulur.
+func testSchema(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s tanımlı değil, migration PostgreSQL testi atlandı", testDSNEnv)
+	}
+	log := zerolog.Nop()
+
+	admin, err := Connect(dsn, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := "migrate_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	db, err := Connect(withSearchPath(dsn, schema), 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// withSearchPath, DSN'e (URL veya key=value) search_path runtime parametresini ekler.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow("SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	rows, err := db.Query("SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var versions []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+// irreversible, down dosyası bilerek olmayan migration'lardır. 0001 üretimde zaten var olan
+// tabloları baseline alır; geri alınması tüm kullanıcı verisini (ve servise ait olmayan
+// tenants tablosunu) silerdi. 0005'in geri alınması yalnızca eklemeye açık audit kaydını silerdi.
+var irreversible = map[int]bool{1: true, 5: true}
+
+// migrationTables, her migration'ın oluşturduğu ve varlığı kontrol edilen bir tablodur.
+var migrationTables = map[int]string{
+	1: "users",
+	3: "outbox_events",
+	4: "webhook_deliveries",
+	5: "audit_events",
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := m.migrations[len(m.migrations)-1].Version
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := m.Version(ctx); err != nil || v != latest {
+		t.Fatalf("Version after Up = %d, %v, want %d", v, err, latest)
+	}
+	for _, table := range migrationTables {
+		if !tableExists(t, db, table) {
+			t.Fatalf("%s missing after Up", table)
+		}
+	}
+
+	// İkinci Up yapılacak iş olmadığını görmeli ve hiçbir versiyonu tekrar uygulamamalıdır.
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("second Up: %v", err)
+	}
+	if got := appliedVersions(t, db); len(got) != latest {
+		t.Fatalf("schema_migrations = %v after repeated Up", got)
+	}
+}
+
+// migratorUpTo, yalnızca version'a kadarki migration'ları bilen bir Migrator döner; versiyonlar
+// 1'den başlayıp ardışık olduğu için (TestLoadMigrations) dilimlemek yeterlidir.
+func migratorUpTo(t *testing.T, db *sql.DB, version int) *Migrator {
+	t.Helper()
+	m, err := NewMigrator(db, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.migrations = m.migrations[:version]
+	return m
+}
+
+// TestMigratorDownScripts: Baseline ile bir sonraki geri alınamaz migration arasındaki down
+// betikleri tersten ve birer birer uygulanabilmeli, ardından Up şemayı yeniden kurabilmelidir.
+func TestMigratorDownScripts(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+	all, err := LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := 1
+	for top < len(all) && !irreversible[top+1] {
+		top++
+	}
+	m := migratorUpTo(t, db, top)
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for version := top; version > 1; version-- {
+		if err := m.Down(ctx, 1); err != nil {
+			t.Fatalf("Down from %d: %v", version, err)
+		}
+		if v, _ := m.Version(ctx); v != version-1 {
+			t.Fatalf("Version after Down from %d = %d", version, v)
+		}
+		if table, ok := migrationTables[version]; ok && tableExists(t, db, table) {
+			t.Fatalf("%s still exists after migration %d was reverted", table, version)
+		}
+		for older, table := range migrationTables {
+			if older < version && !tableExists(t, db, table) {
+				t.Fatalf("%s (migration %d) dropped while reverting %d", table, older, version)
+			}
+		}
+	}
+
+	// Down betikleri baseline'a temiz dönmeli; Up kalan migration'ları tekrar uygulayabilmelidir.
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+	if v, _ := m.Version(ctx); v != top {
+		t.Fatalf("Version after re-Up = %d, want %d", v, top)
+	}
+}
+
+// TestMigratorDownStopsAtIrreversible: Down, down dosyası olmayan migration'da durmalı;
+// o migration'ın tabloları ve içlerindeki veri hiçbir adım sayısında silinmemelidir.
+func TestMigratorDownStopsAtIrreversible(t *testing.T) {
+	for version := range irreversible {
+		t.Run(strconv.Itoa(version), func(t *testing.T) {
+			db := testSchema(t)
+			ctx := context.Background()
+			m := migratorUpTo(t, db, version)
+			if err := m.Up(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES ('keep', 'Keep Tenant')`); err != nil {
+				t.Fatal(err)
+			}
+
+			err := m.Down(ctx, version+1)
+			if err == nil || !strings.Contains(err.Error(), "geri alınamaz") {
+				t.Fatalf("Down past migration %d = %v, want irreversible error", version, err)
+			}
+			if v, err := m.Version(ctx); err != nil || v != version {
+				t.Fatalf("Version after Down = %d, %v, want %d", v, err, version)
+			}
+			if table := migrationTables[version]; !tableExists(t, db, table) {
+				t.Fatalf("%s dropped by Down", table)
+			}
+			var name string
+			if err := db.QueryRowContext(ctx, `SELECT name FROM tenants WHERE id = 'keep'`).Scan(&name); err != nil || name != "Keep Tenant" {
+				t.Fatalf("tenant row after Down = %q, %v", name, err)
+			}
+		})
+	}
+}
+
+// TestCheckCacheTriggers: 0002 uygulanmadan veya tetikleyicilerden biri kapatılmışken
+// önbellek tetikleyici kontrolü başarısız olmalıdır.
+func TestCheckCacheTriggers(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+	if err := CheckCacheTriggers(ctx, db); err == nil {
+		t.Fatal("CheckCacheTriggers succeeded on an empty schema")
+	}
+
+	m, err := NewMigrator(db, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCacheTriggers(ctx, db); err != nil {
+		t.Fatalf("CheckCacheTriggers after Up: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `ALTER TABLE contacts DISABLE TRIGGER contacts_notify_cache`); err != nil {
+		t.Fatal(err)
+	}
+	err = CheckCacheTriggers(ctx, db)
+	if err == nil || !strings.Contains(err.Error(), "contacts_notify_cache") {
+		t.Fatalf("CheckCacheTriggers with a disabled trigger = %v", err)
+	}
+}
+
+// TestMigratorBaseline: Migration runner'dan önce init script'iyle kurulmuş bir veritabanında
+// 0001 (IF NOT EXISTS) hata vermeden baseline alınmalı ve mevcut veri korunmalıdır.
+func TestMigratorBaseline(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, ok := m.find(1)
+	if !ok {
+		t.Fatal("migration 1 not found")
+	}
+	if _, err := db.ExecContext(ctx, legacy.Up); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES ('legacy', 'Legacy Tenant')`); err != nil {
+		t.Fatal(err)
+	}
+	if tableExists(t, db, "schema_migrations") {
+		t.Fatal("legacy schema already has schema_migrations")
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up on legacy schema: %v", err)
+	}
+	if got := appliedVersions(t, db); len(got) != len(m.migrations) || got[0] != 1 {
+		t.Fatalf("schema_migrations = %v, want all versions recorded", got)
+	}
+	var name string
+	if err := db.QueryRowContext(ctx, `SELECT name FROM tenants WHERE id = 'legacy'`).Scan(&name); err != nil || name != "Legacy Tenant" {
+		t.Fatalf("legacy row = %q, %v", name, err)
+	}
+}
+
+// TestMigratorAdvisoryLock: Kilidi başka bir oturum tutarken Up beklemeli; aynı anda başlayan
+// replikalar her versiyonu tam bir kez uygulamalıdır.
+func TestMigratorAdvisoryLock(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+
+	holder, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := holder.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Connect en fazla 5 bağlantı açar: kilit tutan oturum, replikalar ve kontrol sorgusu için yeterli.
+	const replicas = 3
+	errs := make(chan error, replicas)
+	var wg sync.WaitGroup
+	for range replicas {
+		m, err := NewMigrator(db, zerolog.Nop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.Up(ctx)
+		}()
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if tableExists(t, db, "users") {
+		t.Fatal("Up ran while another session held the migration lock")
+	}
+
+	if _, err := holder.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+		t.Fatal(err)
+	}
+	holder.Close()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Up: %v", err)
+		}
+	}
+
+	migrations, _ := LoadMigrations()
+	got := appliedVersions(t, db)
+	if len(got) != len(migrations) {
+		t.Fatalf("schema_migrations = %v, want each of %d versions exactly once", got, len(migrations))
+	}
+}
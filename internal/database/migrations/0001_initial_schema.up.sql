@@ -0,0 +1,52 @@
+-- 0001: Temel şema. Mevcut kurulumlarda tablolar zaten bulunabileceği için
+-- IF NOT EXISTS ile yazılmıştır; bu sayede migration çalıştırılarak baseline alınabilir.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id                    TEXT PRIMARY KEY,
+    name                  TEXT NOT NULL,
+    domain                TEXT,
+    primary_language_code TEXT NOT NULL DEFAULT 'tr',
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                    TEXT,
+    tenant_id               TEXT NOT NULL REFERENCES tenants (id),
+    user_type               TEXT NOT NULL,
+    preferred_language_code TEXT,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+
+CREATE TABLE IF NOT EXISTS contacts (
+    id            SERIAL PRIMARY KEY,
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    contact_type  TEXT NOT NULL,
+    contact_value TEXT NOT NULL,
+    is_primary    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT contacts_type_value_key UNIQUE (contact_type, contact_value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id ON contacts (user_id);
+
+CREATE TABLE IF NOT EXISTS sip_credentials (
+    id           SERIAL PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    sip_username TEXT NOT NULL UNIQUE,
+    ha1_hash     TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS agent_profiles (
+    user_id              UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id            TEXT NOT NULL REFERENCES tenants (id),
+    display_name         TEXT,
+    max_concurrent_calls INTEGER NOT NULL DEFAULT 1 CHECK (max_concurrent_calls > 0),
+    status               TEXT NOT NULL DEFAULT 'OFFLINE',
+    last_status_change   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_profiles_tenant_status ON agent_profiles (tenant_id, status);
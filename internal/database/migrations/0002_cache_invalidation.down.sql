@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS sip_credentials_notify_cache ON sip_credentials;
+DROP TRIGGER IF EXISTS contacts_notify_cache ON contacts;
+DROP TRIGGER IF EXISTS users_notify_cache ON users;
+DROP FUNCTION IF EXISTS notify_user_cache();
+DROP FUNCTION IF EXISTS user_cache_payload(TEXT, JSONB);
@@ -0,0 +1,41 @@
+-- 0002: Önbellek invalidation bildirimleri. users, contacts ve sip_credentials
+-- tablolarındaki her değişiklik 'user_service_cache' kanalına NOTIFY gönderir;
+-- tüm replikalar bu kanalı dinleyerek süreç içi önbelleklerini temizler.
+
+CREATE OR REPLACE FUNCTION user_cache_payload(tbl TEXT, rec JSONB) RETURNS TEXT AS $$
+    SELECT json_build_object(
+        'table', tbl,
+        'user_id', CASE WHEN tbl = 'users' THEN rec ->> 'id' ELSE rec ->> 'user_id' END,
+        'contact_type', rec ->> 'contact_type',
+        'contact_value', rec ->> 'contact_value',
+        'sip_username', rec ->> 'sip_username'
+    )::TEXT;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION notify_user_cache() RETURNS TRIGGER AS $$
+BEGIN
+    -- UPDATE'te hem eski hem yeni anahtar gönderilir (ör. contact_value değişimi).
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM pg_notify('user_service_cache', user_cache_payload(TG_TABLE_NAME, to_jsonb(OLD)));
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM pg_notify('user_service_cache', user_cache_payload(TG_TABLE_NAME, to_jsonb(NEW)));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_notify_cache ON users;
+CREATE TRIGGER users_notify_cache
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION notify_user_cache();
+
+DROP TRIGGER IF EXISTS contacts_notify_cache ON contacts;
+CREATE TRIGGER contacts_notify_cache
+    AFTER INSERT OR UPDATE OR DELETE ON contacts
+    FOR EACH ROW EXECUTE FUNCTION notify_user_cache();
+
+DROP TRIGGER IF EXISTS sip_credentials_notify_cache ON sip_credentials;
+CREATE TRIGGER sip_credentials_notify_cache
+    AFTER INSERT OR UPDATE OR DELETE ON sip_credentials
+    FOR EACH ROW EXECUTE FUNCTION notify_user_cache();
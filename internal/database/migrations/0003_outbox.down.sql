@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS agent_profiles_outbox ON agent_profiles;
+DROP TRIGGER IF EXISTS sip_credentials_outbox ON sip_credentials;
+DROP TRIGGER IF EXISTS contacts_outbox ON contacts;
+DROP TRIGGER IF EXISTS users_outbox ON users;
+DROP FUNCTION IF EXISTS write_outbox_event();
+DROP TABLE IF EXISTS outbox_events;
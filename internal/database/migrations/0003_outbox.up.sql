@@ -0,0 +1,107 @@
+-- 0003: Transactional outbox. Domain olayları, değişikliği yapan transaction'ın içinde
+-- tetikleyicilerle outbox_events tablosuna yazılır; relay bu satırları yayınlar.
+-- Yayın en az bir kez (at-least-once) garantilidir; tüketiciler event_id ile tekilleştirmelidir.
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           BIGSERIAL PRIMARY KEY,
+    event_id     UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    event_type   TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    tenant_id    TEXT,
+    payload      JSONB NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- available_at: Relay'in olayı en erken alabileceği zaman (kiralama ve yeniden deneme gecikmesi).
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (available_at, id) WHERE published_at IS NULL;
+-- Relay, bir aggregate'in bekleyen eski olaylarını bu indeksle arar (aggregate içi sıralama).
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_pending ON outbox_events (aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published ON outbox_events (published_at) WHERE published_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION write_outbox_event() RETURNS TRIGGER AS $$
+DECLARE
+    v_type    TEXT;
+    v_user_id UUID;
+    v_tenant  TEXT;
+    v_payload JSONB;
+BEGIN
+    CASE TG_TABLE_NAME
+    WHEN 'users' THEN
+        v_type := 'UserCreated';
+        v_user_id := NEW.id;
+        v_tenant := NEW.tenant_id;
+        v_payload := jsonb_build_object(
+            'user_id', NEW.id,
+            'tenant_id', NEW.tenant_id,
+            'user_type', NEW.user_type,
+            'name', NEW.name,
+            'preferred_language_code', NEW.preferred_language_code);
+    WHEN 'contacts' THEN
+        v_type := 'ContactAdded';
+        v_user_id := NEW.user_id;
+        v_payload := jsonb_build_object(
+            'user_id', NEW.user_id,
+            'contact_id', NEW.id,
+            'contact_type', NEW.contact_type,
+            'contact_value', NEW.contact_value,
+            'is_primary', NEW.is_primary);
+    WHEN 'sip_credentials' THEN
+        -- ha1_hash bilinçli olarak olaya eklenmez.
+        IF TG_OP = 'INSERT' THEN
+            v_type := 'SipCredentialCreated';
+            v_user_id := NEW.user_id;
+            v_payload := jsonb_build_object('user_id', NEW.user_id, 'sip_username', NEW.sip_username);
+        ELSE
+            v_type := 'SipCredentialDeleted';
+            v_user_id := OLD.user_id;
+            v_payload := jsonb_build_object('user_id', OLD.user_id, 'sip_username', OLD.sip_username);
+        END IF;
+    WHEN 'agent_profiles' THEN
+        IF TG_OP = 'UPDATE' AND OLD.status IS NOT DISTINCT FROM NEW.status THEN
+            RETURN NULL;
+        END IF;
+        v_type := 'AgentStatusChanged';
+        v_user_id := NEW.user_id;
+        v_tenant := NEW.tenant_id;
+        v_payload := jsonb_build_object(
+            'user_id', NEW.user_id,
+            'tenant_id', NEW.tenant_id,
+            'previous_status', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+            'status', NEW.status);
+    END CASE;
+
+    IF v_tenant IS NULL THEN
+        SELECT u.tenant_id INTO v_tenant FROM users u WHERE u.id = v_user_id;
+    END IF;
+
+    INSERT INTO outbox_events (event_type, aggregate_id, tenant_id, payload)
+    VALUES (v_type, v_user_id::TEXT, v_tenant, v_payload);
+    -- Relay'i beklemeden uyandırır; bildirim commit ile birlikte gider.
+    PERFORM pg_notify('user_service_outbox', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_outbox ON users;
+CREATE TRIGGER users_outbox
+    AFTER INSERT ON users
+    FOR EACH ROW EXECUTE FUNCTION write_outbox_event();
+
+DROP TRIGGER IF EXISTS contacts_outbox ON contacts;
+CREATE TRIGGER contacts_outbox
+    AFTER INSERT ON contacts
+    FOR EACH ROW EXECUTE FUNCTION write_outbox_event();
+
+DROP TRIGGER IF EXISTS sip_credentials_outbox ON sip_credentials;
+CREATE TRIGGER sip_credentials_outbox
+    AFTER INSERT OR DELETE ON sip_credentials
+    FOR EACH ROW EXECUTE FUNCTION write_outbox_event();
+
+DROP TRIGGER IF EXISTS agent_profiles_outbox ON agent_profiles;
+CREATE TRIGGER agent_profiles_outbox
+    AFTER INSERT OR UPDATE OF status ON agent_profiles
+    FOR EACH ROW EXECUTE FUNCTION write_outbox_event();
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS outbox_events_webhooks ON outbox_events;
+DROP FUNCTION IF EXISTS fan_out_webhooks();
+DROP TABLE IF EXISTS webhook_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
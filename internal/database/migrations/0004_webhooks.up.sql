@@ -0,0 +1,84 @@
+-- 0004: Tenant webhook'ları. Outbox'a yazılan her olay, aynı transaction içinde tenant'ın
+-- eşleşen aboneliklerine birer teslimat (webhook_deliveries) olarak dağıtılır.
+-- Teslimat denemeleri webhook_attempts'te saklanır; başarısız teslimatlar üstel gecikmeyle
+-- tekrar denenir ve deneme hakkı bitince 'dead' durumuna düşer.
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id   TEXT NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    url         TEXT NOT NULL,
+    -- secret: Payload'ı HMAC-SHA256 ile imzalamak için paylaşılan anahtar.
+    secret      TEXT NOT NULL,
+    -- event_types boşsa tüm olaylar gönderilir.
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions (tenant_id) WHERE active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        UUID NOT NULL,
+    event_type      TEXT NOT NULL,
+    tenant_id       TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ,
+    CONSTRAINT webhook_deliveries_subscription_event_key UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_tenant ON webhook_deliveries (tenant_id, status, id);
+-- Dispatcher, WEBHOOK_RETENTION süresini aşan tamamlanmış teslimatları bu indeksle siler.
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_finished ON webhook_deliveries (created_at) WHERE status <> 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id           BIGSERIAL PRIMARY KEY,
+    delivery_id  BIGINT NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt      INTEGER NOT NULL,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status_code  INTEGER,
+    error        TEXT,
+    duration_ms  INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts (delivery_id, id);
+
+CREATE OR REPLACE FUNCTION fan_out_webhooks() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.tenant_id IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, tenant_id, payload)
+    SELECT s.id, NEW.event_id, NEW.event_type, NEW.tenant_id,
+           jsonb_build_object(
+               'event_id', NEW.event_id,
+               'event_type', NEW.event_type,
+               'aggregate_id', NEW.aggregate_id,
+               'tenant_id', NEW.tenant_id,
+               'occurred_at', NEW.created_at,
+               'payload', NEW.payload)
+    FROM webhook_subscriptions s
+    WHERE s.tenant_id = NEW.tenant_id
+      AND s.active
+      AND (cardinality(s.event_types) = 0 OR NEW.event_type = ANY (s.event_types))
+    ON CONFLICT ON CONSTRAINT webhook_deliveries_subscription_event_key DO NOTHING;
+
+    IF FOUND THEN
+        PERFORM pg_notify('user_service_webhooks', '');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_events_webhooks ON outbox_events;
+CREATE TRIGGER outbox_events_webhooks
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW EXECUTE FUNCTION fan_out_webhooks();
@@ -0,0 +1,42 @@
+-- 0005: Yönetimsel değişikliklerin kalıcı audit kaydı. Tablo yalnızca eklemeye açıktır;
+-- her satırın hash'i bir önceki satırın hash'ini içerir (hash zinciri), böylece araya
+-- satır eklenmesi, silinmesi veya değiştirilmesi `audit verify` ile tespit edilir.
+-- Hash uygulama tarafında hesaplanır (internal/audit); before/after bu yüzden JSONB değil
+-- JSON tutulur, metin yazıldığı gibi saklanır.
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    -- actor: İsteği yapan istemcinin sertifika kimliği (SPIFFE ID, CN veya DNS SAN).
+    actor       TEXT NOT NULL,
+    -- tenant_id bilinçli olarak tenants'a FK değildir; tenant silinse de kayıt kalmalıdır.
+    tenant_id   TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id   TEXT NOT NULL,
+    before      JSON,
+    after       JSON,
+    trace_id    TEXT NOT NULL DEFAULT '',
+    prev_hash   TEXT NOT NULL,
+    hash        TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant ON audit_events (tenant_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred ON audit_events (occurred_at);
+
+CREATE OR REPLACE FUNCTION reject_audit_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events yalnızca eklemeye açıktır (%)', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_mutation();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_mutation();
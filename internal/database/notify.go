@@ -0,0 +1,107 @@
+// sentiric-user-service/internal/database/notify.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+// CacheChannel, 0002 migration'ındaki tetikleyicilerin NOTIFY gönderdiği kanaldır.
+const CacheChannel = "user_service_cache"
+
+// OutboxChannel, 0003 migration'ındaki tetikleyicilerin yeni outbox olayı için NOTIFY gönderdiği kanaldır.
+const OutboxChannel = "user_service_outbox"
+
+// WebhookChannel, 0004 migration'ındaki tetikleyicinin yeni webhook teslimatı için NOTIFY gönderdiği kanaldır.
+const WebhookChannel = "user_service_webhooks"
+
+// cacheTriggers, 0002 migration'ının CacheChannel'a NOTIFY gönderen tetikleyicileridir (tablo -> tetikleyici).
+var cacheTriggers = map[string]string{
+	"users":           "users_notify_cache",
+	"contacts":        "contacts_notify_cache",
+	"sip_credentials": "sip_credentials_notify_cache",
+}
+
+const queryCacheTrigger = `
+	SELECT EXISTS (
+		SELECT 1 FROM pg_trigger
+		WHERE tgrelid = to_regclass($1)::oid AND tgname = $2 AND NOT tgisinternal AND tgenabled <> 'D'
+	)`
+
+// CheckCacheTriggers, önbellek invalidation tetikleyicilerinin kurulu ve etkin olduğunu doğrular.
+// Tetikleyiciler yoksa Listen hiç bildirim almaz; diğer replikalardaki yazmalar önbellekte TTL
+// dolana kadar görünmez.
+func CheckCacheTriggers(ctx context.Context, db *sql.DB) error {
+	var missing []string
+	for table, trigger := range cacheTriggers {
+		var exists bool
+		if err := db.QueryRowContext(ctx, queryCacheTrigger, table, trigger).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, trigger)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("önbellek tetikleyicileri yok (0002 migration'ı uygulanmamış): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Listen, kanalı ayrı bir bağlantı üzerinden dinler ve her bildirimde onNotify'ı çağırır.
+// Bağlantı koparsa 5 saniye sonra yeniden bağlanır; arada kaçırılmış bildirimler
+// olabileceği için her (yeniden) bağlantıda onConnect çağrılır. ctx iptal edilince döner.
+// NOTIFY yalnızca primary'de üretildiği için url primary'yi göstermelidir.
+func Listen(ctx context.Context, url, channel string, onConnect func(), onNotify func(payload string), log zerolog.Logger) {
+	for {
+		err := listenOnce(ctx, url, channel, onConnect, onNotify, log)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn().
+			Str("event", logger.EventDBListenLost).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("channel", channel)).
+			Msg("LISTEN bağlantısı koptu, 5 saniye sonra tekrar denenecek...")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func listenOnce(ctx context.Context, url, channel string, onConnect func(), onNotify func(payload string), log zerolog.Logger) error {
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	log.Info().
+		Str("event", logger.EventDBListenStarted).
+		Dict("attributes", zerolog.Dict().Str("channel", channel)).
+		Msg("Veritabanı bildirimleri dinleniyor")
+	onConnect()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onNotify(n.Payload)
+	}
+}
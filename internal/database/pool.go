@@ -0,0 +1,92 @@
+// sentiric-user-service/internal/database/pool.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+// PoolOptions, pgxpool bağlantı havuzu ayarlarıdır.
+type PoolOptions struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnIdleTime time.Duration
+	MaxConnLifetime time.Duration
+	// ExecMode, pgx sorgu çalıştırma modudur. PgBouncer (transaction pooling) arkasında
+	// prepared statement'lar bağlantılar arasında taşınamadığı için "simple_protocol" seçilmelidir.
+	ExecMode string
+}
+
+// execModes, config'deki isimlerin pgx karşılıklarıdır.
+var execModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// ConnectPool, yeniden deneme mekanizmasıyla bir pgxpool.Pool oluşturur.
+func ConnectPool(ctx context.Context, url string, opts PoolOptions, maxRetries int, log zerolog.Logger) (*pgxpool.Pool, error) {
+	config, err := poolConfig(url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < maxRetries; i++ {
+		var pool *pgxpool.Pool
+		pool, err = pgxpool.NewWithConfig(ctx, config)
+		if err == nil {
+			if err = pool.Ping(ctx); err == nil {
+				log.Info().
+					Str("event", logger.EventDBConnected).
+					Dict("attributes", zerolog.Dict().
+						Str("exec_mode", opts.ExecMode).
+						Int32("max_conns", opts.MaxConns)).
+					Msg("Veritabanına bağlantı başarılı (pgxpool).")
+				return pool, nil
+			}
+			pool.Close()
+		}
+		log.Warn().
+			Str("event", logger.EventDBConnectRetry).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Int("attempt", i+1).
+				Int("max_attempts", maxRetries)).
+			Msg("Veritabanına bağlanılamadı, 5 saniye sonra tekrar denenecek...")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	return nil, fmt.Errorf("veritabanına bağlanılamadı (%d deneme): %w", maxRetries, err)
+}
+
+// poolConfig, URL'yi ve havuz ayarlarını pgxpool yapılandırmasına çevirir.
+func poolConfig(url string, opts PoolOptions) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("PostgreSQL URL parse edilemedi: %w", err)
+	}
+
+	mode, ok := execModes[opts.ExecMode]
+	if !ok {
+		return nil, fmt.Errorf("geçersiz sorgu modu: %q", opts.ExecMode)
+	}
+	config.ConnConfig.DefaultQueryExecMode = mode
+	config.MaxConns = opts.MaxConns
+	config.MinConns = opts.MinConns
+	config.MaxConnIdleTime = opts.MaxConnIdleTime
+	config.MaxConnLifetime = opts.MaxConnLifetime
+	return config, nil
+}
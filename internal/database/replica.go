@@ -0,0 +1,44 @@
+// sentiric-user-service/internal/database/replica.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Replika bağlantıları primary'nin aksine açılışta ping'lenmez ve yeniden denenmez:
+// erişilemeyen bir replika servisin ayağa kalkmasını engellememelidir. Trafik alıp
+// almayacağına repository'deki replika sağlık kontrolü karar verir.
+
+// OpenReplica, database/sql sürücüsü için bir okuma replikası bağlantısı açar.
+func OpenReplica(url string) (*sql.DB, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("replika URL parse edilemedi: %w", err)
+	}
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	db, err := sql.Open("pgx", stdlib.RegisterConnConfig(config.ConnConfig))
+	if err != nil {
+		return nil, err
+	}
+	db.SetConnMaxLifetime(time.Minute * 3)
+	db.SetMaxIdleConns(2)
+	db.SetMaxOpenConns(5)
+	return db, nil
+}
+
+// OpenReplicaPool, pgxpool sürücüsü için bir okuma replikası havuzu açar.
+func OpenReplicaPool(ctx context.Context, url string, opts PoolOptions) (*pgxpool.Pool, error) {
+	config, err := poolConfig(url, opts)
+	if err != nil {
+		return nil, fmt.Errorf("replika: %w", err)
+	}
+	return pgxpool.NewWithConfig(ctx, config)
+}
@@ -0,0 +1,163 @@
+// sentiric-user-service/internal/health/checker.go
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Checker, veritabanını periyodik olarak ping'ler ve sonucu hem gRPC health
+// servisine (grpc.health.v1) hem de HTTP /readyz endpoint'ine yansıtır.
+// db nil ise (memory sürücüsü) servis her zaman hazır sayılır.
+type Checker struct {
+	db       *sql.DB
+	grpc     *grpchealth.Server
+	interval time.Duration
+	timeout  time.Duration
+	log      zerolog.Logger
+
+	ready atomic.Bool
+
+	// mu, Shutdown ile devam eden bir kontrolün durumu yeniden SERVING'e çekmesini önler.
+	mu           sync.Mutex
+	shuttingDown bool
+}
+
+// servingServices: Durumu yönetilen servis isimleri. "" genel sunucu durumunu temsil eder.
+var servingServices = []string{"", userv1.UserService_ServiceDesc.ServiceName}
+
+// NewChecker, başlangıçta NOT_SERVING durumunda bir Checker oluşturur.
+// İlk başarılı ping'e kadar servis hazır sayılmaz.
+func NewChecker(db *sql.DB, interval, timeout time.Duration, log zerolog.Logger) *Checker {
+	c := &Checker{
+		db:       db,
+		grpc:     grpchealth.NewServer(),
+		interval: interval,
+		timeout:  timeout,
+		log:      log,
+	}
+	c.setServing(false)
+	return c
+}
+
+// GrpcServer, gRPC sunucusuna kaydedilecek health servisini döner.
+func (c *Checker) GrpcServer() healthpb.HealthServer {
+	return c.grpc
+}
+
+// Ready, son kontrolün başarılı olup olmadığını döner.
+func (c *Checker) Ready() bool {
+	return c.ready.Load()
+}
+
+// Run, context iptal edilene kadar kontrol döngüsünü çalıştırır.
+func (c *Checker) Run(ctx context.Context) {
+	c.check(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.check(ctx)
+		}
+	}
+}
+
+// Shutdown, tüm servisleri NOT_SERVING'e çeker ve sonraki güncellemeleri engeller.
+// Graceful shutdown sırasında load balancer'ların trafiği kesmesi için çağrılır.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shuttingDown = true
+	c.ready.Store(false)
+	c.grpc.Shutdown()
+}
+
+func (c *Checker) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var err error
+	if c.db != nil {
+		err = c.db.PingContext(pingCtx)
+	}
+	healthy := err == nil
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.shuttingDown {
+		return
+	}
+	if healthy != c.ready.Load() {
+		if healthy {
+			c.log.Info().
+				Str("event", logger.EventHealthChanged).
+				Dict("attributes", zerolog.Dict().
+					Str("status", "SERVING")).
+				Msg("Veritabanı erişilebilir, servis hazır")
+		} else {
+			c.log.Error().
+				Str("event", logger.EventHealthChanged).
+				Err(err).
+				Dict("attributes", zerolog.Dict().
+					Str("status", "NOT_SERVING")).
+				Msg("Veritabanına erişilemiyor, servis hazır değil")
+		}
+	}
+	c.setServing(healthy)
+}
+
+// setServing: Çağıran c.mu'yu tutmalıdır (NewChecker hariç).
+func (c *Checker) setServing(serving bool) {
+	c.ready.Store(serving)
+
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, svc := range servingServices {
+		c.grpc.SetServingStatus(svc, status)
+	}
+}
+
+// --- HTTP Handlers ---
+
+// LivezHandler: Süreç ayakta olduğu sürece 200 döner. Bağımlılıklara bakmaz,
+// aksi halde DB kesintisi tüm pod'ların gereksiz yere yeniden başlatılmasına yol açar.
+func (c *Checker) LivezHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, "ok")
+	}
+}
+
+// ReadyzHandler: Son veritabanı kontrolü başarılıysa 200, değilse 503 döner.
+func (c *Checker) ReadyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !c.Ready() {
+			writeStatus(w, http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		writeStatus(w, http.StatusOK, "ok")
+	}
+}
+
+func writeStatus(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"status": "%s"}`, status)
+}
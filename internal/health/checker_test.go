@@ -0,0 +1,51 @@
+// sentiric-user-service/internal/health/checker_test.go
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func readyzCode(c *Checker) int {
+	rec := httptest.NewRecorder()
+	c.ReadyzHandler()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return rec.Code
+}
+
+// TestShutdownStaysNotServing: Shutdown'dan sonra çalışmaya devam eden Run döngüsü
+// servisi yeniden hazır duruma çekmemelidir.
+func TestShutdownStaysNotServing(t *testing.T) {
+	c := NewChecker(nil, time.Millisecond, time.Second, zerolog.Nop())
+	if c.Ready() || readyzCode(c) != http.StatusServiceUnavailable {
+		t.Fatal("checker ready before the first check")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for !c.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("checker never became ready")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Shutdown()
+	time.Sleep(20 * time.Millisecond) // Run bu sürede birkaç kez daha kontrol yapar.
+
+	if c.Ready() || readyzCode(c) != http.StatusServiceUnavailable {
+		t.Fatal("/readyz serving again after Shutdown")
+	}
+	resp, err := c.GrpcServer().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("grpc health = %v, %v, want NOT_SERVING", resp, err)
+	}
+}
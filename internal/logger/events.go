@@ -4,7 +4,15 @@ package logger
 // SUTS v4.0 Standard Event IDs for user-service
 const (
 	EventSystemStartup    = "SYSTEM_STARTUP"
+	EventHealthChanged    = "HEALTH_STATUS_CHANGED"
 	EventGrpcRequest      = "GRPC_REQUEST_RECEIVED"
+	EventGrpcResponse     = "GRPC_REQUEST_COMPLETED"
+	EventGrpcPanic        = "GRPC_PANIC_RECOVERED"
+	EventAuthzDenied      = "AUTHZ_DENIED"
+	EventRateLimited      = "GRPC_RATE_LIMITED"
+	EventTLSReloaded      = "TLS_CERT_RELOADED"
+	EventTLSReloadFailed  = "TLS_CERT_RELOAD_FAILED"
+	EventInsecureMode     = "INSECURE_TRANSPORT_ENABLED"
 	EventUserLookup       = "USER_LOOKUP"
 	EventUserLookupFailed = "USER_LOOKUP_FAILED"
 	EventUserCreated      = "USER_CREATED"
@@ -14,4 +22,43 @@ const (
 	EventSipAuthSuccess   = "SIP_AUTH_SUCCESS"
 	EventSipAuthFailure   = "SIP_AUTH_FAILURE"
 	EventSipCredCreated   = "SIP_CREDENTIAL_CREATED"
+	EventOutboxPublishErr = "OUTBOX_PUBLISH_FAILED"
+	EventWebhookDead      = "WEBHOOK_DEAD_LETTERED"
+	EventAuditWriteFailed = "AUDIT_WRITE_FAILED"
+	EventDBError          = "DB_ERROR"
+	EventUserCreateFailed = "USER_CREATION_FAIL"
+	EventSipCredConflict  = "SIP_CRED_CONFLICT"
+	EventSipCredDeleted   = "SIP_CRED_DELETED"
+	EventAgentNotAgent    = "AGENT_PROFILE_NOT_AGENT"
+	EventAgentInitFailed  = "AGENT_PROFILE_INIT_FAILED"
+	EventCacheNotifyError = "CACHE_NOTIFICATION_INVALID"
+
+	// Yaşam döngüsü ve yapılandırma
+	EventSystemShutdown    = "SYSTEM_SHUTDOWN"
+	EventStartupFailed     = "SYSTEM_STARTUP_FAILED"
+	EventConfigWarning     = "CONFIG_WARNING"
+	EventAuthzPolicyLoaded = "AUTHZ_POLICY_LOADED"
+	EventTLSWatchFailed    = "TLS_CERT_WATCH_FAILED"
+	EventCommandFailed     = "CLI_COMMAND_FAILED"
+
+	// Veritabanı
+	EventDBConnected        = "DB_CONNECTED"
+	EventDBConnectRetry     = "DB_CONNECT_RETRY"
+	EventDBQueryRetry       = "DB_QUERY_RETRY"
+	EventDBMigrationApplied = "DB_MIGRATION_APPLIED"
+	EventDBMigrationUnlock  = "DB_MIGRATION_UNLOCK_FAILED"
+	EventDBListenStarted    = "DB_LISTEN_STARTED"
+	EventDBListenLost       = "DB_LISTEN_LOST"
+	EventReplicaChanged     = "DB_REPLICA_STATUS_CHANGED"
+	EventReplicaUnavailable = "DB_REPLICA_UNAVAILABLE"
+
+	// Arka plan işleri
+	EventOutboxRelayError   = "OUTBOX_RELAY_ERROR"
+	EventOutboxPruned       = "OUTBOX_PRUNED"
+	EventOutboxConnected    = "OUTBOX_PUBLISHER_CONNECTED"
+	EventWebhookRetry       = "WEBHOOK_DELIVERY_RETRY"
+	EventWebhookStoreError  = "WEBHOOK_STORE_ERROR"
+	EventWebhookPruned      = "WEBHOOK_DELIVERIES_PRUNED"
+	EventAuditSnapshotError = "AUDIT_SNAPSHOT_FAILED"
+	EventMetricsCollectErr  = "METRICS_COLLECT_FAILED"
 )
@@ -3,11 +3,13 @@ package logger
 
 import (
 	"context"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -16,27 +18,40 @@ const (
 	DefaultTenant = "system"
 )
 
-// SutsHook: Her log satırına SUTS zorunlu alanlarını ekler.
-type SutsHook struct {
-	Resource map[string]string
+// SutsHook: Her log satırına tenant_id alanını tam olarak bir kez ekler.
+// Tenant, olayın context'inden okunur (bkz. WithTenant, Tenant); yoksa DefaultTenant yazılır.
+// Log satırlarında tenant_id doğrudan Str ile eklenmemelidir, aksi halde alan iki kez yazılır.
+type SutsHook struct{}
+
+func (SutsHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	e.Str("tenant_id", TenantFromContext(e.GetCtx()))
 }
 
-func (h SutsHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
-	// 1. Governance
-	e.Str("schema_v", SchemaVersion)
+type tenantKey struct{}
 
-	// [CRITICAL FIX]: Zerolog, .Str() ile aynı key'i verirsek ezer.
-	// Eğer servis katmanında ("tenant_id", "xyz") verilmemişse, buradaki DefaultTenant eklenir.
-	// Eğer verilmişse, JSON içinde iki tane "tenant_id" oluşur ama Observer sonuncuyu dikkate alır.
-	// Güvenli fallback (boş gelmesini engellemek) için varsayılanı ekliyoruz.
-	e.Str("tenant_id", DefaultTenant)
+// WithTenant, isteğin tenant'ını context'e koyar; bu context ile oluşturulan
+// ContextLogger satırları bu tenant ile yazılır. Boş tenant context'i değiştirmez.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext, context'teki tenant'ı, yoksa DefaultTenant'ı döner.
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantKey{}).(string); ok {
+		return tenantID
+	}
+	return DefaultTenant
+}
 
-	// 2. Resource (Nested Object)
-	dict := zerolog.Dict()
-	for k, v := range h.Resource {
-		dict.Str(k, v)
+// Tenant, tek bir satırın tenant'ını belirler (ör. kaydın sahibi isteği yapandan farklıysa).
+// Kullanım: l.Info().Str("event", ...).Func(logger.Tenant(user.TenantId))
+func Tenant(tenantID string) func(*zerolog.Event) {
+	return func(e *zerolog.Event) {
+		e.Ctx(WithTenant(e.GetCtx(), tenantID))
 	}
-	e.Dict("resource", dict)
 }
 
 func New(serviceName, version, env, hostname, logLevel, logFormat string) zerolog.Logger {
@@ -61,23 +76,65 @@ func New(serviceName, version, env, hostname, logLevel, logFormat string) zerolo
 
 	var logger zerolog.Logger
 	if logFormat == "json" {
-		logger = zerolog.New(os.Stderr).With().
-			Timestamp().
-			Str("schema_v", SchemaVersion).
-			Dict("resource", resourceContext).
-			Logger()
+		logger = newJSON(os.Stderr, resourceContext)
 	} else {
 		output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
-		logger = zerolog.New(output).With().Timestamp().Str("service", serviceName).Logger()
+		logger = zerolog.New(output).With().Timestamp().Str("service", serviceName).Logger().Hook(SutsHook{})
 	}
 	return logger.Level(level)
 }
 
+// NewJSON, New'in JSON formatıyla aynı SUTS alanlarını üreten bir logger'ı w'ye yazar
+// (testlerde log satırlarını yakalamak için).
+func NewJSON(w io.Writer, serviceName, version, env, hostname string) zerolog.Logger {
+	return newJSON(w, zerolog.Dict().
+		Str("service.name", serviceName).
+		Str("service.version", version).
+		Str("service.env", env).
+		Str("host.name", hostname))
+}
+
+func newJSON(w io.Writer, resource *zerolog.Event) zerolog.Logger {
+	return zerolog.New(w).With().
+		Timestamp().
+		Str("schema_v", SchemaVersion).
+		Dict("resource", resource).
+		Logger().
+		Hook(SutsHook{})
+}
+
+// ContextLogger, context'teki trace bilgisini loga ekler ve context'i logger'a bağlar;
+// böylece SutsHook satırın tenant'ını context'ten okur.
+// OpenTelemetry span'i varsa W3C trace_id/span_id kullanılır ve eski x-trace-id metadata'sı
+// x_trace_id olarak ayrıca yazılır; gelen traceparent yoksa SDK yeni bir kök span açtığından
+// upstream'in ID'si ancak böyle izlenebilir. Span yoksa x-trace-id trace_id olarak yazılır.
 func ContextLogger(ctx context.Context, baseLog zerolog.Logger) zerolog.Logger {
+	c := baseLog.With().Ctx(ctx)
+	legacy := legacyTraceID(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		c = c.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+		if legacy != "" {
+			c = c.Str("x_trace_id", legacy)
+		}
+	} else if legacy != "" {
+		c = c.Str("trace_id", legacy)
+	}
+	return c.Logger()
+}
+
+// TraceID, ContextLogger'ın loglara yazdığı trace_id değerini döner (audit kayıtları için).
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return legacyTraceID(ctx)
+}
+
+func legacyTraceID(ctx context.Context) string {
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if vals := md.Get("x-trace-id"); len(vals) > 0 && vals[0] != "" {
-			return baseLog.With().Str("trace_id", vals[0]).Logger()
+		if vals := md.Get("x-trace-id"); len(vals) > 0 {
+			return vals[0]
 		}
 	}
-	return baseLog
+	return ""
 }
@@ -0,0 +1,124 @@
+// sentiric-user-service/internal/logger/logtest/logtest.go
+
+// Package logtest, testlerde üretim formatındaki (SUTS) log satırlarını yakalar ve
+// şemaya uyduklarını doğrular.
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+// Recorder, log satırlarını biriktirir. Loglar farklı goroutine'lerden yazılabildiği için kilitlidir.
+type Recorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// New, üretimdeki JSON logger ile aynı alanları yazan ve çıktısını Recorder'a
+// biriktiren bir logger döner.
+func New() (zerolog.Logger, *Recorder) {
+	rec := &Recorder{}
+	log := logger.NewJSON(rec, "user-service", "test", "test", "test-host").Level(zerolog.DebugLevel)
+	return log, rec
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.String()
+}
+
+// Entries, JSON log satırlarını çözer.
+func (r *Recorder) Entries(t testing.TB) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range r.lines() {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log satırı JSON değil: %q: %v", line, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// RequireSUTS, yakalanan her satırın SUTS şemasına uyduğunu doğrular; event alanı
+// olmayan satır da ihlal sayılır.
+func (r *Recorder) RequireSUTS(t testing.TB) {
+	t.Helper()
+	for _, line := range r.lines() {
+		if err := CheckSUTS([]byte(line)); err != nil {
+			t.Fatalf("SUTS ihlali: %v\n%s", err, line)
+		}
+	}
+}
+
+func (r *Recorder) lines() []string {
+	var out []string
+	for _, line := range strings.Split(r.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// CheckSUTS, tek bir log satırının SUTS şemasına uyduğunu doğrular: schema_v,
+// resource, event, attributes ve tenant_id bulunmalı; üst seviyede hiçbir alan tekrar etmemelidir.
+// Tekrar eden alanlar map'e çözülünce kaybolduğu için anahtarlar token token okunur.
+func CheckSUTS(line []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("satır bir JSON nesnesi değil")
+	}
+	fields := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if _, dup := fields[key]; dup {
+			return fmt.Errorf("%s alanı birden fazla yazılmış", key)
+		}
+		fields[key] = value
+	}
+
+	var schema string
+	if err := json.Unmarshal(fields["schema_v"], &schema); err != nil || schema != logger.SchemaVersion {
+		return fmt.Errorf("schema_v = %s, want %q", fields["schema_v"], logger.SchemaVersion)
+	}
+	var event, tenant string
+	if err := json.Unmarshal(fields["event"], &event); err != nil || event == "" {
+		return fmt.Errorf("event alanı eksik veya boş: %s", fields["event"])
+	}
+	if err := json.Unmarshal(fields["tenant_id"], &tenant); err != nil || tenant == "" {
+		return fmt.Errorf("tenant_id alanı eksik veya boş: %s", fields["tenant_id"])
+	}
+	for _, key := range []string{"resource", "attributes"} {
+		var obj map[string]any
+		if err := json.Unmarshal(fields[key], &obj); err != nil || obj == nil {
+			return fmt.Errorf("%s alanı bir nesne olmalı: %s", key, fields[key])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// sentiric-user-service/internal/logger/logtest/logtest_test.go
+package logtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+func TestTenantFromContext(t *testing.T) {
+	log, rec := New()
+	ctx := logger.WithTenant(context.Background(), "tenant-a")
+	l := logger.ContextLogger(ctx, log)
+
+	l.Info().Str("event", "A").Dict("attributes", zerolog.Dict()).Msg("context")
+	l.Info().Str("event", "B").Func(logger.Tenant("tenant-b")).Dict("attributes", zerolog.Dict()).Msg("override")
+	log.Info().Str("event", "C").Dict("attributes", zerolog.Dict()).Msg("fallback")
+
+	rec.RequireSUTS(t)
+	want := map[string]string{"A": "tenant-a", "B": "tenant-b", "C": logger.DefaultTenant}
+	for _, entry := range rec.Entries(t) {
+		event := entry["event"].(string)
+		if entry["tenant_id"] != want[event] {
+			t.Errorf("%s: tenant_id = %v, want %s", event, entry["tenant_id"], want[event])
+		}
+	}
+}
+
+// fatalRecorder, RequireSUTS'in testi durdurup durdurmadığını yakalar.
+type fatalRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fatalRecorder) Helper() {}
+
+func (f *fatalRecorder) Fatalf(string, ...any) { f.failed = true }
+
+func TestRequireSUTSChecksEveryLine(t *testing.T) {
+	log, rec := New()
+	log.Info().Str("event", "A").Dict("attributes", zerolog.Dict()).Msg("suts")
+	log.Info().Str("port", "50051").Msg("event alanı yok")
+
+	tb := &fatalRecorder{TB: t}
+	rec.RequireSUTS(tb)
+	if !tb.failed {
+		t.Fatal("event alanı olmayan satır RequireSUTS'ten geçti")
+	}
+}
+
+func TestCheckSUTS(t *testing.T) {
+	valid := `{"schema_v":"` + logger.SchemaVersion + `","resource":{},"event":"E","attributes":{},"tenant_id":"t"`
+	tests := []struct {
+		name string
+		line string
+		err  string
+	}{
+		{"valid", valid + `}`, ""},
+		{"duplicate tenant", valid + `,"tenant_id":"t"}`, "tenant_id alanı birden fazla"},
+		{"missing event", strings.Replace(valid, `"event":"E",`, "", 1) + `}`, "event"},
+		{"missing tenant", strings.Replace(valid, `,"tenant_id":"t"`, "", 1) + `}`, "tenant_id"},
+		{"attributes not object", strings.Replace(valid, `"attributes":{}`, `"attributes":"x"`, 1) + `}`, "attributes"},
+		{"wrong schema", strings.Replace(valid, logger.SchemaVersion, "0.0.0", 1) + `}`, "schema_v"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSUTS([]byte(tt.line))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("CheckSUTS = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("CheckSUTS = %v, want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
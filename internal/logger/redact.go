@@ -0,0 +1,164 @@
+// sentiric-user-service/internal/logger/redact.go
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// Kişisel veri (PII) sınıfları. Her sınıf için ayrı redaction modu seçilebilir.
+const (
+	PIIContact     = "contact"
+	PIIName        = "name"
+	PIISipUsername = "sip_username"
+)
+
+// Redaction modları.
+const (
+	// RedactMask: Değerin yalnızca ayırt edici küçük bir kısmı bırakılır ("********4567").
+	RedactMask = "mask"
+	// RedactHash: Değer anahtarlı HMAC-SHA256 ile özetlenir; aynı değer her satırda aynı
+	// özeti verir, böylece loglar değer açığa çıkmadan ilişkilendirilebilir.
+	RedactHash = "hash"
+	// RedactDrop: Alan loga hiç yazılmaz.
+	RedactDrop = "drop"
+	// RedactNone: Değer olduğu gibi yazılır. Yalnızca yerel geliştirme içindir.
+	RedactNone = "none"
+)
+
+// redactedText: Drop modunda serbest metin içine yazılan yer tutucu.
+const redactedText = "[REDACTED]"
+
+// RedactionPolicy, PII sınıfı başına redaction modunu belirler. Boş modlar RedactMask sayılır.
+type RedactionPolicy struct {
+	Contact     string
+	Name        string
+	SipUsername string
+	// Key, RedactHash modu için HMAC anahtarıdır; hash kullanan bir sınıf varsa zorunludur.
+	Key string
+}
+
+type redactor struct {
+	modes map[string]string
+	key   []byte
+}
+
+var activeRedactor atomic.Pointer[redactor]
+
+func init() {
+	// Varsayılan: tüm sınıflar maskelenir; ConfigureRedaction çağrılmasa da PII açık yazılmaz.
+	_ = ConfigureRedaction(RedactionPolicy{})
+}
+
+// ConfigureRedaction, tüm log satırlarında kullanılacak redaction politikasını ayarlar.
+func ConfigureRedaction(p RedactionPolicy) error {
+	r := &redactor{modes: map[string]string{}, key: []byte(p.Key)}
+	for class, mode := range map[string]string{PIIContact: p.Contact, PIIName: p.Name, PIISipUsername: p.SipUsername} {
+		if mode == "" {
+			mode = RedactMask
+		}
+		switch mode {
+		case RedactMask, RedactDrop, RedactNone:
+		case RedactHash:
+			if p.Key == "" {
+				return fmt.Errorf("%s için hash redaction modu bir anahtar gerektirir", class)
+			}
+		default:
+			return fmt.Errorf("geçersiz redaction modu: %s=%q (mask, hash, drop veya none olmalı)", class, mode)
+		}
+		r.modes[class] = mode
+	}
+	activeRedactor.Store(r)
+	return nil
+}
+
+// Contact, iletişim değerini (telefon, e-posta) politikaya göre key alanına yazar.
+// Kullanım: zerolog.Dict().Func(logger.Contact("contact_value", v))
+func Contact(key, value string) func(*zerolog.Event) {
+	return piiField(PIIContact, key, value)
+}
+
+// Name, kişi adını politikaya göre key alanına yazar.
+func Name(key, value string) func(*zerolog.Event) {
+	return piiField(PIIName, key, value)
+}
+
+// SipUsername, SIP kullanıcı adını politikaya göre key alanına yazar.
+func SipUsername(key, value string) func(*zerolog.Event) {
+	return piiField(PIISipUsername, key, value)
+}
+
+// Redact, serbest metne (ör. hata mesajı) gömülecek değeri politikaya göre döner.
+// Drop modunda değer yerine "[REDACTED]" yazılır.
+func Redact(class, value string) string {
+	if v, ok := activeRedactor.Load().apply(class, value); ok {
+		return v
+	}
+	return redactedText
+}
+
+func piiField(class, key, value string) func(*zerolog.Event) {
+	return func(e *zerolog.Event) {
+		if v, ok := activeRedactor.Load().apply(class, value); ok {
+			e.Str(key, v)
+		}
+	}
+}
+
+// apply, değeri sınıfının moduna göre dönüştürür; alan yazılmamalıysa false döner.
+func (r *redactor) apply(class, value string) (string, bool) {
+	mode, ok := r.modes[class]
+	if !ok {
+		mode = RedactMask
+	}
+	switch mode {
+	case RedactNone:
+		return value, true
+	case RedactDrop:
+		return "", false
+	case RedactHash:
+		h := hmac.New(sha256.New, r.key)
+		h.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil))[:16], true
+	default:
+		return mask(class, value), true
+	}
+}
+
+// mask, değerin yalnızca ayırt edici bir kısmını bırakır:
+// e-postada yerel kısmın ilk harfi ve alan adı, isimde her kelimenin baş harfi,
+// diğerlerinde (telefon, SIP kullanıcı adı) son birkaç karakter.
+func mask(class, value string) string {
+	if value == "" {
+		return ""
+	}
+	if local, domain, ok := strings.Cut(value, "@"); ok && class == PIIContact && local != "" {
+		r, _ := utf8.DecodeRuneInString(local)
+		return string(r) + "***@" + domain
+	}
+	if class == PIIName {
+		words := strings.Fields(value)
+		for i, w := range words {
+			r, _ := utf8.DecodeRuneInString(w)
+			words[i] = string(r) + "***"
+		}
+		return strings.Join(words, " ")
+	}
+
+	runes := []rune(value)
+	keep := 0
+	switch {
+	case len(runes) >= 10:
+		keep = 4
+	case len(runes) > 4:
+		keep = 2
+	}
+	return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
+}
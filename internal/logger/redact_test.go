@@ -0,0 +1,117 @@
+// sentiric-user-service/internal/logger/redact_test.go
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// withPolicy, testin süresince politikayı değiştirir ve sonunda varsayılana döner.
+func withPolicy(t *testing.T, p RedactionPolicy) {
+	t.Helper()
+	if err := ConfigureRedaction(p); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ConfigureRedaction(RedactionPolicy{}) })
+}
+
+// logAttributes, alanları tek bir log satırının attributes nesnesine yazıp geri okur.
+func logAttributes(t *testing.T, fields ...func(*zerolog.Event)) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	dict := zerolog.Dict()
+	for _, f := range fields {
+		dict.Func(f)
+	}
+	log := zerolog.New(&buf)
+	log.Info().Dict("attributes", dict).Msg("test")
+
+	var line struct {
+		Attributes map[string]string `json:"attributes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	return line.Attributes
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		class, value, want string
+	}{
+		{PIIContact, "905551234567", "********4567"},
+		{PIIContact, "ayse.yilmaz@example.com", "a***@example.com"},
+		{PIIContact, "", ""},
+		{PIIName, "Ayşe Yılmaz", "A*** Y***"},
+		{PIIName, "Çağrı", "Ç***"},
+		{PIISipUsername, "1001", "****"},
+		{PIISipUsername, "agent-42", "******42"},
+		// SIP kullanıcı adındaki "@" e-posta gibi ele alınmaz.
+		{PIISipUsername, "ali@pbx", "*****bx"},
+	}
+	for _, tt := range tests {
+		if got := mask(tt.class, tt.value); got != tt.want {
+			t.Errorf("mask(%s, %q) = %q, want %q", tt.class, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRedactionModes(t *testing.T) {
+	withPolicy(t, RedactionPolicy{Contact: RedactHash, Name: RedactDrop, SipUsername: RedactNone, Key: "k1"})
+
+	attrs := logAttributes(t,
+		Contact("contact_value", "905551234567"),
+		Name("name", "Ayşe Yılmaz"),
+		SipUsername("sip_username", "1001"),
+	)
+	if _, ok := attrs["name"]; ok {
+		t.Fatalf("drop modunda alan yazılmış: %v", attrs)
+	}
+	if attrs["sip_username"] != "1001" {
+		t.Fatalf("none modu değeri değiştirmiş: %v", attrs)
+	}
+	hashed := attrs["contact_value"]
+	if !strings.HasPrefix(hashed, "hmac:") || strings.Contains(hashed, "4567") {
+		t.Fatalf("hash modu = %q", hashed)
+	}
+
+	// Aynı değer aynı özeti verir (ilişkilendirme); farklı anahtar farklı özet üretir.
+	if again := Redact(PIIContact, "905551234567"); again != hashed {
+		t.Fatalf("hash kararlı değil: %q != %q", again, hashed)
+	}
+	if other := Redact(PIIContact, "905551234568"); other == hashed {
+		t.Fatal("farklı değerler aynı özeti verdi")
+	}
+	withPolicy(t, RedactionPolicy{Contact: RedactHash, Key: "k2"})
+	if rekeyed := Redact(PIIContact, "905551234567"); rekeyed == hashed {
+		t.Fatal("özet anahtara bağlı değil")
+	}
+}
+
+func TestRedactDropInText(t *testing.T) {
+	withPolicy(t, RedactionPolicy{SipUsername: RedactDrop})
+	if got := Redact(PIISipUsername, "1001"); got != "[REDACTED]" {
+		t.Fatalf("Redact = %q", got)
+	}
+}
+
+func TestDefaultPolicyMasks(t *testing.T) {
+	attrs := logAttributes(t, Contact("contact_value", "905551234567"), SipUsername("username", "agent-42"))
+	if attrs["contact_value"] != "********4567" || attrs["username"] != "******42" {
+		t.Fatalf("varsayılan politika = %v", attrs)
+	}
+}
+
+func TestConfigureRedactionErrors(t *testing.T) {
+	t.Cleanup(func() { ConfigureRedaction(RedactionPolicy{}) })
+	if err := ConfigureRedaction(RedactionPolicy{Contact: RedactHash}); err == nil {
+		t.Error("hash modu anahtarsız kabul edildi")
+	}
+	if err := ConfigureRedaction(RedactionPolicy{Name: "scramble"}); err == nil {
+		t.Error("geçersiz mod kabul edildi")
+	}
+}
@@ -0,0 +1,117 @@
+// sentiric-user-service/internal/metrics/collectors.go
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// RegisterDBStats, sql.DBStats'i (açık/boşta/bekleyen bağlantılar vb.) dışa açar.
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "user_service"))
+}
+
+// agentStatusCollector, her scrape'te ajan durum dağılımını repository'den okur.
+// Sayım veritabanında tutulduğu için gauge'ların replikalar arasında tutarlı olması sağlanır.
+type agentStatusCollector struct {
+	repo    repository.UserRepository
+	timeout time.Duration
+	log     zerolog.Logger
+	desc    *prometheus.Desc
+}
+
+// RegisterAgentStatus, tenant ve duruma göre ajan sayısını gösteren gauge'u kaydeder.
+func RegisterAgentStatus(repo repository.UserRepository, log zerolog.Logger) {
+	prometheus.MustRegister(&agentStatusCollector{
+		repo:    repo,
+		timeout: 2 * time.Second,
+		log:     log,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "agents"),
+			"Tenant ve duruma göre ajan sayısı.",
+			[]string{"tenant_id", "status"}, nil,
+		),
+	})
+}
+
+func (c *agentStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *agentStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.repo.CountAgentsByStatus(ctx)
+	if err != nil {
+		c.log.Warn().
+			Str("event", logger.EventMetricsCollectErr).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("metric", "agents_by_status")).
+			Msg("Ajan durum metrikleri toplanamadı")
+		return
+	}
+	for _, ac := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(ac.Count), ac.TenantID, ac.Status)
+	}
+}
+
+// poolStatsCollector, pgxpool.Stat() değerlerini sql.DBStats ile aynı isimlendirme
+// mantığında dışa açar.
+type poolStatsCollector struct {
+	pool *pgxpool.Pool
+
+	maxConns      *prometheus.Desc
+	totalConns    *prometheus.Desc
+	acquiredConns *prometheus.Desc
+	idleConns     *prometheus.Desc
+	acquireCount  *prometheus.Desc
+	acquireWait   *prometheus.Desc
+	emptyAcquire  *prometheus.Desc
+}
+
+// RegisterPoolStats, pgxpool havuz istatistiklerini kaydeder (DB_DRIVER=pgxpool).
+func RegisterPoolStats(pool *pgxpool.Pool) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+	prometheus.MustRegister(&poolStatsCollector{
+		pool:          pool,
+		maxConns:      desc("max_conns", "Havuzdaki azami bağlantı sayısı."),
+		totalConns:    desc("total_conns", "Havuzdaki toplam bağlantı sayısı."),
+		acquiredConns: desc("acquired_conns", "Şu an kullanımda olan bağlantı sayısı."),
+		idleConns:     desc("idle_conns", "Boşta bekleyen bağlantı sayısı."),
+		acquireCount:  desc("acquire_total", "Havuzdan alınan toplam bağlantı sayısı."),
+		acquireWait:   desc("acquire_wait_seconds_total", "Bağlantı almak için beklenen toplam süre."),
+		emptyAcquire:  desc("empty_acquire_total", "Havuz boşken beklemek zorunda kalınan alım sayısı."),
+	})
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxConns
+	ch <- c.totalConns
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.acquireCount
+	ch <- c.acquireWait
+	ch <- c.emptyAcquire
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
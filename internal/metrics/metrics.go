@@ -0,0 +1,181 @@
+// sentiric-user-service/internal/metrics/metrics.go
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "user_service"
+
+// SIP auth sonuç etiketleri (sip_auth_total{outcome}).
+const (
+	SipAuthSuccess       = "success"
+	SipAuthNotFound      = "not_found"
+	SipAuthRealmMismatch = "realm_mismatch"
+)
+
+// Önbellek etiketleri (cache_lookups_total{result}, cache_invalidations_total{source}).
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	CacheInvalidationNotify = "notify"
+	CacheInvalidationPurge  = "purge"
+)
+
+// Webhook teslimat sonuçları (webhook_deliveries_total{outcome}).
+const (
+	WebhookDelivered = "delivered"
+	WebhookRetry     = "retry"
+	WebhookDead      = "dead"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Metod ve durum koduna göre tamamlanan gRPC istek sayısı.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Metod ve durum koduna göre gRPC istek süresi.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method", "code"})
+
+	repoQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_query_duration_seconds",
+		Help:      "Operasyon ve sonuca göre repository sorgu süresi.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "result"})
+
+	grpcThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_throttled_total",
+		Help:      "Rate limit nedeniyle reddedilen gRPC istek sayısı.",
+	}, []string{"method"})
+
+	sipAuth = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sip_auth_total",
+		Help:      "Sonuca göre SIP kimlik bilgisi sorgulama sayısı.",
+	}, []string{"outcome"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Önbellek ve sonuca (hit, miss) göre önbellek sorgu sayısı.",
+	}, []string{"cache", "result"})
+
+	cacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_invalidations_total",
+		Help:      "Kaynağa (notify, purge) göre önbellek invalidation sayısı.",
+	}, []string{"source"})
+
+	replicaLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_replica_lag_seconds",
+		Help:      "Okuma replikasının primary'ye göre son ölçülen replikasyon gecikmesi.",
+	}, []string{"replica"})
+
+	replicaHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_replica_healthy",
+		Help:      "Okuma replikası trafik alabiliyorsa 1, aksi halde 0.",
+	}, []string{"replica"})
+
+	outboxPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_events_published_total",
+		Help:      "Olay tipine göre outbox'tan yayınlanan domain olayı sayısı.",
+	}, []string{"event_type"})
+
+	outboxPublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_publish_failures_total",
+		Help:      "Başarısız outbox yayın denemesi sayısı.",
+	})
+
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Sonuca (delivered, retry, dead) göre webhook gönderim denemesi sayısı.",
+	}, []string{"outcome"})
+
+	auditWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_write_failures_total",
+		Help:      "Değişiklik uygulandığı halde audit_events'e yazılamayan kayıt sayısı.",
+	})
+)
+
+// ObserveGrpcRequest, tamamlanan bir RPC'yi kaydeder.
+func ObserveGrpcRequest(method, code string, d time.Duration) {
+	grpcRequests.WithLabelValues(method, code).Inc()
+	grpcDuration.WithLabelValues(method, code).Observe(d.Seconds())
+}
+
+// IncThrottled, rate limit'e takılan bir isteği sayar.
+func IncThrottled(method string) {
+	grpcThrottled.WithLabelValues(method).Inc()
+}
+
+// ObserveQuery, bir repository operasyonunun süresini kaydeder.
+func ObserveQuery(operation, result string, d time.Duration) {
+	repoQueryDuration.WithLabelValues(operation, result).Observe(d.Seconds())
+}
+
+// IncSipAuth, SIP auth sonucunu sayar. outcome için Sip* sabitleri kullanılmalıdır.
+func IncSipAuth(outcome string) {
+	sipAuth.WithLabelValues(outcome).Inc()
+}
+
+// IncCacheLookup, bir önbellek sorgusunu sayar. result için CacheHit/CacheMiss kullanılmalıdır.
+func IncCacheLookup(cache, result string) {
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// IncCacheInvalidation, bir önbellek invalidation'ını sayar.
+func IncCacheInvalidation(source string) {
+	cacheInvalidations.WithLabelValues(source).Inc()
+}
+
+// SetReplicaLag, bir replikanın ölçülen replikasyon gecikmesini kaydeder.
+func SetReplicaLag(replica string, lag time.Duration) {
+	replicaLag.WithLabelValues(replica).Set(lag.Seconds())
+}
+
+// SetReplicaHealthy, bir replikanın okuma trafiği alıp almadığını kaydeder.
+func SetReplicaHealthy(replica string, healthy bool) {
+	v := 0.0
+	if healthy {
+		v = 1
+	}
+	replicaHealthy.WithLabelValues(replica).Set(v)
+}
+
+// IncOutboxPublished, yayınlanan bir domain olayını sayar.
+func IncOutboxPublished(eventType string) {
+	outboxPublished.WithLabelValues(eventType).Inc()
+}
+
+// IncOutboxPublishFailure, başarısız bir yayın denemesini sayar.
+func IncOutboxPublishFailure() {
+	outboxPublishFailures.Inc()
+}
+
+// IncWebhookDelivery, bir webhook denemesinin sonucunu sayar. outcome için Webhook* sabitleri kullanılmalıdır.
+func IncWebhookDelivery(outcome string) {
+	webhookDeliveries.WithLabelValues(outcome).Inc()
+}
+
+// IncAuditWriteFailure, yazılamayan bir audit kaydını sayar.
+func IncAuditWriteFailure() {
+	auditWriteFailures.Inc()
+}
@@ -0,0 +1,132 @@
+// sentiric-user-service/internal/outbox/amqp.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+)
+
+// AMQPPublisher, olayları bir topic exchange'e yayınlar. Routing key olay tipidir
+// (ör. "UserCreated"); tüketiciler ilgilendikleri tipleri kendi kuyruklarına bağlar.
+// Her mesaj publisher confirm ile onaylanmadan başarılı sayılmaz.
+type AMQPPublisher struct {
+	url      string
+	exchange string
+	log      zerolog.Logger
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+// NewAMQPPublisher, bir AMQP publisher oluşturur. Bağlantı ilk yayında kurulur ve koptuğunda
+// bir sonraki yayında yeniden açılır; broker'a erişilemezse olaylar outbox'ta bekler.
+func NewAMQPPublisher(url, exchange string, log zerolog.Logger) (*AMQPPublisher, error) {
+	if _, err := amqp.ParseURI(url); err != nil {
+		return nil, fmt.Errorf("geçersiz AMQP adresi: %w", err)
+	}
+	if exchange == "" {
+		return nil, errors.New("AMQP exchange adı boş olamaz")
+	}
+	return &AMQPPublisher{url: url, exchange: exchange, log: log}, nil
+}
+
+func (p *AMQPPublisher) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, p.exchange, e.Type, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    e.EventID,
+		Type:         e.Type,
+		Timestamp:    e.CreatedAt,
+		Headers: amqp.Table{
+			"tenant_id":    e.TenantID,
+			"aggregate_id": e.AggregateID,
+		},
+		Body: body,
+	})
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("AMQP yayını başarısız: %w", err)
+	}
+	ok, err := confirm.WaitContext(ctx)
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("AMQP onayı alınamadı: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("AMQP broker mesajı reddetti (nack): %s", e.EventID)
+	}
+	return nil
+}
+
+// channel, açık bir confirm kanalı döner; gerekirse bağlantıyı ve exchange'i yeniden kurar.
+func (p *AMQPPublisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() && p.conn != nil && !p.conn.IsClosed() {
+		return p.ch, nil
+	}
+	p.reset()
+
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("AMQP bağlantısı kurulamadı: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("AMQP kanalı açılamadı: %w", err)
+	}
+	if err := ch.ExchangeDeclare(p.exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("AMQP exchange tanımlanamadı: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("AMQP publisher confirm açılamadı: %w", err)
+	}
+
+	p.conn, p.ch = conn, ch
+	p.log.Info().
+		Str("event", logger.EventOutboxConnected).
+		Dict("attributes", zerolog.Dict().
+			Str("publisher", "amqp").
+			Str("exchange", p.exchange)).
+		Msg("AMQP bağlantısı kuruldu")
+	return ch, nil
+}
+
+func (p *AMQPPublisher) reset() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.ch = nil, nil
+}
+
+func (p *AMQPPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.ch = nil, nil
+	return err
+}
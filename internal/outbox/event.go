@@ -0,0 +1,60 @@
+// sentiric-user-service/internal/outbox/event.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Domain olay tipleri. 0003 migration'ındaki tetikleyiciler bu isimleri yazar.
+const (
+	EventUserCreated          = "UserCreated"
+	EventContactAdded         = "ContactAdded"
+	EventSipCredentialCreated = "SipCredentialCreated"
+	EventSipCredentialDeleted = "SipCredentialDeleted"
+	EventAgentStatusChanged   = "AgentStatusChanged"
+)
+
+// Event, outbox_events tablosundaki bir domain olayıdır.
+type Event struct {
+	// ID, outbox içindeki sıra numarasıdır; yalnızca relay tarafından kullanılır.
+	ID int64
+	// EventID, tüketicilerin tekrar eden teslimatları ayıklamak için kullanacağı kalıcı kimliktir.
+	EventID     string
+	Type        string
+	AggregateID string
+	TenantID    string
+	Payload     json.RawMessage
+	CreatedAt   time.Time
+	Attempts    int
+}
+
+// envelope, olayın yayınlanan (tüketicilere görünen) biçimidir.
+type envelope struct {
+	EventID     string          `json:"event_id"`
+	Type        string          `json:"event_type"`
+	AggregateID string          `json:"aggregate_id"`
+	TenantID    string          `json:"tenant_id,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// MarshalJSON, olayı relay'e ait alanlar (ID, Attempts) olmadan serileştirir.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(envelope{
+		EventID:     e.EventID,
+		Type:        e.Type,
+		AggregateID: e.AggregateID,
+		TenantID:    e.TenantID,
+		OccurredAt:  e.CreatedAt.UTC(),
+		Payload:     e.Payload,
+	})
+}
+
+// Publisher, olayları dış sisteme iletir. Publish nil dönerse olay teslim edilmiş sayılır;
+// hata dönerse relay aynı olayı daha sonra tekrar dener (at-least-once).
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+	Close() error
+}
@@ -0,0 +1,38 @@
+// sentiric-user-service/internal/outbox/pruner.go
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Pruner, publisher yapılandırılmadığında (OUTBOX_PUBLISHER=none) outbox tablosunu sınırlı tutar.
+// Tetikleyiciler olayları webhook dağıtımı için yine de yazar; hiç yayınlanmayacak bu satırlar
+// iletişim bilgisi gibi kişisel veriler içerdiğinden retention sonunda silinir.
+type Pruner struct {
+	store     Store
+	retention time.Duration
+	log       zerolog.Logger
+	now       func() time.Time
+}
+
+// NewPruner, bir Pruner oluşturur. retention sıfırdan büyük olmalıdır.
+func NewPruner(store Store, retention time.Duration, log zerolog.Logger) *Pruner {
+	return &Pruner{store: store, retention: retention, log: log, now: time.Now}
+}
+
+// Run, ctx iptal edilene kadar outbox'ı pruneInterval'de bir temizler.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		prune(ctx, p.store, p.now().Add(-p.retention), true, p.log)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
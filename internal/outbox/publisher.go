@@ -0,0 +1,59 @@
+// sentiric-user-service/internal/outbox/publisher.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterPublisher, her olayı bir satır JSON olarak yazar (stdout veya dosya).
+// Yerel geliştirme ve olayları bir log toplayıcıya devretmek için uygundur.
+type WriterPublisher struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterPublisher, w'ye yazan bir publisher oluşturur. w kapatılmaz.
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// NewFilePublisher, olayları path'teki dosyanın sonuna ekler.
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("outbox dosyası açılamadı: %w", err)
+	}
+	return &WriterPublisher{w: f, closer: f}, nil
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(line); err != nil {
+		return err
+	}
+	// Dosyaya yazılan olay, başarılı sayılmadan önce diske inmelidir.
+	if f, ok := p.w.(*os.File); ok && p.closer != nil {
+		return f.Sync()
+	}
+	return nil
+}
+
+func (p *WriterPublisher) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
@@ -0,0 +1,80 @@
+// sentiric-user-service/internal/outbox/publisher_test.go
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testEvent(id int64) Event {
+	return Event{
+		ID:          id,
+		EventID:     fmt.Sprintf("evt-%d", id),
+		Type:        EventUserCreated,
+		AggregateID: "user-1",
+		TenantID:    "tenant-a",
+		Payload:     json.RawMessage(`{"user_id":"user-1","user_type":"caller"}`),
+		CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Attempts:    3,
+	}
+}
+
+func TestEventEnvelope(t *testing.T) {
+	data, err := json.Marshal(testEvent(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["event_type"] != EventUserCreated || got["tenant_id"] != "tenant-a" || got["occurred_at"] != "2025-01-02T03:04:05Z" {
+		t.Fatalf("envelope = %s", data)
+	}
+	if _, ok := got["ID"]; ok {
+		t.Fatalf("relay alanları yayına sızdı: %s", data)
+	}
+	if _, ok := got["attempts"]; ok {
+		t.Fatalf("relay alanları yayına sızdı: %s", data)
+	}
+}
+
+func TestFilePublisherAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	for round := 0; round < 2; round++ {
+		pub, err := NewFilePublisher(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pub.Publish(context.Background(), testEvent(int64(round+1))); err != nil {
+			t.Fatal(err)
+		}
+		if err := pub.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("satır JSON değil: %q", scanner.Text())
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("lines = %d, want 2 (dosya yeniden açılınca eklenmeli)", lines)
+	}
+}
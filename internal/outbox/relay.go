@@ -0,0 +1,239 @@
+// sentiric-user-service/internal/outbox/relay.go
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+)
+
+// pruneInterval: Yayınlanmış eski olayların silinme sıklığı.
+const pruneInterval = time.Hour
+
+// RelayOptions, relay davranışını belirler. Sıfır değerler için varsayılanlar kullanılır.
+type RelayOptions struct {
+	// BatchSize, tek seferde kiralanacak en fazla olay sayısıdır.
+	BatchSize int
+	// PollInterval, NOTIFY gelmediğinde outbox'ın kontrol edilme sıklığıdır.
+	PollInterval time.Duration
+	// Lease, kiralanan bir olayın başka relay'lere verilmeden önce yayınlanması için tanınan süredir.
+	Lease time.Duration
+	// MinBackoff ve MaxBackoff, başarısız yayınların tekrar denenme gecikmesini sınırlar.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention, yayınlanmış olayların silinmeden önce tutulma süresidir. 0 ise silinmez.
+	Retention time.Duration
+}
+
+func (o RelayOptions) withDefaults() RelayOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.Lease <= 0 {
+		o.Lease = 30 * time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = 5 * time.Minute
+	}
+	return o
+}
+
+// Relay, outbox'taki olayları her aggregate için oluşturulma sırasıyla Publisher'a iletir. Olay
+// ancak Publish başarılı olduktan sonra yayınlanmış işaretlenir; işaretleme başarısız olursa olay
+// tekrar yayınlanır (at-least-once).
+// Birden çok replikada aynı anda çalışabilir; kiralama sayesinde aynı olay eşzamanlı iki kez alınmaz.
+type Relay struct {
+	store Store
+	pub   Publisher
+	opts  RelayOptions
+	log   zerolog.Logger
+
+	wake chan struct{}
+	now  func() time.Time
+}
+
+// NewRelay, bir Relay oluşturur.
+func NewRelay(store Store, pub Publisher, opts RelayOptions, log zerolog.Logger) *Relay {
+	return &Relay{
+		store: store,
+		pub:   pub,
+		opts:  opts.withDefaults(),
+		log:   log,
+		wake:  make(chan struct{}, 1),
+		now:   time.Now,
+	}
+}
+
+// Wake, relay'i bir sonraki poll'u beklemeden çalıştırır (NOTIFY geldiğinde çağrılır).
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run, ctx iptal edilene kadar outbox'ı boşaltır.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+
+	for {
+		r.drain(ctx)
+		if r.opts.Retention > 0 && r.now().Sub(lastPrune) >= pruneInterval {
+			r.prune(ctx)
+			lastPrune = r.now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// drain, bekleyen olay kalmayana veya bir yayın başarısız olana kadar batch'leri işler.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.relayOnce(ctx)
+		if err != nil || n < r.opts.BatchSize {
+			return
+		}
+	}
+}
+
+// relayOnce, bir batch kiralar ve sırayla yayınlar. İlk başarısız yayında durur ve kalan olayları
+// serbest bırakır. Başarısız olay tekrar denemeyi beklerken Claim aynı aggregate'in sonraki
+// olaylarını vermez; diğer aggregate'lerin olayları bir sonraki turda yayınlanır.
+// Yayınlanan olay sayısını döner.
+func (r *Relay) relayOnce(ctx context.Context) (int, error) {
+	events, err := r.store.Claim(ctx, r.opts.BatchSize, r.opts.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.log.Error().
+				Str("event", logger.EventOutboxRelayError).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("operation", "claim")).
+				Msg("Outbox olayları okunamadı")
+		}
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(events))
+	var failed error
+	for i, e := range events {
+		if err := r.pub.Publish(ctx, e); err != nil {
+			failed = err
+			r.fail(ctx, e, err)
+			r.release(ctx, events[i+1:])
+			break
+		}
+		published = append(published, e.ID)
+		metrics.IncOutboxPublished(e.Type)
+	}
+
+	if err := r.store.MarkPublished(ctx, published); err != nil {
+		// Olaylar yayınlandı ama işaretlenemedi; lease dolunca tekrar yayınlanacaklar.
+		r.log.Error().
+			Str("event", logger.EventOutboxRelayError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "mark_published").
+				Int("count", len(published))).
+			Msg("Outbox olayları yayınlandı olarak işaretlenemedi")
+		return 0, err
+	}
+	return len(published), failed
+}
+
+func (r *Relay) fail(ctx context.Context, e Event, cause error) {
+	metrics.IncOutboxPublishFailure()
+	retryAfter := r.backoff(e.Attempts)
+	r.log.Warn().
+		Str("event", logger.EventOutboxPublishErr).
+		Func(logger.Tenant(e.TenantID)).
+		Err(cause).
+		Dict("attributes", zerolog.Dict().
+			Str("event_id", e.EventID).
+			Str("event_type", e.Type).
+			Int("attempts", e.Attempts+1).
+			Dur("retry_after_ms", retryAfter)).
+		Msg("Domain olayı yayınlanamadı, tekrar denenecek")
+
+	if err := r.store.MarkFailed(ctx, e.ID, cause.Error(), retryAfter); err != nil {
+		r.log.Error().
+			Str("event", logger.EventOutboxRelayError).
+			Func(logger.Tenant(e.TenantID)).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "mark_failed").
+				Str("event_id", e.EventID)).
+			Msg("Outbox yayın hatası kaydedilemedi")
+	}
+}
+
+func (r *Relay) release(ctx context.Context, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	if err := r.store.Release(ctx, ids); err != nil {
+		r.log.Warn().
+			Str("event", logger.EventOutboxRelayError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "release").
+				Int("count", len(ids))).
+			Msg("Outbox olayları serbest bırakılamadı, lease dolunca tekrar alınacak")
+	}
+}
+
+// backoff, önceki deneme sayısına göre üstel gecikme döner.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.opts.MinBackoff
+	for i := 0; i < attempts && d < r.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.opts.MaxBackoff)
+}
+
+func (r *Relay) prune(ctx context.Context) {
+	prune(ctx, r.store, r.now().Add(-r.opts.Retention), false, r.log)
+}
+
+// prune, before'dan eski olayları siler; relay ve Pruner ortak kullanır.
+func prune(ctx context.Context, store Store, before time.Time, pending bool, log zerolog.Logger) {
+	n, err := store.Prune(ctx, before, pending)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn().
+				Str("event", logger.EventOutboxRelayError).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("operation", "prune")).
+				Msg("Eski outbox olayları silinemedi")
+		}
+		return
+	}
+	if n > 0 {
+		log.Debug().
+			Str("event", logger.EventOutboxPruned).
+			Dict("attributes", zerolog.Dict().
+				Int64("deleted", n).
+				Bool("pending", pending)).
+			Msg("Eski outbox olayları silindi")
+	}
+}
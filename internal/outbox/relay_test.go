@@ -0,0 +1,349 @@
+// sentiric-user-service/internal/outbox/relay_test.go
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// memStore, outbox tablosunun kiralama, tekrar deneme gecikmesi ve aggregate içi sıralama
+// davranışını taklit eder. Zaman yalnızca advance ile ilerler.
+type memStore struct {
+	mu          sync.Mutex
+	now         time.Time
+	events      []Event
+	published   map[int64]bool
+	availableAt map[int64]time.Time
+	failures    map[int64]string
+	retryAt     map[int64]time.Duration
+	pruned      int
+	prunedAll   int
+	claimErr    error
+}
+
+func newMemStore(types ...string) *memStore {
+	s := &memStore{
+		now:         time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		published:   map[int64]bool{},
+		availableAt: map[int64]time.Time{},
+		failures:    map[int64]string{},
+		retryAt:     map[int64]time.Duration{},
+	}
+	for _, typ := range types {
+		s.add(Event{EventID: typ + "-id", Type: typ})
+	}
+	return s
+}
+
+func (s *memStore) add(e Event) {
+	e.ID = int64(len(s.events) + 1)
+	s.events = append(s.events, e)
+}
+
+func (s *memStore) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// leased, olayın şu an kiralık veya tekrar denemeyi bekliyor olduğunu döner.
+func (s *memStore) leased(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.availableAt[id].After(s.now)
+}
+
+func (s *memStore) Claim(_ context.Context, limit int, lease time.Duration) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	var out []Event
+	blocked := map[string]bool{}
+	for _, e := range s.events {
+		if len(out) == limit {
+			break
+		}
+		if s.published[e.ID] {
+			continue
+		}
+		// Aggregate'in daha eski bir olayı beklerken sonraki olayları verilmez.
+		if blocked[e.AggregateID] || s.availableAt[e.ID].After(s.now) {
+			blocked[e.AggregateID] = true
+			continue
+		}
+		s.availableAt[e.ID] = s.now.Add(lease)
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (s *memStore) MarkPublished(_ context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.published[id] = true
+	}
+	return nil
+}
+
+func (s *memStore) MarkFailed(_ context.Context, id int64, reason string, retryAfter time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[id] = reason
+	s.retryAt[id] = retryAfter
+	s.availableAt[id] = s.now.Add(retryAfter)
+	for i := range s.events {
+		if s.events[i].ID == id {
+			s.events[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Release(_ context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		s.availableAt[id] = s.now
+	}
+	return nil
+}
+
+func (s *memStore) Prune(_ context.Context, _ time.Time, pending bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pending {
+		s.prunedAll++
+	} else {
+		s.pruned++
+	}
+	return 0, nil
+}
+
+// recordingPublisher, yayınlanan olay tiplerini kaydeder; failOn'daki tipler bir kez hata verir.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	got    []string
+	failOn map[string]bool
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failOn[e.Type] {
+		delete(p.failOn, e.Type)
+		return errors.New("broker down")
+	}
+	p.got = append(p.got, e.Type)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func (p *recordingPublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.got)
+}
+
+func TestRelayPublishesInOrderAcrossBatches(t *testing.T) {
+	store := newMemStore(EventUserCreated, EventContactAdded, EventSipCredentialCreated, EventSipCredentialDeleted, EventAgentStatusChanged)
+	pub := &recordingPublisher{}
+	relay := NewRelay(store, pub, RelayOptions{BatchSize: 2}, zerolog.Nop())
+
+	relay.drain(context.Background())
+
+	want := []string{EventUserCreated, EventContactAdded, EventSipCredentialCreated, EventSipCredentialDeleted, EventAgentStatusChanged}
+	if got := pub.published(); !slices.Equal(got, want) {
+		t.Fatalf("published = %v, want %v", got, want)
+	}
+	if len(store.published) != len(want) {
+		t.Fatalf("marked %d events, want %d", len(store.published), len(want))
+	}
+}
+
+func TestRelayStopsAtFailureAndRetries(t *testing.T) {
+	store := newMemStore(EventUserCreated, EventContactAdded, EventSipCredentialCreated)
+	pub := &recordingPublisher{failOn: map[string]bool{EventContactAdded: true}}
+	relay := NewRelay(store, pub, RelayOptions{BatchSize: 10, MinBackoff: time.Second, MaxBackoff: time.Minute}, zerolog.Nop())
+
+	n, err := relay.relayOnce(context.Background())
+	if err == nil || n != 1 {
+		t.Fatalf("relayOnce = %d, %v; want 1 and error", n, err)
+	}
+	// Başarısız olaydan sonraki olay sırayı korumak için yayınlanmamalı ve serbest bırakılmalı.
+	if got := pub.published(); !slices.Equal(got, []string{EventUserCreated}) {
+		t.Fatalf("published = %v", got)
+	}
+	if store.leased(3) {
+		t.Fatal("failed batch did not release remaining events")
+	}
+	if store.failures[2] != "broker down" || store.retryAt[2] != time.Second {
+		t.Fatalf("failure = %q retry = %v", store.failures[2], store.retryAt[2])
+	}
+
+	// Tekrar deneme zamanı gelmeden başarısız olayın arkasındaki olay yayınlanmamalı.
+	relay.drain(context.Background())
+	if got := pub.published(); !slices.Equal(got, []string{EventUserCreated}) {
+		t.Fatalf("published before retry delay = %v", got)
+	}
+
+	store.advance(time.Second)
+	relay.drain(context.Background())
+	want := []string{EventUserCreated, EventContactAdded, EventSipCredentialCreated}
+	if got := pub.published(); !slices.Equal(got, want) {
+		t.Fatalf("after retry published = %v, want %v", got, want)
+	}
+}
+
+// TestRelayKeepsAggregateOrderAfterFailure: Başarısız bir olay tekrar denemeyi beklerken aynı
+// aggregate'in sonraki olayları onu geçmemeli, diğer aggregate'ler ise beklememelidir.
+func TestRelayKeepsAggregateOrderAfterFailure(t *testing.T) {
+	store := newMemStore()
+	for _, e := range []Event{
+		{EventID: "a1", Type: EventUserCreated, AggregateID: "user-a"},
+		{EventID: "a2", Type: EventContactAdded, AggregateID: "user-a"},
+		{EventID: "b1", Type: EventUserCreated, AggregateID: "user-b"},
+		{EventID: "a3", Type: EventSipCredentialCreated, AggregateID: "user-a"},
+	} {
+		store.add(e)
+	}
+	var order []string
+	pub := &eventIDPublisher{failOn: map[string]bool{"a2": true}, got: &order}
+	relay := NewRelay(store, pub, RelayOptions{BatchSize: 1, MinBackoff: time.Minute, MaxBackoff: time.Hour}, zerolog.Nop())
+
+	relay.drain(context.Background())
+	relay.drain(context.Background())
+	if !slices.Equal(order, []string{"a1", "b1"}) {
+		t.Fatalf("published during retry delay = %v, want [a1 b1]", order)
+	}
+
+	store.advance(time.Minute)
+	relay.drain(context.Background())
+	if want := []string{"a1", "b1", "a2", "a3"}; !slices.Equal(order, want) {
+		t.Fatalf("published = %v, want %v", order, want)
+	}
+}
+
+// eventIDPublisher, yayınlanan olay kimliklerini sırasıyla kaydeder; failOn'dakiler bir kez hata verir.
+type eventIDPublisher struct {
+	failOn map[string]bool
+	got    *[]string
+}
+
+func (p *eventIDPublisher) Publish(_ context.Context, e Event) error {
+	if p.failOn[e.EventID] {
+		delete(p.failOn, e.EventID)
+		return errors.New("broker down")
+	}
+	*p.got = append(*p.got, e.EventID)
+	return nil
+}
+
+func (p *eventIDPublisher) Close() error { return nil }
+
+func TestRelayBackoff(t *testing.T) {
+	relay := NewRelay(newMemStore(), &recordingPublisher{}, RelayOptions{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, zerolog.Nop())
+	for attempts, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if got := relay.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+	if got := relay.backoff(100); got != 10*time.Second {
+		t.Errorf("backoff(100) = %v, want cap", got)
+	}
+}
+
+func TestRelayRunWakesAndStops(t *testing.T) {
+	store := newMemStore()
+	pub := &recordingPublisher{}
+	relay := NewRelay(store, pub, RelayOptions{PollInterval: time.Hour, Retention: time.Hour}, zerolog.Nop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	store.mu.Lock()
+	store.add(Event{EventID: "e1", Type: EventUserCreated})
+	store.mu.Unlock()
+	relay.Wake()
+
+	deadline := time.After(5 * time.Second)
+	for len(pub.published()) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("relay did not publish after Wake")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	if store.pruned != 1 || store.prunedAll != 0 {
+		t.Fatalf("pruned %d times (%d with pending events), want 1 published-only prune", store.pruned, store.prunedAll)
+	}
+}
+
+// TestPrunerRemovesPendingEvents: Publisher yokken olaylar hiç yayınlanmayacağı için bekleyenler de silinmelidir.
+func TestPrunerRemovesPendingEvents(t *testing.T) {
+	store := newMemStore(EventUserCreated)
+	pruner := NewPruner(store, time.Hour, zerolog.Nop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pruner.Run(ctx)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		store.mu.Lock()
+		n := store.prunedAll
+		store.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("pruner did not prune pending events")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestRelayClaimError(t *testing.T) {
+	store := newMemStore(EventUserCreated)
+	store.claimErr = errors.New("db down")
+	pub := &recordingPublisher{}
+	relay := NewRelay(store, pub, RelayOptions{}, zerolog.Nop())
+
+	if _, err := relay.relayOnce(context.Background()); err == nil {
+		t.Fatal("relayOnce succeeded with failing store")
+	}
+	if len(pub.published()) != 0 {
+		t.Fatal("published despite claim error")
+	}
+}
@@ -0,0 +1,133 @@
+// sentiric-user-service/internal/outbox/store.go
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// Store, relay'in outbox tablosu üzerindeki işlemlerini soyutlar.
+type Store interface {
+	// Claim, yayınlanmamış ve zamanı gelmiş en eski olayları lease süresince kiralar.
+	// Kiralanan olaylar süre dolana kadar diğer relay'lere verilmez. Aynı aggregate'in daha
+	// eski bir olayı kiralık veya tekrar denemeyi bekliyorsa sonraki olayları verilmez.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	// MarkPublished, olayları yayınlanmış olarak işaretler.
+	MarkPublished(ctx context.Context, ids []int64) error
+	// MarkFailed, deneme sayısını artırır ve olayı retryAfter sonra tekrar alınabilir yapar.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error
+	// Release, yayınlanmadan bırakılan olayların kirasını hemen sonlandırır.
+	Release(ctx context.Context, ids []int64) error
+	// Prune, before'dan önce yayınlanmış olayları siler ve silinen satır sayısını döner. pending
+	// true ise before'dan önce oluşturulup hiç yayınlanmamış olaylar da silinir (publisher yokken).
+	Prune(ctx context.Context, before time.Time, pending bool) (int64, error)
+}
+
+const (
+	// queryClaimOutbox, bir aggregate'in olayını ancak o aggregate'in daha eski yayınlanmamış
+	// olaylarının hepsi aynı batch'te kiralanıyorsa verir. Böylece tekrar denemeyi bekleyen
+	// (veya başka bir relay'e kiralanmış) bir olayın arkasındaki olaylar onu geçemez; diğer
+	// aggregate'ler beklemeden yayınlanır.
+	queryClaimOutbox = `
+		WITH candidates AS (
+			SELECT e.id, e.aggregate_id FROM outbox_events e
+			WHERE e.published_at IS NULL AND e.available_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.aggregate_id = e.aggregate_id AND p.published_at IS NULL
+				  AND p.id < e.id AND p.available_at > NOW())
+			ORDER BY e.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			SELECT c.id FROM candidates c
+			WHERE NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.aggregate_id = c.aggregate_id AND p.published_at IS NULL
+				  AND p.id < c.id AND p.id NOT IN (SELECT id FROM candidates))
+		)
+		UPDATE outbox_events o
+		SET available_at = NOW() + $2::float8 * INTERVAL '1 millisecond'
+		FROM claimed
+		WHERE o.id = claimed.id
+		RETURNING o.id, o.event_id, o.event_type, o.aggregate_id, COALESCE(o.tenant_id, ''), o.payload, o.created_at, o.attempts`
+	queryMarkOutboxPublished = `UPDATE outbox_events SET published_at = NOW(), last_error = NULL WHERE id = ANY($1)`
+	queryMarkOutboxFailed    = `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, available_at = NOW() + $3::float8 * INTERVAL '1 millisecond'
+		WHERE id = $1`
+	queryReleaseOutbox = `UPDATE outbox_events SET available_at = NOW() WHERE id = ANY($1) AND published_at IS NULL`
+	queryPruneOutbox   = `DELETE FROM outbox_events WHERE published_at < $1`
+	// queryPruneOutboxPending, yayınlanmayı beklemeyen (publisher yok) olayları da siler.
+	queryPruneOutboxPending = `DELETE FROM outbox_events WHERE published_at < $1 OR (published_at IS NULL AND created_at < $1)`
+)
+
+// SQLStore, outbox tablosunu database/sql üzerinden yönetir (sql ve pgxpool sürücüleriyle çalışır).
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore, bir SQLStore oluşturur.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx, queryClaimOutbox, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.EventID, &e.Type, &e.AggregateID, &e.TenantID, &payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING sırası garanti değildir; olaylar oluşturulma sırasıyla yayınlanır.
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (s *SQLStore) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, queryMarkOutboxPublished, ids)
+	return err
+}
+
+func (s *SQLStore) MarkFailed(ctx context.Context, id int64, reason string, retryAfter time.Duration) error {
+	_, err := s.db.ExecContext(ctx, queryMarkOutboxFailed, id, reason, retryAfter.Milliseconds())
+	return err
+}
+
+func (s *SQLStore) Release(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, queryReleaseOutbox, ids)
+	return err
+}
+
+func (s *SQLStore) Prune(ctx context.Context, before time.Time, pending bool) (int64, error) {
+	query := queryPruneOutbox
+	if pending {
+		query = queryPruneOutboxPending
+	}
+	result, err := s.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
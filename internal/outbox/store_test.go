@@ -0,0 +1,199 @@
+// sentiric-user-service/internal/outbox/store_test.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+)
+
+const testDSNEnv = "USER_SERVICE_TEST_DSN"
+
+// TestSQLStoreTriggers, 0003 tetikleyicilerinin olayları değişiklikle aynı transaction'da
+// yazdığını ve kiralama akışını gerçek PostgreSQL'e karşı doğrular.
+func TestSQLStoreTriggers(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s tanımlı değil, outbox PostgreSQL testi atlandı", testDSNEnv)
+	}
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	db, err := database.Connect(dsn, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := "outbox_" + uuid.NewString()
+	mustExec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	mustExec(`INSERT INTO tenants (id, name) VALUES ($1, 'Outbox')`, tenant)
+	t.Cleanup(func() {
+		db.ExecContext(ctx, `DELETE FROM users WHERE tenant_id = $1`, tenant)
+		db.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenant)
+		db.ExecContext(ctx, `DELETE FROM outbox_events WHERE tenant_id = $1`, tenant)
+	})
+
+	var userID string
+	if err := db.QueryRowContext(ctx, `INSERT INTO users (tenant_id, user_type) VALUES ($1, 'agent') RETURNING id`, tenant).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	mustExec(`INSERT INTO contacts (user_id, contact_type, contact_value, is_primary) VALUES ($1, 'email', $2, true)`, userID, tenant+"@example.com")
+	mustExec(`INSERT INTO sip_credentials (user_id, sip_username, ha1_hash) VALUES ($1, $2, 'secret-ha1')`, userID, tenant)
+	mustExec(`DELETE FROM sip_credentials WHERE sip_username = $1`, tenant)
+	mustExec(`INSERT INTO agent_profiles (user_id, tenant_id, status) VALUES ($1, $2, 'OFFLINE')`, userID, tenant)
+	mustExec(`UPDATE agent_profiles SET status = 'ONLINE' WHERE user_id = $1`, userID)
+	// Durum değişmediğinde olay üretilmez.
+	mustExec(`UPDATE agent_profiles SET status = 'ONLINE', display_name = 'x' WHERE user_id = $1`, userID)
+
+	// Geri alınan transaction'ın olayı da geri alınır.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO users (tenant_id, user_type) VALUES ($1, 'caller')`, tenant); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+
+	store := NewSQLStore(db)
+	claimed, err := store.Claim(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mine []Event
+	var others []int64
+	for _, e := range claimed {
+		if e.TenantID == tenant {
+			mine = append(mine, e)
+		} else {
+			others = append(others, e.ID)
+		}
+	}
+	// Paralel çalışan diğer testlerin olaylarına dokunulmaz.
+	if err := store.Release(ctx, others); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, e := range mine {
+		types = append(types, e.Type)
+		if e.AggregateID != userID {
+			t.Errorf("%s aggregate = %q, want %q", e.Type, e.AggregateID, userID)
+		}
+		if slices.Contains([]string{EventSipCredentialCreated, EventSipCredentialDeleted}, e.Type) {
+			var payload map[string]any
+			if err := json.Unmarshal(e.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := payload["ha1_hash"]; ok {
+				t.Errorf("%s payload HA1 içeriyor: %s", e.Type, e.Payload)
+			}
+		}
+	}
+	want := []string{EventUserCreated, EventContactAdded, EventSipCredentialCreated, EventSipCredentialDeleted, EventAgentStatusChanged, EventAgentStatusChanged}
+	if !slices.Equal(types, want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+
+	// Kiralanmış olaylar lease süresince tekrar verilmez.
+	again, err := store.Claim(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range again {
+		if e.TenantID == tenant {
+			t.Fatalf("leased event claimed twice: %v", e.Type)
+		}
+	}
+	store.Release(ctx, idsOf(again))
+
+	// Tekrar denemeyi bekleyen olayın arkasındaki olaylar (aynı aggregate) verilmez.
+	if err := store.MarkFailed(ctx, mine[0].ID, "broker down", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Release(ctx, idsOf(mine[1:])); err != nil {
+		t.Fatal(err)
+	}
+	blocked, err := store.Claim(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Release(ctx, idsOf(blocked))
+	for _, e := range blocked {
+		if e.TenantID == tenant {
+			t.Fatalf("%s claimed while an earlier event of its aggregate awaits retry", e.Type)
+		}
+	}
+	store.Release(ctx, idsOf(mine[:1]))
+
+	if err := store.MarkFailed(ctx, mine[0].ID, "broker down", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkPublished(ctx, idsOf(mine[1:])); err != nil {
+		t.Fatal(err)
+	}
+	retry, err := store.Claim(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var retried []Event
+	for _, e := range retry {
+		if e.TenantID == tenant {
+			retried = append(retried, e)
+		}
+	}
+	store.Release(ctx, idsOf(retry))
+	if len(retried) != 1 || retried[0].ID != mine[0].ID || retried[0].Attempts != 2 {
+		t.Fatalf("retry claim = %+v, want only the failed event with attempts=2", retried)
+	}
+
+	if _, err := store.Prune(ctx, time.Now().Add(time.Minute), false); err != nil {
+		t.Fatal(err)
+	}
+	var remaining int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox_events WHERE tenant_id = $1`, tenant).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 1 {
+		t.Fatalf("after prune %d events remain, want only the unpublished one", remaining)
+	}
+
+	// Publisher yokken yayınlanmamış eski olaylar da silinir.
+	if _, err := store.Prune(ctx, time.Now().Add(time.Minute), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox_events WHERE tenant_id = $1`, tenant).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Fatalf("after pending prune %d events remain, want none", remaining)
+	}
+}
+
+func idsOf(events []Event) []int64 {
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
@@ -0,0 +1,199 @@
+// sentiric-user-service/internal/ratelimit/limiter.go
+package ratelimit
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultMethod, özel limiti olmayan metodlar için kullanılan anahtardır.
+const DefaultMethod = "*"
+
+// Limit, bir metod için token bucket ayarıdır: saniyede RPS token, en fazla Burst birikim.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+const (
+	// idleTTL: Bu süre boyunca istek gelmeyen bucket'lar bellekten atılır.
+	idleTTL = 10 * time.Minute
+	// maxBuckets: Bellekte tutulabilecek en fazla bucket sayısı. Dolduğunda boşta kalanlar
+	// hemen temizlenir; yine yer açılmazsa yeni anahtarlar reddedilir.
+	maxBuckets = 100_000
+)
+
+// Bucket anahtarı önekleri: İstemci ve tenant limitleri ayrı bucket'larda tutulur.
+const (
+	scopeClient = "client"
+	scopeTenant = "tenant"
+)
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter, iki bağımsız token bucket ailesi tutar: (metod, istemci) ve (metod, tenant).
+// İstek ikisinden de geçmelidir. İstemci kimliği mTLS sertifikasından geldiği için tenant
+// değiştirerek yeni bir istemci kotası elde edilemez; tenant limiti ise tek bir tenant'ın
+// tüm istemciler üzerinden toplam kotasını sınırlar.
+type Limiter struct {
+	clientLimits map[string]Limit
+	tenantLimits map[string]Limit
+	now          func() time.Time
+	maxBuckets   int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	lastGC  time.Time
+}
+
+// New, metod bazlı istemci ve tenant limitleriyle bir Limiter oluşturur. Metodlar kısa adla
+// verilir ("FindUserByContact"). Bir ailede DefaultMethod tanımlı değilse, o ailede listede
+// olmayan metodlar sınırlanmaz; tenantLimits nil olabilir.
+func New(clientLimits, tenantLimits map[string]Limit) *Limiter {
+	return &Limiter{
+		clientLimits: clientLimits,
+		tenantLimits: tenantLimits,
+		now:          time.Now,
+		maxBuckets:   maxBuckets,
+		buckets:      make(map[string]*bucket),
+		lastGC:       time.Now(),
+	}
+}
+
+// Allow, isteğin geçip geçemeyeceğini döner. Reddedilirse bir sonraki token'ın
+// ne kadar süre sonra hazır olacağını da döner (retry-after ipucu).
+// tenant yalnızca güvenilir bir kaynaktan geliyorsa verilmelidir; boşsa tenant limiti uygulanmaz.
+func (l *Limiter) Allow(method, client, tenant string) (bool, time.Duration) {
+	now := l.now()
+
+	scopes := []scopedLimits{{scopeClient + "|" + method + "|" + client, l.clientLimits}}
+	if tenant != "" {
+		scopes = append(scopes, scopedLimits{scopeTenant + "|" + method + "|" + tenant, l.tenantLimits})
+	}
+
+	l.mu.Lock()
+	l.gcLocked(now, false)
+	var limiters []*rate.Limiter
+	for _, sc := range scopes {
+		limit, ok := lookup(sc.limits, method)
+		if !ok {
+			continue
+		}
+		b, ok := l.bucketLocked(sc.key, limit, now)
+		if !ok {
+			l.mu.Unlock()
+			return false, time.Second
+		}
+		limiters = append(limiters, b.limiter)
+	}
+	l.mu.Unlock()
+
+	// Token'lar tüm bucket'lardan birlikte alınır; biri reddederse diğerlerinden alınanlar iade edilir.
+	var reservations []*rate.Reservation
+	var retryAfter time.Duration
+	allowed := true
+	for _, lim := range limiters {
+		r := lim.ReserveN(now, 1)
+		if !r.OK() {
+			allowed, retryAfter = false, max(retryAfter, time.Second)
+			continue
+		}
+		reservations = append(reservations, r)
+		if delay := r.DelayFrom(now); delay > 0 {
+			allowed, retryAfter = false, max(retryAfter, delay)
+		}
+	}
+	if !allowed {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+		return false, retryAfter
+	}
+	return true, 0
+}
+
+// scopedLimits: Bir bucket anahtarı ve o anahtarın ailesine ait limitler.
+type scopedLimits struct {
+	key    string
+	limits map[string]Limit
+}
+
+func lookup(limits map[string]Limit, method string) (Limit, bool) {
+	if limit, ok := limits[method]; ok {
+		return limit, true
+	}
+	limit, ok := limits[DefaultMethod]
+	return limit, ok
+}
+
+// bucketLocked, anahtarın bucket'ını döner, yoksa oluşturur. Bucket sınırına ulaşılmışsa
+// ve boşta kalan bucket'lar temizlendikten sonra da yer yoksa false döner. mu tutulmalıdır.
+func (l *Limiter) bucketLocked(key string, limit Limit, now time.Time) (*bucket, bool) {
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.maxBuckets {
+			l.gcLocked(now, true)
+			if len(l.buckets) >= l.maxBuckets {
+				return nil, false
+			}
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b, true
+}
+
+// gcLocked, uzun süredir kullanılmayan bucket'ları temizler. force değilse en fazla
+// idleTTL'de bir çalışır. mu tutulurken çağrılmalıdır.
+func (l *Limiter) gcLocked(now time.Time, force bool) {
+	if !force && now.Sub(l.lastGC) < idleTTL {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastGC = now
+}
+
+// ParseLimits, "Metod=rps:burst" çiftlerinden oluşan virgülle ayrılmış tanımı okur.
+//
+//	FindUserByContact=200:400,GetSipCredentials=500:1000,*=100:200
+func ParseLimits(spec string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		method, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("geçersiz rate limit tanımı: %q (Metod=rps:burst bekleniyor)", part)
+		}
+		rpsStr, burstStr, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("geçersiz rate limit değeri: %q (rps:burst bekleniyor)", value)
+		}
+		rps, err := strconv.ParseFloat(rpsStr, 64)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("geçersiz rps değeri: %q", rpsStr)
+		}
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return nil, fmt.Errorf("geçersiz burst değeri: %q", burstStr)
+		}
+		limits[path.Base(strings.TrimSpace(method))] = Limit{RPS: rps, Burst: burst}
+	}
+	return limits, nil
+}
@@ -0,0 +1,178 @@
+// sentiric-user-service/internal/ratelimit/limiter_test.go
+package ratelimit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock, testlerde zamanı elle ilerletmek içindir.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(clientLimits, tenantLimits map[string]Limit) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(clientLimits, tenantLimits)
+	l.now = clock.now
+	l.lastGC = clock.t
+	return l, clock
+}
+
+// drain, izin verilen istek sayısını döner.
+func drain(l *Limiter, n int, method, client, tenant string) int {
+	allowed := 0
+	for range n {
+		if ok, _ := l.Allow(method, client, tenant); ok {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestParseLimits(t *testing.T) {
+	got, err := ParseLimits(" FindUserByContact=200:400, /sentiric.user.v1.UserService/GetUser=0.5:1,*=100:200,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Limit{
+		"FindUserByContact": {RPS: 200, Burst: 400},
+		"GetUser":           {RPS: 0.5, Burst: 1},
+		DefaultMethod:       {RPS: 100, Burst: 200},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLimits = %v, want %v", got, want)
+	}
+
+	if empty, err := ParseLimits(""); err != nil || len(empty) != 0 {
+		t.Fatalf("ParseLimits(\"\") = %v, %v", empty, err)
+	}
+
+	for _, spec := range []string{
+		"GetUser",
+		"GetUser=10",
+		"GetUser=abc:10",
+		"GetUser=0:10",
+		"GetUser=-1:10",
+		"GetUser=10:abc",
+		"GetUser=10:0",
+	} {
+		if _, err := ParseLimits(spec); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestAllowPerClient(t *testing.T) {
+	l, clock := newTestLimiter(map[string]Limit{"GetUser": {RPS: 1, Burst: 2}}, nil)
+
+	if got := drain(l, 5, "GetUser", "svc-a", ""); got != 2 {
+		t.Fatalf("allowed %d requests, want burst of 2", got)
+	}
+	ok, retryAfter := l.Allow("GetUser", "svc-a", "")
+	if ok || retryAfter <= 0 || retryAfter > time.Second {
+		t.Fatalf("Allow = %v, %v, want rejection with retry-after in (0, 1s]", ok, retryAfter)
+	}
+	if got := drain(l, 2, "GetUser", "svc-b", ""); got != 2 {
+		t.Fatalf("another client allowed %d requests, want its own burst", got)
+	}
+	if ok, _ := l.Allow("FindUserByContact", "svc-a", ""); !ok {
+		t.Fatal("method without limit and without default was throttled")
+	}
+
+	clock.advance(time.Second)
+	if ok, _ := l.Allow("GetUser", "svc-a", ""); !ok {
+		t.Fatal("token not refilled after 1s")
+	}
+}
+
+// TestAllowTenantDoesNotResetClientLimit: Tenant değiştirmek istemciye yeni bir kota vermemelidir.
+func TestAllowTenantDoesNotResetClientLimit(t *testing.T) {
+	l, _ := newTestLimiter(map[string]Limit{DefaultMethod: {RPS: 1, Burst: 3}}, map[string]Limit{DefaultMethod: {RPS: 1, Burst: 100}})
+
+	allowed := 0
+	for i := range 10 {
+		if ok, _ := l.Allow("GetUser", "svc-a", fmt.Sprintf("tenant-%d", i)); ok {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Fatalf("allowed %d requests across rotating tenants, want client burst of 3", allowed)
+	}
+}
+
+func TestAllowPerTenantAcrossClients(t *testing.T) {
+	l, _ := newTestLimiter(map[string]Limit{DefaultMethod: {RPS: 1, Burst: 10}}, map[string]Limit{"GetUser": {RPS: 1, Burst: 2}})
+
+	if got := drain(l, 1, "GetUser", "svc-a", "tenant-a") + drain(l, 5, "GetUser", "svc-b", "tenant-a"); got != 2 {
+		t.Fatalf("tenant-a allowed %d requests across clients, want tenant burst of 2", got)
+	}
+	if ok, _ := l.Allow("GetUser", "svc-b", "tenant-b"); !ok {
+		t.Fatal("other tenant throttled")
+	}
+	// Tenant'ı güvenilir olmayan çağrılar (tenant == "") yalnızca istemci limitine tabidir.
+	if ok, _ := l.Allow("GetUser", "svc-a", ""); !ok {
+		t.Fatal("request without trusted tenant throttled by tenant limit")
+	}
+
+	// Tenant limitine takılan istek istemci kotasından token harcamamalıdır.
+	l2, _ := newTestLimiter(map[string]Limit{DefaultMethod: {RPS: 1, Burst: 2}}, map[string]Limit{DefaultMethod: {RPS: 1, Burst: 1}})
+	drain(l2, 5, "GetUser", "svc-a", "tenant-a")
+	if got := drain(l2, 2, "GetUser", "svc-a", ""); got != 1 {
+		t.Fatalf("client has %d tokens left, want 1 (rejected requests must not consume tokens)", got)
+	}
+}
+
+func TestBucketGC(t *testing.T) {
+	l, clock := newTestLimiter(map[string]Limit{DefaultMethod: {RPS: 1, Burst: 1}}, nil)
+
+	for i := range 5 {
+		l.Allow("GetUser", fmt.Sprintf("client-%d", i), "")
+	}
+	if len(l.buckets) != 5 {
+		t.Fatalf("%d buckets, want 5", len(l.buckets))
+	}
+
+	clock.advance(idleTTL / 2)
+	l.Allow("GetUser", "client-0", "")
+	clock.advance(idleTTL/2 + time.Second)
+	l.Allow("GetUser", "client-new", "")
+
+	if _, ok := l.buckets["client|GetUser|client-0"]; !ok || len(l.buckets) != 2 {
+		t.Fatalf("buckets after GC = %v, want only client-0 and client-new", keys(l.buckets))
+	}
+}
+
+func TestBucketCap(t *testing.T) {
+	l, clock := newTestLimiter(map[string]Limit{DefaultMethod: {RPS: 1, Burst: 1}}, nil)
+	l.maxBuckets = 3
+
+	for i := range 3 {
+		if ok, _ := l.Allow("GetUser", fmt.Sprintf("client-%d", i), ""); !ok {
+			t.Fatalf("client-%d rejected below the cap", i)
+		}
+	}
+	if ok, retryAfter := l.Allow("GetUser", "client-3", ""); ok || retryAfter <= 0 {
+		t.Fatalf("new key accepted at the cap: %v, %v", ok, retryAfter)
+	}
+	if len(l.buckets) != 3 {
+		t.Fatalf("%d buckets, want cap of 3", len(l.buckets))
+	}
+
+	// Boşta kalan bucket'lar temizlenince yeni anahtarlara yer açılır.
+	clock.advance(idleTTL + time.Second)
+	if ok, _ := l.Allow("GetUser", "client-3", ""); !ok {
+		t.Fatal("new key rejected after idle buckets expired")
+	}
+}
+
+func keys(m map[string]*bucket) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
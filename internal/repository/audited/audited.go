@@ -0,0 +1,193 @@
+// sentiric-user-service/internal/repository/audited/audited.go
+package audited
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/audit"
+	"github.com/sentiric/sentiric-user-service/internal/authz"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// ErrNotRecorded, audit kaydı yazılamadığı için değişikliğin uygulanmadığını belirtir. Kayıt
+// değişiklikle aynı transaction'da yazılır; biri başarısız olursa ikisi de geri alınır ve çağrı
+// güvenle tekrar denenebilir. Hata zincirinde alttaki hata da (ör. repository.ErrUnavailable) bulunur.
+var ErrNotRecorded = errors.New("audit kaydı yazılamadı, değişiklik uygulanmadı")
+
+// Repository, değiştiren repository çağrılarının audit kaydını değişiklikle aynı transaction'da
+// (repository.TxHook) audit.Store'a yazar; okumalar doğrudan alttaki repository'ye gider.
+// Kayıt yazılamazsa değişiklik geri alınır, çağrı ErrNotRecorded ile başarısız olur, hata loglanır
+// ve user_service_audit_write_failures_total artırılır. Store audit.TxStore değilse (memory) kayıt
+// hook içinde Append ile yazılır.
+type Repository struct {
+	repository.UserRepository
+
+	store audit.Store
+	log   zerolog.Logger
+}
+
+// New, verilen repository'yi audit katmanıyla sarar.
+func New(next repository.UserRepository, store audit.Store, log zerolog.Logger) *Repository {
+	return &Repository{UserRepository: next, store: store, log: log}
+}
+
+// CreateUser: Kişisel veriler (isim, iletişim değeri) audit kaydına yazılmaz; kayıt user_id ile izlenir.
+func (r *Repository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
+	rec := r.newRecord(ctx, audit.Event{
+		TenantID:   user.GetTenantId(),
+		Action:     audit.ActionUserCreate,
+		TargetType: audit.TargetUser,
+	}, nil, map[string]any{
+		"user_type":               user.GetUserType(),
+		"preferred_language_code": user.GetPreferredLanguageCode(),
+		"contact_type":            initialContact.GetContactType(),
+	})
+	created, err := r.UserRepository.CreateUser(rec.context(ctx), user, initialContact, normalizedContactValue)
+	if err := rec.finish(ctx, err); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CreateSipCredential: HA1 hash'i audit kaydına yazılmaz.
+func (r *Repository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) error {
+	var tenantID string
+	if user, err := r.UserRepository.FetchUserByID(repository.WithPrimary(ctx), userID); err == nil {
+		tenantID = user.GetTenantId()
+	}
+	rec := r.newRecord(ctx, audit.Event{
+		TenantID:   tenantID,
+		Action:     audit.ActionSipCredentialCreate,
+		TargetType: audit.TargetSipCredential,
+	}, nil, map[string]any{"user_id": userID, "sip_username": sipUsername})
+	return rec.finish(ctx, r.UserRepository.CreateSipCredential(rec.context(ctx), userID, sipUsername, ha1Hash))
+}
+
+func (r *Repository) DeleteSipCredential(ctx context.Context, sipUsername string) error {
+	// Silinecek kaydın sahibi silmeden önce okunur; bulunamazsa kayıt tenant'sız yazılır.
+	var before map[string]any
+	var tenantID string
+	if userID, tenant, _, err := r.UserRepository.FetchSipCredentials(repository.WithPrimary(ctx), sipUsername); err == nil {
+		before = map[string]any{"user_id": userID, "sip_username": sipUsername}
+		tenantID = tenant
+	}
+	rec := r.newRecord(ctx, audit.Event{
+		TenantID:   tenantID,
+		Action:     audit.ActionSipCredentialDelete,
+		TargetType: audit.TargetSipCredential,
+	}, before, nil)
+	return rec.finish(ctx, r.UserRepository.DeleteSipCredential(rec.context(ctx), sipUsername))
+}
+
+func (r *Repository) UpsertAgentProfile(ctx context.Context, profile *userv1.AgentProfile, tenantID string) error {
+	var before map[string]any
+	if current, err := r.UserRepository.GetAgentProfile(repository.WithPrimary(ctx), profile.GetUserId()); err == nil {
+		before = agentSnapshot(current)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		l := logger.ContextLogger(ctx, r.log)
+		l.Debug().
+			Str("event", logger.EventAuditSnapshotError).
+			Func(logger.Tenant(tenantID)).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("user_id", profile.GetUserId())).
+			Msg("Audit için mevcut ajan profili okunamadı")
+	}
+	rec := r.newRecord(ctx, audit.Event{
+		TenantID:   tenantID,
+		Action:     audit.ActionAgentProfileUpsert,
+		TargetType: audit.TargetAgentProfile,
+	}, before, agentSnapshot(profile))
+	return rec.finish(ctx, r.UserRepository.UpsertAgentProfile(rec.context(ctx), profile, tenantID))
+}
+
+// --- Helper ---
+
+// record, tek bir değişikliğin bekleyen audit kaydıdır.
+type record struct {
+	r     *Repository
+	event audit.Event
+	// written, hook'un en az bir kez başarıyla çalıştığını; err, son hook denemesinin hatasını tutar.
+	// Repository transaction'ı tekrar denediğinde hook da yeniden çalışır.
+	written bool
+	err     error
+}
+
+// newRecord, kaydı istek kimliği ve trace ID ile tamamlar.
+func (r *Repository) newRecord(ctx context.Context, e audit.Event, before, after map[string]any) *record {
+	id, _ := authz.IdentityFromContext(ctx)
+	e.Actor = id.String()
+	e.TraceID = logger.TraceID(ctx)
+	e.Before, e.After = audit.Changes(before, after)
+	return &record{r: r, event: e}
+}
+
+// context, değişiklikle aynı transaction'da kaydı yazan hook'u ctx'e ekler.
+func (rec *record) context(ctx context.Context) context.Context {
+	return repository.WithTxHook(ctx, rec.append)
+}
+
+func (rec *record) append(ctx context.Context, tx repository.Tx, id string) error {
+	e := rec.event
+	e.TargetID = id
+	if ts, ok := rec.r.store.(audit.TxStore); ok && tx != nil {
+		_, rec.err = ts.AppendTx(ctx, tx, e)
+	} else {
+		_, rec.err = rec.r.store.Append(ctx, e)
+	}
+	if rec.err == nil {
+		rec.event, rec.written = e, true
+	}
+	return rec.err
+}
+
+// finish, değişikliğin sonucunu döner. Değişiklik audit kaydı yazılamadığı için geri alındıysa
+// hata loglanır ve ErrNotRecorded ile sarılır.
+func (rec *record) finish(ctx context.Context, err error) error {
+	switch {
+	case err != nil && rec.err != nil:
+		metrics.IncAuditWriteFailure()
+		rec.log(ctx, rec.err, "Audit kaydı yazılamadı, değişiklik geri alındı")
+		return fmt.Errorf("%w: %w", ErrNotRecorded, err)
+	case err == nil && !rec.written:
+		// Alttaki repository TxHook'u çalıştırmadı: değişiklik uygulandı ama kaydı yok.
+		metrics.IncAuditWriteFailure()
+		rec.log(ctx, errors.New("repository TxHook çalıştırmadı"), "Değişiklik audit kaydı olmadan uygulandı")
+	}
+	return err
+}
+
+func (rec *record) log(ctx context.Context, err error, msg string) {
+	e := rec.event
+	l := logger.ContextLogger(ctx, rec.r.log)
+	l.Error().
+		Str("event", logger.EventAuditWriteFailed).
+		Func(logger.Tenant(e.TenantID)).
+		Err(err).
+		Dict("attributes", zerolog.Dict().
+			Str("actor", e.Actor).
+			Str("action", e.Action).
+			Func(targetID(e))).
+		Msg(msg)
+}
+
+// targetID: SIP credential hedefinin ID'si kullanıcı adıdır ve loga redaction ile yazılır.
+func targetID(e audit.Event) func(*zerolog.Event) {
+	if e.TargetType == audit.TargetSipCredential {
+		return logger.SipUsername("target_id", e.TargetID)
+	}
+	return func(d *zerolog.Event) { d.Str("target_id", e.TargetID) }
+}
+
+func agentSnapshot(p *userv1.AgentProfile) map[string]any {
+	return map[string]any{
+		"display_name":         p.GetDisplayName(),
+		"max_concurrent_calls": p.GetMaxConcurrentCalls(),
+		"status":               p.GetStatus(),
+	}
+}
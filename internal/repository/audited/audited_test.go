@@ -0,0 +1,197 @@
+// sentiric-user-service/internal/repository/audited/audited_test.go
+package audited
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/audit"
+	"github.com/sentiric/sentiric-user-service/internal/authz"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/repository/memory"
+	"github.com/sentiric/sentiric-user-service/internal/repository/repotest"
+	"google.golang.org/grpc/metadata"
+)
+
+const testActor = "spiffe://sentiric/api-gateway"
+
+// TestConformance, audit katmanının repository davranışını değiştirmediğini doğrular.
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Harness {
+		mem := memory.New("tenant-a", "tenant-b")
+		return repotest.Harness{
+			Repo:        New(mem, audit.NewMemoryStore(), zerolog.Nop()),
+			Tenant:      "tenant-a",
+			OtherTenant: "tenant-b",
+			AddContact:  mem.AddContact,
+		}
+	})
+}
+
+func requestContext() context.Context {
+	ctx := authz.WithIdentity(context.Background(), authz.Identity{SpiffeID: testActor})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("x-trace-id", "trace-audit"))
+}
+
+func newAudited(t *testing.T) (*Repository, *audit.MemoryStore, *userv1.User) {
+	t.Helper()
+	store := audit.NewMemoryStore()
+	repo := New(memory.New("tenant-a"), store, zerolog.Nop())
+	user, err := repo.CreateUser(requestContext(), &userv1.User{TenantId: "tenant-a", UserType: "agent"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: "905551234567"}, "905551234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, store, user
+}
+
+func lastEvent(t *testing.T, store audit.Store) audit.Event {
+	t.Helper()
+	events, err := store.Query(context.Background(), audit.Filter{Limit: 1})
+	if err != nil || len(events) == 0 {
+		t.Fatalf("Query = %v, %v", events, err)
+	}
+	return events[0]
+}
+
+func TestCreateUserRecorded(t *testing.T) {
+	_, store, user := newAudited(t)
+
+	e := lastEvent(t, store)
+	if e.Action != audit.ActionUserCreate || e.TargetID != user.Id || e.TenantID != "tenant-a" {
+		t.Fatalf("event = %+v", e)
+	}
+	if e.Actor != testActor || e.TraceID != "trace-audit" {
+		t.Fatalf("actor = %q trace = %q", e.Actor, e.TraceID)
+	}
+	if e.Before != nil || strings.Contains(string(e.After), "905551234567") {
+		t.Fatalf("before = %s after = %s, want no before and no contact value", e.Before, e.After)
+	}
+}
+
+func TestSipCredentialLifecycleRecorded(t *testing.T) {
+	repo, store, user := newAudited(t)
+	ctx := requestContext()
+
+	if err := repo.CreateSipCredential(ctx, user.Id, "1001", "secret-ha1"); err != nil {
+		t.Fatal(err)
+	}
+	created := lastEvent(t, store)
+	if created.Action != audit.ActionSipCredentialCreate || created.TenantID != "tenant-a" || created.TargetID != "1001" {
+		t.Fatalf("create event = %+v", created)
+	}
+	if strings.Contains(string(created.After), "secret-ha1") {
+		t.Fatalf("audit record contains HA1: %s", created.After)
+	}
+
+	if err := repo.DeleteSipCredential(ctx, "1001"); err != nil {
+		t.Fatal(err)
+	}
+	deleted := lastEvent(t, store)
+	if deleted.Action != audit.ActionSipCredentialDelete || deleted.TenantID != "tenant-a" || deleted.After != nil {
+		t.Fatalf("delete event = %+v", deleted)
+	}
+	if want := `{"sip_username":"1001","user_id":"` + user.Id + `"}`; string(deleted.Before) != want {
+		t.Fatalf("delete before = %s, want %s", deleted.Before, want)
+	}
+}
+
+func TestAgentProfileUpsertRecordsDiff(t *testing.T) {
+	repo, store, user := newAudited(t)
+	ctx := requestContext()
+
+	profile := &userv1.AgentProfile{UserId: user.Id, DisplayName: "Ayşe", MaxConcurrentCalls: 2, Status: "OFFLINE"}
+	if err := repo.UpsertAgentProfile(ctx, profile, "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+	first := lastEvent(t, store)
+	if first.Before != nil || string(first.After) != `{"display_name":"Ayşe","max_concurrent_calls":2,"status":"OFFLINE"}` {
+		t.Fatalf("first upsert = %s -> %s", first.Before, first.After)
+	}
+
+	profile.Status = "ONLINE"
+	if err := repo.UpsertAgentProfile(ctx, profile, "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+	second := lastEvent(t, store)
+	if string(second.Before) != `{"status":"OFFLINE"}` || string(second.After) != `{"status":"ONLINE"}` {
+		t.Fatalf("second upsert = %s -> %s", second.Before, second.After)
+	}
+
+	if _, err := store.Verify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailedMutationNotRecorded(t *testing.T) {
+	repo, store, _ := newAudited(t)
+	ctx := requestContext()
+
+	if err := repo.DeleteSipCredential(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("DeleteSipCredential = %v", err)
+	}
+	if err := repo.CreateSipCredential(ctx, "missing-user", "1002", "ha1"); err == nil {
+		t.Fatal("CreateSipCredential succeeded for missing user")
+	}
+	if events, _ := store.Query(context.Background(), audit.Filter{}); len(events) != 1 {
+		t.Fatalf("%d events recorded, want only the CreateUser event", len(events))
+	}
+}
+
+// failingStore, her yazmada hata döner.
+type failingStore struct{ audit.Store }
+
+func (failingStore) Append(context.Context, audit.Event) (audit.Event, error) {
+	return audit.Event{}, errors.New("db down")
+}
+
+// TestAuditFailureRollsBackMutation: Audit kaydı yazılamayan değişiklik uygulanmamalı ve
+// istemciye ErrNotRecorded ile dönmelidir.
+func TestAuditFailureRollsBackMutation(t *testing.T) {
+	mem := memory.New("tenant-a")
+	repo := New(mem, failingStore{}, zerolog.Nop())
+	ctx := requestContext()
+
+	_, err := repo.CreateUser(ctx, &userv1.User{TenantId: "tenant-a", UserType: "caller"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "email", ContactValue: "a@example.com"}, "a@example.com")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("CreateUser = %v, want ErrNotRecorded", err)
+	}
+	if _, err := mem.FetchUserByContact(ctx, "email", "a@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("user created without an audit record: %v", err)
+	}
+
+	user, err := mem.CreateUser(ctx, &userv1.User{TenantId: "tenant-a", UserType: "agent"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "email", ContactValue: "b@example.com"}, "b@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateSipCredential(ctx, user.Id, "1001", "ha1"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("CreateSipCredential = %v, want ErrNotRecorded", err)
+	}
+	if _, _, _, err := mem.FetchSipCredentials(ctx, "1001"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("SIP credential created without an audit record: %v", err)
+	}
+
+	if err := mem.CreateSipCredential(ctx, user.Id, "1002", "ha1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteSipCredential(ctx, "1002"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("DeleteSipCredential = %v, want ErrNotRecorded", err)
+	}
+	if _, _, _, err := mem.FetchSipCredentials(ctx, "1002"); err != nil {
+		t.Fatalf("SIP credential deleted without an audit record: %v", err)
+	}
+
+	profile := &userv1.AgentProfile{UserId: user.Id, DisplayName: "Ayşe", MaxConcurrentCalls: 2, Status: "ONLINE"}
+	if err := repo.UpsertAgentProfile(ctx, profile, "tenant-a"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("UpsertAgentProfile = %v, want ErrNotRecorded", err)
+	}
+	if _, err := mem.GetAgentProfile(ctx, user.Id); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("agent profile saved without an audit record: %v", err)
+	}
+}
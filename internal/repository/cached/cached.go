@@ -0,0 +1,235 @@
+// sentiric-user-service/internal/repository/cached/cached.go
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+)
+
+// Önbellek adları (user_service_cache_lookups_total{cache}).
+const (
+	cacheContact = "contact"
+	cacheSip     = "sip"
+)
+
+// Options, önbellek ayarlarıdır.
+type Options struct {
+	// MaxEntries: Her önbellek (contact, sip) için en fazla girdi sayısı.
+	MaxEntries int
+	// TTL: Bulunan kayıtların saklanma süresi. NOTIFY kaçırılsa bile bayatlık bu süreyle sınırlıdır.
+	TTL time.Duration
+	// NegativeTTL: "Kayıt yok" sonuçlarının saklanma süresi; kısa tutulmalıdır.
+	NegativeTTL time.Duration
+}
+
+// sipCredentials, FetchSipCredentials sonucudur.
+type sipCredentials struct {
+	userID, tenantID, ha1Hash string
+}
+
+// Repository, sıcak yoldaki iki okumayı (FindUserByContact ve GetSipCredentials) süreç içi
+// LRU/TTL önbellekle karşılar; diğer tüm çağrılar doğrudan alttaki repository'ye gider.
+// Aynı anahtar için eşzamanlı miss'ler singleflight ile tek sorguya indirgenir.
+// Miss'ler primary'den yüklenir: geride kalan bir replika NOTIFY'dan sonra silinmiş bir kaydı
+// hâlâ dönebilir ve bu eski değer TTL boyunca önbellekte kalırdı.
+type Repository struct {
+	repository.UserRepository
+
+	opts     Options
+	contacts *lru[*userv1.User]
+	sip      *lru[sipCredentials]
+	group    singleflight.Group
+	// gen, her invalidation'da artar. Sorgu sürerken invalidation gelirse sonuç önbelleğe yazılmaz;
+	// aksi halde invalidation'dan önce okunmuş eski veri önbellekte kalabilirdi.
+	gen atomic.Uint64
+	log zerolog.Logger
+}
+
+// New, verilen repository'yi önbellek katmanıyla sarar.
+func New(next repository.UserRepository, opts Options, log zerolog.Logger) *Repository {
+	return &Repository{
+		UserRepository: next,
+		opts:           opts,
+		contacts:       newLRU[*userv1.User](opts.MaxEntries),
+		sip:            newLRU[sipCredentials](opts.MaxEntries),
+		log:            log,
+	}
+}
+
+func contactKey(contactType, contactValue string) string {
+	return contactType + ":" + contactValue
+}
+
+func (r *Repository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
+	key := contactKey(contactType, contactValue)
+	if user, found, ok := r.contacts.get(key); ok {
+		metrics.IncCacheLookup(cacheContact, metrics.CacheHit)
+		if !found {
+			return nil, repository.ErrNotFound
+		}
+		return proto.Clone(user).(*userv1.User), nil
+	}
+	metrics.IncCacheLookup(cacheContact, metrics.CacheMiss)
+
+	gen := r.gen.Load()
+	v, err, _ := r.group.Do(cacheContact+"|"+key, func() (any, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		user, err := r.UserRepository.FetchUserByContact(repository.WithPrimary(ctx), contactType, contactValue)
+		switch {
+		case err == nil:
+			r.store(gen, func() { r.contacts.set(key, user.Id, user, true, r.opts.TTL) })
+		case errors.Is(err, repository.ErrNotFound):
+			r.store(gen, func() { r.contacts.set(key, "", nil, false, r.opts.NegativeTTL) })
+		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(v.(*userv1.User)).(*userv1.User), nil
+}
+
+func (r *Repository) FetchSipCredentials(ctx context.Context, sipUsername string) (userID, tenantID, ha1Hash string, err error) {
+	if creds, found, ok := r.sip.get(sipUsername); ok {
+		metrics.IncCacheLookup(cacheSip, metrics.CacheHit)
+		if !found {
+			return "", "", "", repository.ErrNotFound
+		}
+		return creds.userID, creds.tenantID, creds.ha1Hash, nil
+	}
+	metrics.IncCacheLookup(cacheSip, metrics.CacheMiss)
+
+	gen := r.gen.Load()
+	v, err, _ := r.group.Do(cacheSip+"|"+sipUsername, func() (any, error) {
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		var creds sipCredentials
+		var err error
+		creds.userID, creds.tenantID, creds.ha1Hash, err = r.UserRepository.FetchSipCredentials(repository.WithPrimary(ctx), sipUsername)
+		switch {
+		case err == nil:
+			r.store(gen, func() { r.sip.set(sipUsername, creds.userID, creds, true, r.opts.TTL) })
+		case errors.Is(err, repository.ErrNotFound):
+			r.store(gen, func() { r.sip.set(sipUsername, "", sipCredentials{}, false, r.opts.NegativeTTL) })
+		}
+		return creds, err
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	creds := v.(sipCredentials)
+	return creds.userID, creds.tenantID, creds.ha1Hash, nil
+}
+
+// detach, paylaşılan sorgunun ilk çağıranın iptaliyle diğer bekleyenler için de iptal
+// olmasını engeller; deadline korunur ki takılan bir sorgu sonsuza kadar beklemesin.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+// store, sorgu başladığından beri invalidation olmadıysa sonucu önbelleğe yazar.
+func (r *Repository) store(gen uint64, set func()) {
+	if r.gen.Load() == gen {
+		set()
+	}
+}
+
+// --- Yazmalar: yerel önbellek hemen temizlenir, diğer replikalar NOTIFY ile haberdar olur ---
+
+func (r *Repository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
+	created, err := r.UserRepository.CreateUser(ctx, user, initialContact, normalizedContactValue)
+	// Hata durumunda da (ör. commit sonucu belirsiz) negatif girdi silinir.
+	r.invalidateContact(initialContact.GetContactType(), normalizedContactValue)
+	return created, err
+}
+
+func (r *Repository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) error {
+	err := r.UserRepository.CreateSipCredential(ctx, userID, sipUsername, ha1Hash)
+	r.invalidateSip(sipUsername)
+	return err
+}
+
+func (r *Repository) DeleteSipCredential(ctx context.Context, sipUsername string) error {
+	err := r.UserRepository.DeleteSipCredential(ctx, sipUsername)
+	r.invalidateSip(sipUsername)
+	return err
+}
+
+// --- Invalidation ---
+
+func (r *Repository) invalidateContact(contactType, contactValue string) {
+	r.gen.Add(1)
+	r.contacts.delete(contactKey(contactType, contactValue))
+}
+
+func (r *Repository) invalidateSip(sipUsername string) {
+	r.gen.Add(1)
+	r.sip.delete(sipUsername)
+}
+
+func (r *Repository) invalidateUser(userID string) {
+	r.gen.Add(1)
+	r.contacts.deleteUser(userID)
+	r.sip.deleteUser(userID)
+}
+
+// Purge, tüm önbelleği temizler. NOTIFY bağlantısı koptuğunda kaçırılmış olabilecek
+// invalidation'lar yüzünden çağrılır.
+func (r *Repository) Purge() {
+	r.gen.Add(1)
+	r.contacts.purge()
+	r.sip.purge()
+	metrics.IncCacheInvalidation(metrics.CacheInvalidationPurge)
+}
+
+// notification, 0002 migration'ındaki tetikleyicilerin gönderdiği NOTIFY içeriğidir.
+type notification struct {
+	Table        string `json:"table"`
+	UserID       string `json:"user_id"`
+	ContactType  string `json:"contact_type"`
+	ContactValue string `json:"contact_value"`
+	SipUsername  string `json:"sip_username"`
+}
+
+// HandleNotification, bir NOTIFY mesajını işler. Kullanıcıya ait tüm girdiler silinir;
+// contact ve sip anahtarları ayrıca silinir ki negatif girdiler de temizlensin.
+func (r *Repository) HandleNotification(payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		// Ne değiştiği bilinmiyorsa güvenli taraf tüm önbelleği atmaktır. Payload iletişim değeri
+		// ve SIP kullanıcı adı içerebildiği için loga yalnızca boyutu yazılır.
+		r.log.Warn().
+			Str("event", logger.EventCacheNotifyError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Int("payload_bytes", len(payload))).
+			Msg("Önbellek bildirimi çözümlenemedi, önbellek temizleniyor")
+		r.Purge()
+		return
+	}
+	if n.UserID != "" {
+		r.invalidateUser(n.UserID)
+	}
+	if n.ContactType != "" {
+		r.invalidateContact(n.ContactType, n.ContactValue)
+	}
+	if n.SipUsername != "" {
+		r.invalidateSip(n.SipUsername)
+	}
+	metrics.IncCacheInvalidation(metrics.CacheInvalidationNotify)
+}
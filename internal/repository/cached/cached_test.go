@@ -0,0 +1,307 @@
+// sentiric-user-service/internal/repository/cached/cached_test.go
+package cached
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/logger/logtest"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/repository/memory"
+	"github.com/sentiric/sentiric-user-service/internal/repository/repotest"
+)
+
+// stubRepository, yalnızca önbelleklenen iki okumayı sayarak cevaplar.
+type stubRepository struct {
+	repository.UserRepository
+
+	mu      sync.Mutex
+	users   map[string]*userv1.User // contact key -> user
+	sip     map[string]sipCredentials
+	calls   atomic.Int32
+	release chan struct{} // nil değilse sorgular bu kanal kapanana kadar bekler
+}
+
+func newStub() *stubRepository {
+	return &stubRepository{users: map[string]*userv1.User{}, sip: map[string]sipCredentials{}}
+}
+
+func (s *stubRepository) FetchUserByContact(_ context.Context, contactType, contactValue string) (*userv1.User, error) {
+	s.calls.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[contactKey(contactType, contactValue)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *stubRepository) FetchSipCredentials(_ context.Context, sipUsername string) (string, string, string, error) {
+	s.calls.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.sip[sipUsername]
+	if !ok {
+		return "", "", "", repository.ErrNotFound
+	}
+	return c.userID, c.tenantID, c.ha1Hash, nil
+}
+
+func (s *stubRepository) put(contactValue string, user *userv1.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[contactKey("phone", contactValue)] = user
+}
+
+var testOptions = Options{MaxEntries: 100, TTL: time.Minute, NegativeTTL: time.Minute}
+
+func TestCacheServesRepeatedLookupsFromMemory(t *testing.T) {
+	stub := newStub()
+	stub.put("+905551112233", &userv1.User{Id: "u-1", TenantId: "t-1"})
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	for i := 0; i < 3; i++ {
+		user, err := repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+		if err != nil || user.Id != "u-1" {
+			t.Fatalf("lookup %d: user=%v err=%v", i, user, err)
+		}
+	}
+	if got := stub.calls.Load(); got != 1 {
+		t.Fatalf("repository called %d times, want 1", got)
+	}
+}
+
+func TestCacheReturnsCopies(t *testing.T) {
+	stub := newStub()
+	stub.put("+905551112233", &userv1.User{Id: "u-1", TenantId: "t-1"})
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	first, _ := repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+	first.TenantId = "mutated"
+	second, _ := repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+	if second.TenantId != "t-1" {
+		t.Fatalf("caller mutation leaked into cache: tenant=%q", second.TenantId)
+	}
+}
+
+func TestCacheNegativeEntriesAndLocalInvalidation(t *testing.T) {
+	stub := newStub()
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := repo.FetchSipCredentials(context.Background(), "alice"); err != repository.ErrNotFound {
+			t.Fatalf("err=%v, want ErrNotFound", err)
+		}
+	}
+	if got := stub.calls.Load(); got != 1 {
+		t.Fatalf("not-found result was not cached: %d calls", got)
+	}
+
+	stub.sip["alice"] = sipCredentials{userID: "u-1", tenantID: "t-1", ha1Hash: "h"}
+	repo.HandleNotification(`{"table":"sip_credentials","user_id":"u-1","sip_username":"alice"}`)
+
+	userID, _, ha1, err := repo.FetchSipCredentials(context.Background(), "alice")
+	if err != nil || userID != "u-1" || ha1 != "h" {
+		t.Fatalf("after invalidation: user=%q ha1=%q err=%v", userID, ha1, err)
+	}
+}
+
+func TestCacheInvalidatesAllEntriesOfUser(t *testing.T) {
+	stub := newStub()
+	stub.put("+905551112233", &userv1.User{Id: "u-1", TenantId: "t-1"})
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+	stub.put("+905551112233", &userv1.User{Id: "u-1", TenantId: "t-2"})
+	repo.HandleNotification(`{"table":"users","user_id":"u-1"}`)
+
+	user, err := repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+	if err != nil || user.TenantId != "t-2" {
+		t.Fatalf("stale entry after user invalidation: user=%v err=%v", user, err)
+	}
+}
+
+func TestCacheCollapsesConcurrentMisses(t *testing.T) {
+	stub := newStub()
+	stub.put("+905551112233", &userv1.User{Id: "u-1"})
+	stub.release = make(chan struct{})
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.FetchUserByContact(context.Background(), "phone", "+905551112233"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// Tüm goroutine'lerin singleflight'a girmesi için kısa bir süre tanınır.
+	time.Sleep(50 * time.Millisecond)
+	close(stub.release)
+	wg.Wait()
+
+	if got := stub.calls.Load(); got != 1 {
+		t.Fatalf("concurrent misses hit the repository %d times, want 1", got)
+	}
+}
+
+func TestCacheDropsResultLoadedBeforeInvalidation(t *testing.T) {
+	stub := newStub()
+	stub.put("+905551112233", &userv1.User{Id: "u-1", TenantId: "old"})
+	stub.release = make(chan struct{})
+	repo := New(stub, testOptions, zerolog.Nop())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.FetchUserByContact(context.Background(), "phone", "+905551112233")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	repo.HandleNotification(`{"table":"users","user_id":"u-1"}`)
+	close(stub.release)
+	<-done
+
+	if _, _, ok := repo.contacts.get(contactKey("phone", "+905551112233")); ok {
+		t.Fatal("result read before invalidation was cached")
+	}
+}
+
+// TestInvalidNotificationNotLogged: Çözülemeyen bildirim önbelleği temizlemeli, fakat kişisel
+// veri içerebilen payload loga yazılmamalıdır.
+func TestInvalidNotificationNotLogged(t *testing.T) {
+	stub := newStub()
+	stub.sip["alice"] = sipCredentials{userID: "u-1", tenantID: "tenant-a", ha1Hash: "ha1"}
+	log, logs := logtest.New()
+	repo := New(stub, testOptions, log)
+	repo.FetchSipCredentials(context.Background(), "alice")
+
+	repo.HandleNotification(`{"table":"contacts","contact_value":"+905551112233","sip_username":"alice"`)
+
+	if _, _, ok := repo.sip.get("alice"); ok {
+		t.Fatal("cache not purged after invalid notification")
+	}
+	if out := logs.String(); strings.Contains(out, "905551112233") || strings.Contains(out, "alice") {
+		t.Fatalf("log contains notification payload: %s", out)
+	}
+	logs.RequireSUTS(t)
+}
+
+func TestLRUEvictsAndExpires(t *testing.T) {
+	now := time.Now()
+	c := newLRU[int](2)
+	c.nowFunc = func() time.Time { return now }
+
+	c.set("a", "u-1", 1, true, time.Minute)
+	c.set("b", "u-2", 2, true, time.Minute)
+	c.get("a") // a en son kullanılan olur
+	c.set("c", "u-3", 3, true, time.Second)
+
+	if _, _, ok := c.get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, _, ok := c.get("a"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, _, ok := c.get("c"); ok {
+		t.Error("expired entry was returned")
+	}
+	if c.len() != 1 {
+		t.Errorf("len = %d, want 1", c.len())
+	}
+}
+
+// laggingReplica, WithPrimary ile işaretlenmemiş okumaları geride kalmış bir replikadan
+// (snapshot alındığı andaki veri) yanıtlar; primary okumaları güncel backend'e gider.
+type laggingReplica struct {
+	repository.UserRepository
+	staleSip     map[string]sipCredentials
+	staleContact map[string]*userv1.User
+}
+
+func (r *laggingReplica) FetchSipCredentials(ctx context.Context, sipUsername string) (string, string, string, error) {
+	if repository.PrimaryRequired(ctx) {
+		return r.UserRepository.FetchSipCredentials(ctx, sipUsername)
+	}
+	c, ok := r.staleSip[sipUsername]
+	if !ok {
+		return "", "", "", repository.ErrNotFound
+	}
+	return c.userID, c.tenantID, c.ha1Hash, nil
+}
+
+func (r *laggingReplica) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
+	if repository.PrimaryRequired(ctx) {
+		return r.UserRepository.FetchUserByContact(ctx, contactType, contactValue)
+	}
+	user, ok := r.staleContact[contactKey(contactType, contactValue)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+// TestCacheLoadsMissesFromPrimary: Silinen bir SIP kimliği ve yeni oluşturulan bir kullanıcı,
+// replika henüz güncellenmemişken önbelleğe eski hâliyle yazılmamalıdır.
+func TestCacheLoadsMissesFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.New("tenant-a")
+	user, err := backend.CreateUser(ctx, &userv1.User{TenantId: "tenant-a", UserType: "agent"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: "+905551112233"}, "+905551112233")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.CreateSipCredential(ctx, user.Id, "1001", "ha1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replika, SIP kimliğinin var olduğu ama yeni kullanıcının henüz gelmediği anda kalmıştır.
+	replica := &laggingReplica{
+		UserRepository: backend,
+		staleSip:       map[string]sipCredentials{"1001": {userID: user.Id, tenantID: "tenant-a", ha1Hash: "ha1"}},
+	}
+	repo := New(replica, testOptions, zerolog.Nop())
+
+	if err := repo.DeleteSipCredential(ctx, "1001"); err != nil {
+		t.Fatal(err)
+	}
+	repo.HandleNotification(`{"table":"sip_credentials","user_id":"` + user.Id + `","sip_username":"1001"}`)
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := repo.FetchSipCredentials(ctx, "1001"); err != repository.ErrNotFound {
+			t.Fatalf("lookup %d of deleted credential: err=%v, want ErrNotFound", i, err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := repo.FetchUserByContact(ctx, "phone", "+905551112233")
+		if err != nil || got.Id != user.Id {
+			t.Fatalf("lookup %d of new user: user=%v err=%v", i, got, err)
+		}
+	}
+}
+
+// TestConformance, önbellek katmanının sardığı repository'nin davranışını değiştirmediğini doğrular.
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Harness {
+		backend := memory.New("tenant-a", "tenant-b")
+		return repotest.Harness{
+			Repo:        New(backend, testOptions, zerolog.Nop()),
+			Tenant:      "tenant-a",
+			OtherTenant: "tenant-b",
+			AddContact:  backend.AddContact,
+		}
+	})
+}
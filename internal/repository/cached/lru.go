@@ -0,0 +1,127 @@
+// sentiric-user-service/internal/repository/cached/lru.go
+package cached
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru, boyutu sınırlı ve girdileri TTL ile sona eren, eşzamanlı kullanıma uygun bir önbellektir.
+// Her girdi ilişkili olduğu kullanıcıya göre de indekslenir; böylece bir kullanıcı
+// değiştiğinde ona ait tüm anahtarlar tek seferde silinebilir.
+type lru[V any] struct {
+	mu      sync.Mutex
+	max     int
+	items   map[string]*list.Element
+	order   *list.List // ön: en son kullanılan
+	byUser  map[string]map[string]struct{}
+	nowFunc func() time.Time
+}
+
+type entry[V any] struct {
+	key     string
+	userID  string
+	value   V
+	found   bool
+	expires time.Time
+}
+
+func newLRU[V any](max int) *lru[V] {
+	return &lru[V]{
+		max:     max,
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		byUser:  make(map[string]map[string]struct{}),
+		nowFunc: time.Now,
+	}
+}
+
+// get, anahtarın değerini döner. ok=false ise girdi yoktur veya süresi dolmuştur;
+// found=false ise girdi bir negatif (kayıt yok) sonuçtur.
+func (c *lru[V]) get(key string) (value V, found, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, exists := c.items[key]
+	if !exists {
+		return value, false, false
+	}
+	e := el.Value.(*entry[V])
+	if c.nowFunc().After(e.expires) {
+		c.removeElement(el)
+		return value, false, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, e.found, true
+}
+
+// set, değeri ttl süresince saklar. Kapasite aşılırsa en uzun süredir kullanılmayan girdi atılır.
+func (c *lru[V]) set(key, userID string, value V, found bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, exists := c.items[key]; exists {
+		c.removeElement(el)
+	}
+	e := &entry[V]{key: key, userID: userID, value: value, found: found, expires: c.nowFunc().Add(ttl)}
+	c.items[key] = c.order.PushFront(e)
+	if userID != "" {
+		keys, ok := c.byUser[userID]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.byUser[userID] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.order.Len() > c.max {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// delete, anahtarı siler.
+func (c *lru[V]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, exists := c.items[key]; exists {
+		c.removeElement(el)
+	}
+}
+
+// deleteUser, kullanıcıya ait tüm girdileri siler.
+func (c *lru[V]) deleteUser(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.byUser[userID] {
+		if el, exists := c.items[key]; exists {
+			c.removeElement(el)
+		}
+	}
+}
+
+// purge, tüm girdileri siler.
+func (c *lru[V]) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.byUser = make(map[string]map[string]struct{})
+	c.order.Init()
+}
+
+func (c *lru[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *lru[V]) removeElement(el *list.Element) {
+	e := c.order.Remove(el).(*entry[V])
+	delete(c.items, e.key)
+	if keys, ok := c.byUser[e.userID]; ok {
+		delete(keys, e.key)
+		if len(keys) == 0 {
+			delete(c.byUser, e.userID)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// sentiric-user-service/internal/repository/consistency.go
+package repository
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary, okuma sorgularının replika yerine primary'ye gitmesini ister.
+// Yazmanın hemen ardından okuyan (read-after-write) akışlarda replikasyon gecikmesi
+// yüzünden yeni kaydın görünmemesini önler.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired, context'in WithPrimary ile işaretlenip işaretlenmediğini döner.
+func PrimaryRequired(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
@@ -10,6 +10,18 @@ var (
 	// ErrConflict: Kayıt zaten mevcut (Unique constraint violation).
 	ErrConflict = errors.New("record already exists")
 
+	// ErrForeignKey: Referans verilen kayıt mevcut değil (Foreign key violation).
+	ErrForeignKey = errors.New("referenced record does not exist")
+
+	// ErrCheckViolation: Değer bir CHECK kısıtını ihlal ediyor.
+	ErrCheckViolation = errors.New("check constraint violated")
+
+	// ErrRetryable: Geçici hata (serialization failure, deadlock); işlem tekrar denenebilir.
+	ErrRetryable = errors.New("transient database error")
+
+	// ErrUnavailable: Veritabanına ulaşılamıyor (bağlantı kopması, failover, sunucu kapanıyor).
+	ErrUnavailable = errors.New("database unavailable")
+
 	// ErrDatabase: Beklenmeyen veritabanı hatası.
 	ErrDatabase = errors.New("database internal error")
 )
@@ -0,0 +1,138 @@
+// sentiric-user-service/internal/repository/instrumented/instrumented.go
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Repository, herhangi bir UserRepository implementasyonunu sarar; her operasyon
+// için bir child span açar ve süresini metriklere yazar. Backend'den bağımsızdır.
+type Repository struct {
+	next repository.UserRepository
+}
+
+// New, verilen repository'yi ölçümleme katmanıyla sarar.
+func New(next repository.UserRepository) repository.UserRepository {
+	return &Repository{next: next}
+}
+
+// begin, operasyon için span açar. Dönen fonksiyon operasyon sonunda hata ile
+// çağrılır; span'i kapatır, süreyi ve sonucu (ok, not_found, conflict, error) kaydeder.
+func begin(ctx context.Context, operation string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := telemetry.Tracer().Start(ctx, "repository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation", operation)),
+	)
+
+	return ctx, func(err error) {
+		res := result(err)
+		metrics.ObserveQuery(operation, res, time.Since(start))
+
+		// not_found ve conflict iş akışının parçasıdır, span'i hatalı işaretlemez.
+		if res == "error" {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(attribute.String("db.result", res))
+		span.End()
+	}
+}
+
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, repository.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, repository.ErrConflict):
+		return "conflict"
+	default:
+		return "error"
+	}
+}
+
+// --- User CRUD ---
+
+func (r *Repository) FetchUserByID(ctx context.Context, userID string) (user *userv1.User, err error) {
+	ctx, end := begin(ctx, "fetch_user_by_id")
+	defer func() { end(err) }()
+	return r.next.FetchUserByID(ctx, userID)
+}
+
+func (r *Repository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (user *userv1.User, err error) {
+	ctx, end := begin(ctx, "fetch_user_by_contact")
+	defer func() { end(err) }()
+	return r.next.FetchUserByContact(ctx, contactType, contactValue)
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (created *userv1.User, err error) {
+	ctx, end := begin(ctx, "create_user")
+	defer func() { end(err) }()
+	return r.next.CreateUser(ctx, user, initialContact, normalizedContactValue)
+}
+
+// --- Sip Credentials ---
+
+func (r *Repository) FetchSipCredentials(ctx context.Context, sipUsername string) (userID, tenantID, ha1Hash string, err error) {
+	ctx, end := begin(ctx, "fetch_sip_credentials")
+	defer func() { end(err) }()
+	return r.next.FetchSipCredentials(ctx, sipUsername)
+}
+
+func (r *Repository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) (err error) {
+	ctx, end := begin(ctx, "create_sip_credential")
+	defer func() { end(err) }()
+	return r.next.CreateSipCredential(ctx, userID, sipUsername, ha1Hash)
+}
+
+func (r *Repository) DeleteSipCredential(ctx context.Context, sipUsername string) (err error) {
+	ctx, end := begin(ctx, "delete_sip_credential")
+	defer func() { end(err) }()
+	return r.next.DeleteSipCredential(ctx, sipUsername)
+}
+
+// --- Helper ---
+
+func (r *Repository) FetchContactsForUser(ctx context.Context, userID string) (contacts []*userv1.Contact, err error) {
+	ctx, end := begin(ctx, "fetch_contacts_for_user")
+	defer func() { end(err) }()
+	return r.next.FetchContactsForUser(ctx, userID)
+}
+
+func (r *Repository) FetchContactsForUsers(ctx context.Context, userIDs []string) (contacts map[string][]*userv1.Contact, err error) {
+	ctx, end := begin(ctx, "fetch_contacts_for_users")
+	defer func() { end(err) }()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("db.batch_size", len(userIDs)))
+	return r.next.FetchContactsForUsers(ctx, userIDs)
+}
+
+// --- Agent Profiles ---
+
+func (r *Repository) GetAgentProfile(ctx context.Context, userID string) (profile *userv1.AgentProfile, err error) {
+	ctx, end := begin(ctx, "get_agent_profile")
+	defer func() { end(err) }()
+	return r.next.GetAgentProfile(ctx, userID)
+}
+
+func (r *Repository) UpsertAgentProfile(ctx context.Context, profile *userv1.AgentProfile, tenantID string) (err error) {
+	ctx, end := begin(ctx, "upsert_agent_profile")
+	defer func() { end(err) }()
+	return r.next.UpsertAgentProfile(ctx, profile, tenantID)
+}
+
+func (r *Repository) CountAgentsByStatus(ctx context.Context) (counts []repository.AgentStatusCount, err error) {
+	ctx, end := begin(ctx, "count_agents_by_status")
+	defer func() { end(err) }()
+	return r.next.CountAgentsByStatus(ctx)
+}
@@ -0,0 +1,324 @@
+// sentiric-user-service/internal/repository/memory/memory.go
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"google.golang.org/protobuf/proto"
+)
+
+// Repository, UserRepository'nin veritabanı gerektirmeyen, eşzamanlı kullanıma uygun
+// implementasyonudur. Postgres şemasındaki kısıtları (tenant/user foreign key'leri,
+// (contact_type, contact_value) ve sip_username tekilliği, max_concurrent_calls > 0)
+// aynı repository hatalarıyla uygular. Transaction yoktur; TxHook'lar değişiklik uygulanmadan
+// hemen önce, kilit altında çalışır ve hata dönerlerse değişiklik yapılmaz. Yerel demolar ve testler içindir; veri süreçle birlikte kaybolur.
+type Repository struct {
+	mu sync.RWMutex
+
+	tenants       map[string]struct{}
+	users         map[string]*userv1.User    // contacts alanı boş tutulur
+	contacts      map[string]*userv1.Contact // contactKey -> contact
+	nextContactID int32
+	sip           map[string]sipCredential
+	agents        map[string]agentProfile
+}
+
+type sipCredential struct {
+	userID  string
+	ha1Hash string
+}
+
+type agentProfile struct {
+	profile  *userv1.AgentProfile
+	tenantID string
+}
+
+// New, verilen tenant'larla boş bir repository oluşturur. Postgres'te olduğu gibi
+// kayıtlı olmayan bir tenant'a kullanıcı eklemek ErrForeignKey döner.
+func New(tenants ...string) *Repository {
+	r := &Repository{
+		tenants:  make(map[string]struct{}),
+		users:    make(map[string]*userv1.User),
+		contacts: make(map[string]*userv1.Contact),
+		sip:      make(map[string]sipCredential),
+		agents:   make(map[string]agentProfile),
+	}
+	for _, id := range tenants {
+		r.tenants[id] = struct{}{}
+	}
+	return r
+}
+
+// AddTenant, bir tenant kaydeder (tenants tablosu bu servis tarafından yönetilmez).
+func (r *Repository) AddTenant(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[id] = struct{}{}
+}
+
+func contactKey(contactType, contactValue string) string {
+	return contactType + ":" + contactValue
+}
+
+// --- User CRUD ---
+
+func (r *Repository) FetchUserByID(ctx context.Context, userID string) (*userv1.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return r.withContacts(user), nil
+}
+
+func (r *Repository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	contact, ok := r.contacts[contactKey(contactType, contactValue)]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return r.withContacts(r.users[contact.UserId]), nil
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[user.GetTenantId()]; !ok {
+		return nil, repository.ErrForeignKey
+	}
+	key := contactKey(initialContact.GetContactType(), normalizedContactValue)
+	if _, exists := r.contacts[key]; exists {
+		return nil, repository.ErrConflict
+	}
+
+	created := &userv1.User{
+		Id:                    uuid.NewString(),
+		Name:                  user.Name,
+		TenantId:              user.TenantId,
+		UserType:              user.UserType,
+		PreferredLanguageCode: user.PreferredLanguageCode,
+	}
+	if err := repository.RunTxHook(ctx, nil, created.Id); err != nil {
+		return nil, err
+	}
+	r.users[created.Id] = created
+	r.insertContact(created.Id, initialContact.GetContactType(), normalizedContactValue, true)
+
+	return r.withContacts(created), nil
+}
+
+// AddContact, kullanıcıya ek bir iletişim bilgisi ekler. UserRepository'de karşılığı yoktur;
+// demo verisi hazırlamak ve uyumluluk testleri içindir.
+func (r *Repository) AddContact(ctx context.Context, userID, contactType, contactValue string, primary bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return repository.ErrForeignKey
+	}
+	if _, exists := r.contacts[contactKey(contactType, contactValue)]; exists {
+		return repository.ErrConflict
+	}
+	r.insertContact(userID, contactType, contactValue, primary)
+	return nil
+}
+
+// insertContact, SERIAL id ile yeni bir iletişim bilgisi ekler. Kilit çağıran tarafta tutulmalıdır.
+func (r *Repository) insertContact(userID, contactType, contactValue string, primary bool) {
+	r.nextContactID++
+	r.contacts[contactKey(contactType, contactValue)] = &userv1.Contact{
+		Id:           r.nextContactID,
+		UserId:       userID,
+		ContactType:  contactType,
+		ContactValue: contactValue,
+		IsPrimary:    primary,
+	}
+}
+
+// --- Sip Credentials ---
+
+func (r *Repository) FetchSipCredentials(ctx context.Context, sipUsername string) (userID, tenantID, ha1Hash string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", "", "", err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cred, ok := r.sip[sipUsername]
+	if !ok {
+		return "", "", "", repository.ErrNotFound
+	}
+	return cred.userID, r.users[cred.userID].GetTenantId(), cred.ha1Hash, nil
+}
+
+func (r *Repository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return repository.ErrForeignKey
+	}
+	if _, exists := r.sip[sipUsername]; exists {
+		return repository.ErrConflict
+	}
+	if err := repository.RunTxHook(ctx, nil, sipUsername); err != nil {
+		return err
+	}
+	r.sip[sipUsername] = sipCredential{userID: userID, ha1Hash: ha1Hash}
+	return nil
+}
+
+func (r *Repository) DeleteSipCredential(ctx context.Context, sipUsername string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sip[sipUsername]; !ok {
+		return repository.ErrNotFound
+	}
+	if err := repository.RunTxHook(ctx, nil, sipUsername); err != nil {
+		return err
+	}
+	delete(r.sip, sipUsername)
+	return nil
+}
+
+// --- Helper / Internal ---
+
+func (r *Repository) FetchContactsForUser(ctx context.Context, userID string) ([]*userv1.Contact, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.contactsOf(userID), nil
+}
+
+func (r *Repository) FetchContactsForUsers(ctx context.Context, userIDs []string) (map[string][]*userv1.Contact, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byUser := make(map[string][]*userv1.Contact, len(userIDs))
+	for _, id := range userIDs {
+		if contacts := r.contactsOf(id); len(contacts) > 0 {
+			byUser[id] = contacts
+		}
+	}
+	return byUser, nil
+}
+
+// --- Agent Profiles ---
+
+func (r *Repository) GetAgentProfile(ctx context.Context, userID string) (*userv1.AgentProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agent, ok := r.agents[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return proto.Clone(agent.profile).(*userv1.AgentProfile), nil
+}
+
+func (r *Repository) UpsertAgentProfile(ctx context.Context, profile *userv1.AgentProfile, tenantID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[profile.GetUserId()]; !ok {
+		return repository.ErrForeignKey
+	}
+	// Postgres'teki ON CONFLICT DO UPDATE gibi: mevcut kaydın tenant_id'si güncellenmez
+	// (dolayısıyla verilen tenant da doğrulanmaz).
+	if existing, ok := r.agents[profile.GetUserId()]; ok {
+		tenantID = existing.tenantID
+	} else if _, ok := r.tenants[tenantID]; !ok {
+		return repository.ErrForeignKey
+	}
+	if profile.GetMaxConcurrentCalls() <= 0 {
+		return repository.ErrCheckViolation
+	}
+	if err := repository.RunTxHook(ctx, nil, profile.GetUserId()); err != nil {
+		return err
+	}
+	r.agents[profile.GetUserId()] = agentProfile{
+		profile:  proto.Clone(profile).(*userv1.AgentProfile),
+		tenantID: tenantID,
+	}
+	return nil
+}
+
+func (r *Repository) CountAgentsByStatus(ctx context.Context) ([]repository.AgentStatusCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type group struct{ tenantID, status string }
+	counts := make(map[group]int64)
+	for _, agent := range r.agents {
+		counts[group{agent.tenantID, agent.profile.GetStatus()}]++
+	}
+
+	result := make([]repository.AgentStatusCount, 0, len(counts))
+	for g, n := range counts {
+		result = append(result, repository.AgentStatusCount{TenantID: g.tenantID, Status: g.status, Count: n})
+	}
+	return result, nil
+}
+
+// withContacts, kullanıcının iletişim bilgileriyle birlikte bir kopyasını döner.
+// Kopya dönülür ki çağıranın yaptığı değişiklikler depolanan veriyi bozmasın.
+func (r *Repository) withContacts(user *userv1.User) *userv1.User {
+	clone := proto.Clone(user).(*userv1.User)
+	clone.Contacts = r.contactsOf(user.GetId())
+	return clone
+}
+
+// contactsOf, kullanıcının iletişim bilgilerini id sırasıyla (Postgres'teki ORDER BY id gibi) döner.
+func (r *Repository) contactsOf(userID string) []*userv1.Contact {
+	var contacts []*userv1.Contact
+	for _, c := range r.contacts {
+		if c.UserId == userID {
+			contacts = append(contacts, proto.Clone(c).(*userv1.Contact))
+		}
+	}
+	slices.SortFunc(contacts, func(a, b *userv1.Contact) int { return int(a.Id - b.Id) })
+	return contacts
+}
@@ -0,0 +1,20 @@
+// sentiric-user-service/internal/repository/memory/memory_test.go
+package memory
+
+import (
+	"testing"
+
+	"github.com/sentiric/sentiric-user-service/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Harness {
+		repo := New("tenant-a", "tenant-b")
+		return repotest.Harness{
+			Repo:        repo,
+			Tenant:      "tenant-a",
+			OtherTenant: "tenant-b",
+			AddContact:  repo.AddContact,
+		}
+	})
+}
@@ -0,0 +1,132 @@
+// sentiric-user-service/internal/repository/postgres/bench_test.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// Benchmark'lar gerçek bir PostgreSQL gerektirir:
+//
+//	USER_SERVICE_TEST_DSN=postgres://... go test -run '^$' -bench . ./internal/repository/postgres/
+const testDSNEnv = "USER_SERVICE_TEST_DSN"
+
+const (
+	benchTenant      = "bench_tenant"
+	benchPhone       = "+905550000001"
+	benchSipUsername = "bench_sip_user"
+)
+
+// benchBackends, karşılaştırılan repository implementasyonlarını hazırlar.
+func benchBackends(b *testing.B) map[string]repository.UserRepository {
+	b.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s tanımlı değil, PostgreSQL benchmark'ları atlandı", testDSNEnv)
+	}
+
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	db, err := database.Connect(dsn, 1, log)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		b.Fatal(err)
+	}
+	seedBenchData(b, ctx, db)
+
+	backends := map[string]repository.UserRepository{
+		"sql": NewPostgresRepository(db, log),
+	}
+	for _, mode := range []string{"cache_statement", "simple_protocol"} {
+		pool, err := database.ConnectPool(ctx, dsn, database.PoolOptions{
+			MaxConns:        5,
+			MinConns:        5,
+			MaxConnIdleTime: time.Minute,
+			MaxConnLifetime: time.Hour,
+			ExecMode:        mode,
+		}, 1, log)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(pool.Close)
+		backends["pgxpool_"+mode] = NewPoolRepository(pool, log)
+	}
+	return backends
+}
+
+// seedBenchData, benchmark'ların okuyacağı kayıtları idempotent olarak oluşturur.
+func seedBenchData(b *testing.B, ctx context.Context, db *sql.DB) {
+	b.Helper()
+	stmts := []string{
+		`INSERT INTO tenants (id, name) VALUES ('` + benchTenant + `', 'Benchmark') ON CONFLICT DO NOTHING`,
+		`WITH u AS (
+			INSERT INTO users (tenant_id, user_type, name)
+			SELECT '` + benchTenant + `', 'caller', 'Bench User'
+			WHERE NOT EXISTS (SELECT 1 FROM contacts WHERE contact_type = 'phone' AND contact_value = '` + benchPhone + `')
+			RETURNING id
+		)
+		INSERT INTO contacts (user_id, contact_type, contact_value, is_primary)
+		SELECT id, 'phone', '` + benchPhone + `', TRUE FROM u`,
+		`INSERT INTO sip_credentials (user_id, sip_username, ha1_hash)
+		SELECT user_id, '` + benchSipUsername + `', md5('bench') FROM contacts
+		WHERE contact_type = 'phone' AND contact_value = '` + benchPhone + `'
+		ON CONFLICT (sip_username) DO NOTHING`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			b.Fatalf("benchmark verisi oluşturulamadı: %v", err)
+		}
+	}
+}
+
+// BenchmarkFetchSipCredentials, her REGISTER'da çalışan SIP auth sorgusunu karşılaştırır.
+func BenchmarkFetchSipCredentials(b *testing.B) {
+	for name, repo := range benchBackends(b) {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, _, _, err := repo.FetchSipCredentials(ctx, benchSipUsername); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkFetchUserByContact, her gelen çağrıda çalışan arayan tanımlama sorgusunu karşılaştırır.
+func BenchmarkFetchUserByContact(b *testing.B) {
+	for name, repo := range benchBackends(b) {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := repo.FetchUserByContact(ctx, "phone", benchPhone); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// sentiric-user-service/internal/repository/postgres/conformance_test.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/repository/repotest"
+)
+
+// TestConformance, ortak uyumluluk testlerini her iki Postgres backend'ine karşı çalıştırır:
+//
+//	USER_SERVICE_TEST_DSN=postgres://... go test ./internal/repository/postgres/
+func TestConformance(t *testing.T) {
+	ctx := context.Background()
+	db, backends := testBackends(t)
+	for name, repo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) repotest.Harness {
+				// Her alt test kendi tenant'larıyla çalışır ve sonunda verisini siler.
+				tenants := []string{"conformance_" + uuid.NewString(), "conformance_" + uuid.NewString()}
+				for _, id := range tenants {
+					if _, err := db.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES ($1, 'Conformance')`, id); err != nil {
+						t.Fatal(err)
+					}
+				}
+				t.Cleanup(func() {
+					// contacts, sip_credentials ve agent_profiles kullanıcıyla birlikte silinir (ON DELETE CASCADE).
+					db.ExecContext(ctx, `DELETE FROM agent_profiles WHERE tenant_id = ANY($1)`, tenants)
+					db.ExecContext(ctx, `DELETE FROM users WHERE tenant_id = ANY($1)`, tenants)
+					db.ExecContext(ctx, `DELETE FROM tenants WHERE id = ANY($1)`, tenants)
+					db.ExecContext(ctx, `DELETE FROM outbox_events WHERE tenant_id = ANY($1)`, tenants)
+				})
+
+				return repotest.Harness{
+					Repo:        repo,
+					Tenant:      tenants[0],
+					OtherTenant: tenants[1],
+					AddContact: func(ctx context.Context, userID, contactType, contactValue string, primary bool) error {
+						_, err := db.ExecContext(ctx, queryInsertContact, userID, contactType, contactValue, primary)
+						return translateError(err)
+					},
+				}
+			})
+		})
+	}
+}
+
+// testBackends, migration'ları uygulanmış test veritabanına bağlanır ve her iki Postgres
+// backend'ini döner. DSN tanımlı değilse test atlanır.
+func testBackends(t *testing.T) (*sql.DB, map[string]repository.UserRepository) {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s tanımlı değil, PostgreSQL testleri atlandı", testDSNEnv)
+	}
+
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	db, err := database.Connect(dsn, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := database.ConnectPool(ctx, dsn, database.PoolOptions{
+		MaxConns:        5,
+		MinConns:        1,
+		MaxConnIdleTime: time.Minute,
+		MaxConnLifetime: time.Hour,
+		ExecMode:        "cache_statement",
+	}, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return db, map[string]repository.UserRepository{
+		"sql":     NewPostgresRepository(db, log),
+		"pgxpool": NewPoolRepository(pool, log),
+	}
+}
@@ -0,0 +1,41 @@
+// sentiric-user-service/internal/repository/postgres/contacts.go
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+)
+
+// contactJSON, userColumns sorgusundaki json_build_object alanlarıyla eşleşir.
+type contactJSON struct {
+	ID           int32  `json:"id"`
+	UserID       string `json:"user_id"`
+	ContactType  string `json:"contact_type"`
+	ContactValue string `json:"contact_value"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+// decodeContacts, kullanıcı satırındaki toplanmış iletişim bilgilerini çözer.
+// İletişim bilgisi olmayan kullanıcı için nil döner (ayrı sorgu davranışıyla aynı).
+func decodeContacts(raw []byte) ([]*userv1.Contact, error) {
+	var rows []contactJSON
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, fmt.Errorf("iletişim bilgileri çözümlenemedi: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	contacts := make([]*userv1.Contact, 0, len(rows))
+	for _, c := range rows {
+		contacts = append(contacts, &userv1.Contact{
+			Id:           c.ID,
+			UserId:       c.UserID,
+			ContactType:  c.ContactType,
+			ContactValue: c.ContactValue,
+			IsPrimary:    c.IsPrimary,
+		})
+	}
+	return contacts, nil
+}
@@ -0,0 +1,32 @@
+// sentiric-user-service/internal/repository/postgres/contacts_test.go
+package postgres
+
+import "testing"
+
+func TestDecodeContacts(t *testing.T) {
+	raw := []byte(`[
+		{"id": 7, "user_id": "u-1", "contact_type": "phone", "contact_value": "+905551112233", "is_primary": true},
+		{"id": 9, "user_id": "u-1", "contact_type": "email", "contact_value": "a@example.com", "is_primary": false}
+	]`)
+	contacts, err := decodeContacts(raw)
+	if err != nil {
+		t.Fatalf("decodeContacts: %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("got %d contacts, want 2", len(contacts))
+	}
+	c := contacts[0]
+	if c.Id != 7 || c.UserId != "u-1" || c.ContactType != "phone" || c.ContactValue != "+905551112233" || !c.IsPrimary {
+		t.Errorf("unexpected first contact: %+v", c)
+	}
+	if contacts[1].IsPrimary {
+		t.Errorf("second contact should not be primary")
+	}
+}
+
+func TestDecodeContactsEmpty(t *testing.T) {
+	contacts, err := decodeContacts([]byte(`[]`))
+	if err != nil || contacts != nil {
+		t.Fatalf("decodeContacts([]) = %v, %v; want nil, nil", contacts, err)
+	}
+}
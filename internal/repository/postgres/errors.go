@@ -0,0 +1,121 @@
+// sentiric-user-service/internal/repository/postgres/errors.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// PostgreSQL SQLSTATE kodları (https://www.postgresql.org/docs/current/errcodes-appendix.html).
+const (
+	sqlStateUniqueViolation      = "23505"
+	sqlStateForeignKeyViolation  = "23503"
+	sqlStateCheckViolation       = "23514"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateQueryCanceled        = "57014"
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateCrashShutdown        = "57P02"
+	sqlStateCannotConnectNow     = "57P03"
+	sqlStateReadOnlyTransaction  = "25006"
+
+	// sqlStateClassConnection: 08xxx "Connection Exception" sınıfı.
+	sqlStateClassConnection = "08"
+)
+
+// translateError, sürücü hatalarını repository hatalarına çevirir. Eşleştirme mesaj
+// metni yerine SQLSTATE koduna göre yapılır; yerelleştirilmiş sunucu mesajlarından etkilenmez.
+// Orijinal hata, loglanabilmesi için sarılarak korunur.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isTranslated(err) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if errors.Is(err, context.Canceled) {
+		return context.Canceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return context.DeadlineExceeded
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case sqlStateUniqueViolation:
+			return fmt.Errorf("%w: %s", repository.ErrConflict, pgErr.ConstraintName)
+		case sqlStateForeignKeyViolation:
+			return fmt.Errorf("%w: %s", repository.ErrForeignKey, pgErr.ConstraintName)
+		case sqlStateCheckViolation:
+			return fmt.Errorf("%w: %s", repository.ErrCheckViolation, pgErr.ConstraintName)
+		case sqlStateSerializationFailure, sqlStateDeadlockDetected:
+			return fmt.Errorf("%w: %w", repository.ErrRetryable, err)
+		case sqlStateAdminShutdown, sqlStateCrashShutdown, sqlStateCannotConnectNow:
+			return fmt.Errorf("%w: %w", repository.ErrUnavailable, err)
+		case sqlStateReadOnlyTransaction:
+			// Failover sonrası eski primary'ye (artık replika) yazma denemesi.
+			return fmt.Errorf("%w: %w", repository.ErrUnavailable, err)
+		case sqlStateQueryCanceled:
+			// statement_timeout veya iptal isteği; istemciye zaman aşımı olarak yansır.
+			return context.DeadlineExceeded
+		}
+		if len(pgErr.Code) == 5 && pgErr.Code[:2] == sqlStateClassConnection {
+			return fmt.Errorf("%w: %w", repository.ErrUnavailable, err)
+		}
+		return fmt.Errorf("%w: %v", repository.ErrDatabase, err)
+	}
+	if isConnectionError(err) {
+		// Orijinal hata zincirde tutulur; pgconn.SafeToRetry kararı buna bakar.
+		return fmt.Errorf("%w: %w", repository.ErrUnavailable, err)
+	}
+	return fmt.Errorf("%w: %v", repository.ErrDatabase, err)
+}
+
+// isConnectionError, bağlantı seviyesindeki hataları (reset, refused, EOF) tanır.
+func isConnectionError(err error) bool {
+	if pgconn.SafeToRetry(err) || errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isTranslated, hatanın zaten çevrilmiş olduğunu belirtir; iç içe çağrılarda
+// (ör. FetchUserByID -> FetchContactsForUser) çift sarmalamayı önler.
+func isTranslated(err error) bool {
+	for _, target := range []error{
+		repository.ErrNotFound, repository.ErrConflict, repository.ErrForeignKey,
+		repository.ErrCheckViolation, repository.ErrRetryable, repository.ErrUnavailable,
+		repository.ErrDatabase, context.Canceled, context.DeadlineExceeded,
+	} {
+		if err == target || errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnexpected, hatanın iş akışının doğal parçası olmadığını (loglanması gerektiğini) belirtir.
+func isUnexpected(err error) bool {
+	return errors.Is(err, repository.ErrDatabase) || errors.Is(err, repository.ErrRetryable) ||
+		errors.Is(err, repository.ErrUnavailable)
+}
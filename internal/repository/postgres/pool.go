@@ -0,0 +1,324 @@
+// sentiric-user-service/internal/repository/postgres/pool.go
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// PoolRepository, database/sql katmanı olmadan doğrudan pgxpool üzerinde çalışır.
+// Statement cache açıkken (varsayılan) sık kullanılan sorgular bağlantı başına bir kez
+// hazırlanır; bu, SIP auth gibi sıcak yollarda parse/plan maliyetini ortadan kaldırır.
+type PoolRepository struct {
+	pool  *pgxpool.Pool
+	log   zerolog.Logger
+	retry retryPolicy
+	reads *replicaSet[*pgxpool.Pool]
+}
+
+// NewPoolRepository, pgxpool tabanlı repository'yi başlatır.
+func NewPoolRepository(pool *pgxpool.Pool, log zerolog.Logger) repository.UserRepository {
+	return NewPoolRepositoryWithReplicas(context.Background(), pool, nil, ReplicaOptions{}, log)
+}
+
+// NewPoolRepositoryWithReplicas, salt okunur sorguları sağlıklı replikalara yönlendiren repository'yi başlatır.
+// Replika kontrolü ctx iptal edilene kadar arka planda çalışır. Yazmalar daima primary'ye gider.
+func NewPoolRepositoryWithReplicas(ctx context.Context, pool *pgxpool.Pool, replicas []*pgxpool.Pool, opts ReplicaOptions, log zerolog.Logger) repository.UserRepository {
+	reads := newReplicaSet(pool, replicas, opts, probePool, log)
+	go reads.run(ctx)
+	return &PoolRepository{pool: pool, log: log, retry: defaultRetryPolicy, reads: reads}
+}
+
+// fail, sürücü hatasını çevirir ve beklenmeyen hataları loglar.
+func (r *PoolRepository) fail(ctx context.Context, err error, msg string) error {
+	err = translateError(err)
+	if isUnexpected(err) {
+		l := logger.ContextLogger(ctx, r.log)
+		l.Error().
+			Str("event", logger.EventDBError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("driver", "pgx")).
+			Msg(msg)
+	}
+	return err
+}
+
+// read, yan etkisiz bir sorguyu uygun bir replikada (yoksa primary'de) çalıştırır ve geçici
+// hatalarda tekrar dener. Her deneme bağlantıyı yeniden seçer; erişilemeyen replika atlanır.
+func (r *PoolRepository) read(ctx context.Context, operation string, fn func(conn *pgxpool.Pool) error) error {
+	return withRetry(ctx, r.retry, r.log, operation, retryRead, func() error {
+		conn, rep := r.reads.reader(ctx)
+		err := fn(conn)
+		r.reads.observe(rep, err)
+		return err
+	})
+}
+
+// change, tek ifadelik bir yazmayı çalıştırır ve etkilenen satır sayısını döner. Context'te
+// TxHook varsa (audit) ifade ve hook aynı transaction'da çalışır; hook yalnızca bir satır
+// değiştiyse çağrılır. Hook yoksa ifade transaction açılmadan retryable kuralıyla tekrar denenir.
+func (r *PoolRepository) change(ctx context.Context, operation string, retryable retryClassifier, id, query string, args ...any) (int64, error) {
+	var rowsAffected int64
+	if !repository.HasTxHook(ctx) {
+		err := withRetry(ctx, r.retry, r.log, operation, retryable, func() error {
+			tag, err := r.pool.Exec(ctx, query, args...)
+			rowsAffected = tag.RowsAffected()
+			return err
+		})
+		return rowsAffected, err
+	}
+	err := r.inTx(ctx, operation, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if rowsAffected = tag.RowsAffected(); rowsAffected == 0 {
+			return nil
+		}
+		return repository.RunTxHook(ctx, pgxTx{tx}, id)
+	})
+	return rowsAffected, err
+}
+
+// inTx, fn'i bir transaction içinde çalıştırır; geçici hatalarda transaction baştan tekrarlanır.
+func (r *PoolRepository) inTx(ctx context.Context, operation string, fn func(tx pgx.Tx) error) error {
+	return withRetry(ctx, r.retry, r.log, operation, retryTx, func() error {
+		tx, err := r.pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return &commitError{err: err}
+		}
+		return nil
+	})
+}
+
+// --- User CRUD ---
+
+func (r *PoolRepository) FetchUserByID(ctx context.Context, userID string) (*userv1.User, error) {
+	var user *userv1.User
+	err := r.read(ctx, "FetchUserByID", func(pool *pgxpool.Pool) (err error) {
+		user, err = r.queryUser(ctx, pool, queryUserByID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Veritabanı sorgu hatası")
+	}
+	return user, nil
+}
+
+func (r *PoolRepository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
+	var user *userv1.User
+	err := r.read(ctx, "FetchUserByContact", func(pool *pgxpool.Pool) (err error) {
+		user, err = r.queryUser(ctx, pool, queryUserByContact, contactType, contactValue)
+		return err
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Veritabanı sorgu hatası")
+	}
+	return user, nil
+}
+
+func (r *PoolRepository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
+	// Kayıtlar RETURNING ile okunur; commit sonrası ayrı bir okuma (ve replika gecikmesi riski) yoktur.
+	var created userv1.User
+	err := r.inTx(ctx, "CreateUser", func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, queryInsertUser, user.Name, user.TenantId, user.UserType, user.PreferredLanguageCode).
+			Scan(&created.Id, &created.Name, &created.TenantId, &created.UserType, &created.PreferredLanguageCode)
+		if err != nil {
+			return err
+		}
+		var c userv1.Contact
+		err = tx.QueryRow(ctx, queryInsertContact, created.Id, initialContact.GetContactType(), normalizedContactValue, true).
+			Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary)
+		if err != nil {
+			return err
+		}
+		created.Contacts = []*userv1.Contact{&c}
+		return repository.RunTxHook(ctx, pgxTx{tx}, created.Id)
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Kullanıcı kaydedilemedi")
+	}
+	return &created, nil
+}
+
+// --- Sip Credentials ---
+
+func (r *PoolRepository) FetchSipCredentials(ctx context.Context, sipUsername string) (userID, tenantID, ha1Hash string, err error) {
+	err = r.read(ctx, "FetchSipCredentials", func(pool *pgxpool.Pool) error {
+		return pool.QueryRow(ctx, querySipCredentials, sipUsername).Scan(&userID, &tenantID, &ha1Hash)
+	})
+	if err != nil {
+		return "", "", "", r.fail(ctx, err, "SIP kimlik sorgu hatası")
+	}
+	return userID, tenantID, ha1Hash, nil
+}
+
+func (r *PoolRepository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) error {
+	_, err := r.change(ctx, "CreateSipCredential", retryWrite, sipUsername, queryInsertSipCredential, userID, sipUsername, ha1Hash)
+	if err != nil {
+		return r.fail(ctx, err, "SIP kimliği kaydedilemedi")
+	}
+	return nil
+}
+
+func (r *PoolRepository) DeleteSipCredential(ctx context.Context, sipUsername string) error {
+	rowsAffected, err := r.change(ctx, "DeleteSipCredential", retryWrite, sipUsername, queryDeleteSipCredential, sipUsername)
+	if err != nil {
+		return r.fail(ctx, err, "SIP kimliği silinemedi")
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// --- Helper / Internal ---
+
+func (r *PoolRepository) FetchContactsForUser(ctx context.Context, userID string) ([]*userv1.Contact, error) {
+	var contacts []*userv1.Contact
+	err := r.read(ctx, "FetchContactsForUser", func(pool *pgxpool.Pool) (err error) {
+		contacts, err = r.queryContacts(ctx, pool, userID)
+		return err
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "İletişim bilgileri sorgulanamadı")
+	}
+	return contacts, nil
+}
+
+// FetchContactsForUsers, birden çok kullanıcının iletişim bilgilerini tek sorguda okur.
+// İletişim bilgisi olmayan kullanıcılar map'te yer almaz.
+func (r *PoolRepository) FetchContactsForUsers(ctx context.Context, userIDs []string) (map[string][]*userv1.Contact, error) {
+	byUser := make(map[string][]*userv1.Contact, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+	err := r.read(ctx, "FetchContactsForUsers", func(pool *pgxpool.Pool) error {
+		clear(byUser)
+		rows, err := pool.Query(ctx, queryContactsForUsers, userIDs)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c userv1.Contact
+			if err := rows.Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary); err != nil {
+				return err
+			}
+			byUser[c.UserId] = append(byUser[c.UserId], &c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "İletişim bilgileri toplu sorgulanamadı")
+	}
+	return byUser, nil
+}
+
+// queryUser, kullanıcıyı iletişim bilgileriyle birlikte tek sorguda okur. Ham sürücü
+// hatası döner; tekrar deneme ve çeviri çağıran tarafa aittir.
+func (r *PoolRepository) queryUser(ctx context.Context, pool *pgxpool.Pool, query string, args ...any) (*userv1.User, error) {
+	var user userv1.User
+	var contactsJSON []byte
+	err := pool.QueryRow(ctx, query, args...).
+		Scan(&user.Id, &user.Name, &user.TenantId, &user.UserType, &user.PreferredLanguageCode, &contactsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if user.Contacts, err = decodeContacts(contactsJSON); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *PoolRepository) queryContacts(ctx context.Context, pool *pgxpool.Pool, userID string) ([]*userv1.Contact, error) {
+	rows, err := pool.Query(ctx, queryContactsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*userv1.Contact
+	for rows.Next() {
+		var c userv1.Contact
+		if err := rows.Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, &c)
+	}
+	return contacts, rows.Err()
+}
+
+// --- Agent Profiles ---
+
+func (r *PoolRepository) GetAgentProfile(ctx context.Context, userID string) (*userv1.AgentProfile, error) {
+	var profile userv1.AgentProfile
+	var displayName *string
+
+	err := r.read(ctx, "GetAgentProfile", func(pool *pgxpool.Pool) error {
+		return pool.QueryRow(ctx, queryAgentProfile, userID).
+			Scan(&profile.UserId, &displayName, &profile.MaxConcurrentCalls, &profile.Status)
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Ajan profili sorgulanamadı")
+	}
+	if displayName != nil {
+		profile.DisplayName = *displayName
+	}
+	return &profile, nil
+}
+
+func (r *PoolRepository) UpsertAgentProfile(ctx context.Context, profile *userv1.AgentProfile, tenantID string) error {
+	// Upsert tekrar uygulandığında sonuç değişmez; okuma gibi tekrar denenir.
+	_, err := r.change(ctx, "UpsertAgentProfile", retryRead, profile.UserId, queryUpsertAgentProfile,
+		profile.UserId,
+		tenantID,
+		profile.DisplayName,
+		profile.MaxConcurrentCalls,
+		profile.Status,
+	)
+	if err != nil {
+		return r.fail(ctx, err, "Ajan profili kaydedilemedi")
+	}
+	return nil
+}
+
+func (r *PoolRepository) CountAgentsByStatus(ctx context.Context) ([]repository.AgentStatusCount, error) {
+	var counts []repository.AgentStatusCount
+	err := r.read(ctx, "CountAgentsByStatus", func(pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, queryCountAgentsByStatus)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		counts = counts[:0]
+		for rows.Next() {
+			var c repository.AgentStatusCount
+			if err := rows.Scan(&c.TenantID, &c.Status, &c.Count); err != nil {
+				return err
+			}
+			counts = append(counts, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Ajan durum sayıları sorgulanamadı")
+	}
+	return counts, nil
+}
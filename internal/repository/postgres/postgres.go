@@ -4,156 +4,182 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"strings"
 
 	"github.com/rs/zerolog"
 	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
 	"github.com/sentiric/sentiric-user-service/internal/repository"
 )
 
 // PostgresRepository, tüm veritabanı işlemlerini yürüten yapıdır.
 type PostgresRepository struct {
-	db  *sql.DB
-	log zerolog.Logger
+	db    *sql.DB
+	log   zerolog.Logger
+	retry retryPolicy
+	reads *replicaSet[*sql.DB]
 }
 
 // NewPostgresRepository, Repository'yi başlatır.
 func NewPostgresRepository(db *sql.DB, log zerolog.Logger) repository.UserRepository {
-	return &PostgresRepository{db: db, log: log}
+	return NewPostgresRepositoryWithReplicas(context.Background(), db, nil, ReplicaOptions{}, log)
 }
 
-// --- User CRUD ---
-
-func (r *PostgresRepository) FetchUserByID(ctx context.Context, userID string) (*userv1.User, error) {
-	query := "SELECT id, name, tenant_id, user_type, preferred_language_code FROM users WHERE id = $1"
-	row := r.db.QueryRowContext(ctx, query, userID)
-	var user userv1.User
-	var name, langCode sql.NullString
-	if err := row.Scan(&user.Id, &name, &user.TenantId, &user.UserType, &langCode); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, repository.ErrNotFound
-		}
-		r.log.Error().Err(err).Str("user_id", userID).Msg("Veritabanı sorgu hatası")
-		return nil, repository.ErrDatabase
-	}
-	if name.Valid {
-		user.Name = &name.String
-	}
-	if langCode.Valid {
-		user.PreferredLanguageCode = &langCode.String
-	}
+// NewPostgresRepositoryWithReplicas, salt okunur sorguları sağlıklı replikalara yönlendiren repository'yi başlatır.
+// Replika kontrolü ctx iptal edilene kadar arka planda çalışır. Yazmalar daima primary'ye gider.
+func NewPostgresRepositoryWithReplicas(ctx context.Context, db *sql.DB, replicas []*sql.DB, opts ReplicaOptions, log zerolog.Logger) repository.UserRepository {
+	reads := newReplicaSet(db, replicas, opts, probeSQL, log)
+	go reads.run(ctx)
+	return &PostgresRepository{db: db, log: log, retry: defaultRetryPolicy, reads: reads}
+}
 
-	contacts, err := r.FetchContactsForUser(ctx, user.Id)
-	if err != nil {
-		return nil, err
+// fail, sürücü hatasını çevirir ve beklenmeyen hataları loglar.
+func (r *PostgresRepository) fail(ctx context.Context, err error, msg string) error {
+	err = translateError(err)
+	if isUnexpected(err) {
+		l := logger.ContextLogger(ctx, r.log)
+		l.Error().
+			Str("event", logger.EventDBError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("driver", "database/sql")).
+			Msg(msg)
 	}
-	user.Contacts = contacts
+	return err
+}
 
-	return &user, nil
+// read, yan etkisiz bir sorguyu uygun bir replikada (yoksa primary'de) çalıştırır ve geçici
+// hatalarda tekrar dener. Her deneme bağlantıyı yeniden seçer; erişilemeyen replika atlanır.
+func (r *PostgresRepository) read(ctx context.Context, operation string, fn func(conn *sql.DB) error) error {
+	return withRetry(ctx, r.retry, r.log, operation, retryRead, func() error {
+		conn, rep := r.reads.reader(ctx)
+		err := fn(conn)
+		r.reads.observe(rep, err)
+		return err
+	})
 }
 
-func (r *PostgresRepository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
-	query := `
-		SELECT u.id, u.name, u.tenant_id, u.user_type, u.preferred_language_code
-		FROM users u
-		JOIN contacts c ON u.id = c.user_id
-		WHERE c.contact_type = $1 AND c.contact_value = $2
-	`
-	row := r.db.QueryRowContext(ctx, query, contactType, contactValue)
-	var user userv1.User
-	var name, langCode sql.NullString
-	err := row.Scan(&user.Id, &name, &user.TenantId, &user.UserType, &langCode)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, repository.ErrNotFound
-		}
-		r.log.Error().Err(err).Msg("Veritabanı sorgu hatası")
-		return nil, repository.ErrDatabase
-	}
-	if name.Valid {
-		user.Name = &name.String
-	}
-	if langCode.Valid {
-		user.PreferredLanguageCode = &langCode.String
+// change, tek ifadelik bir yazmayı çalıştırır ve etkilenen satır sayısını döner. Context'te
+// TxHook varsa (audit) ifade ve hook aynı transaction'da çalışır; hook yalnızca bir satır
+// değiştiyse çağrılır. Hook yoksa ifade transaction açılmadan retryable kuralıyla tekrar denenir.
+func (r *PostgresRepository) change(ctx context.Context, operation string, retryable retryClassifier, id, query string, args ...any) (int64, error) {
+	var rowsAffected int64
+	if !repository.HasTxHook(ctx) {
+		err := withRetry(ctx, r.retry, r.log, operation, retryable, func() error {
+			result, err := r.db.ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			rowsAffected, _ = result.RowsAffected()
+			return nil
+		})
+		return rowsAffected, err
 	}
+	err := r.inTx(ctx, operation, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ = result.RowsAffected(); rowsAffected == 0 {
+			return nil
+		}
+		return repository.RunTxHook(ctx, sqlTx{tx}, id)
+	})
+	return rowsAffected, err
+}
 
-	contacts, err := r.FetchContactsForUser(ctx, user.Id)
-	if err != nil {
-		return nil, err
-	}
-	user.Contacts = contacts
+// inTx, fn'i bir transaction içinde çalıştırır; geçici hatalarda transaction baştan tekrarlanır.
+func (r *PostgresRepository) inTx(ctx context.Context, operation string, fn func(tx *sql.Tx) error) error {
+	return withRetry(ctx, r.retry, r.log, operation, retryTx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
 
-	return &user, nil
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return &commitError{err: err}
+		}
+		return nil
+	})
 }
 
-func (r *PostgresRepository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, repository.ErrDatabase
-	}
-	defer tx.Rollback()
+// --- User CRUD ---
 
-	userQuery := `INSERT INTO users (name, tenant_id, user_type, preferred_language_code) VALUES ($1, $2, $3, $4) RETURNING id`
-	var newUserID string
-	err = tx.QueryRowContext(ctx, userQuery, user.Name, user.TenantId, user.UserType, user.PreferredLanguageCode).Scan(&newUserID)
+func (r *PostgresRepository) FetchUserByID(ctx context.Context, userID string) (*userv1.User, error) {
+	var user *userv1.User
+	err := r.read(ctx, "FetchUserByID", func(db *sql.DB) (err error) {
+		user, err = r.queryUser(ctx, db, queryUserByID, userID)
+		return err
+	})
 	if err != nil {
-		return nil, repository.ErrDatabase
+		return nil, r.fail(ctx, err, "Veritabanı sorgu hatası")
 	}
+	return user, nil
+}
 
-	contactQuery := `INSERT INTO contacts (user_id, contact_type, contact_value, is_primary) VALUES ($1, $2, $3, $4)`
-	_, err = tx.ExecContext(ctx, contactQuery, newUserID, initialContact.GetContactType(), normalizedContactValue, true)
+func (r *PostgresRepository) FetchUserByContact(ctx context.Context, contactType, contactValue string) (*userv1.User, error) {
+	var user *userv1.User
+	err := r.read(ctx, "FetchUserByContact", func(db *sql.DB) (err error) {
+		user, err = r.queryUser(ctx, db, queryUserByContact, contactType, contactValue)
+		return err
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return nil, repository.ErrConflict
-		}
-		return nil, repository.ErrDatabase
+		return nil, r.fail(ctx, err, "Veritabanı sorgu hatası")
 	}
+	return user, nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return nil, repository.ErrDatabase
+func (r *PostgresRepository) CreateUser(ctx context.Context, user *userv1.User, initialContact *userv1.CreateUserRequest_InitialContact, normalizedContactValue string) (*userv1.User, error) {
+	// Kayıtlar RETURNING ile okunur; commit sonrası ayrı bir okuma (ve replika gecikmesi riski) yoktur.
+	var created *userv1.User
+	err := r.inTx(ctx, "CreateUser", func(tx *sql.Tx) error {
+		var err error
+		created, err = scanUser(tx.QueryRowContext(ctx, queryInsertUser, user.Name, user.TenantId, user.UserType, user.PreferredLanguageCode))
+		if err != nil {
+			return err
+		}
+		var c userv1.Contact
+		err = tx.QueryRowContext(ctx, queryInsertContact, created.Id, initialContact.GetContactType(), normalizedContactValue, true).
+			Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary)
+		if err != nil {
+			return err
+		}
+		created.Contacts = []*userv1.Contact{&c}
+		return repository.RunTxHook(ctx, sqlTx{tx}, created.Id)
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "Kullanıcı kaydedilemedi")
 	}
-
-	return r.FetchUserByID(ctx, newUserID)
+	return created, nil
 }
 
 // --- Sip Credentials ---
 
 func (r *PostgresRepository) FetchSipCredentials(ctx context.Context, sipUsername string) (userID, tenantID, ha1Hash string, err error) {
-	query := `SELECT sc.user_id, u.tenant_id, sc.ha1_hash FROM sip_credentials sc JOIN users u ON sc.user_id = u.id WHERE sc.sip_username = $1`
-	row := r.db.QueryRowContext(ctx, query, sipUsername)
-
-	var resUserID, resTenantID, resHA1Hash string
-	err = row.Scan(&resUserID, &resTenantID, &resHA1Hash)
+	err = r.read(ctx, "FetchSipCredentials", func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, querySipCredentials, sipUsername).Scan(&userID, &tenantID, &ha1Hash)
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", "", "", repository.ErrNotFound
-		}
-		r.log.Error().Err(err).Msg("SIP kimlik sorgu hatası")
-		return "", "", "", repository.ErrDatabase
+		return "", "", "", r.fail(ctx, err, "SIP kimlik sorgu hatası")
 	}
-	return resUserID, resTenantID, resHA1Hash, nil
+	return userID, tenantID, ha1Hash, nil
 }
 
 func (r *PostgresRepository) CreateSipCredential(ctx context.Context, userID, sipUsername, ha1Hash string) error {
-	query := `INSERT INTO sip_credentials (user_id, sip_username, ha1_hash) VALUES ($1, $2, $3)`
-	_, err := r.db.ExecContext(ctx, query, userID, sipUsername, ha1Hash)
+	_, err := r.change(ctx, "CreateSipCredential", retryWrite, sipUsername, queryInsertSipCredential, userID, sipUsername, ha1Hash)
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return repository.ErrConflict
-		}
-		return repository.ErrDatabase
+		return r.fail(ctx, err, "SIP kimliği kaydedilemedi")
 	}
 	return nil
 }
 
 func (r *PostgresRepository) DeleteSipCredential(ctx context.Context, sipUsername string) error {
-	query := `DELETE FROM sip_credentials WHERE sip_username = $1`
-	result, err := r.db.ExecContext(ctx, query, sipUsername)
+	rowsAffected, err := r.change(ctx, "DeleteSipCredential", retryWrite, sipUsername, queryDeleteSipCredential, sipUsername)
 	if err != nil {
-		return repository.ErrDatabase
+		return r.fail(ctx, err, "SIP kimliği silinemedi")
 	}
-
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
 		return repository.ErrNotFound
 	}
@@ -163,10 +189,82 @@ func (r *PostgresRepository) DeleteSipCredential(ctx context.Context, sipUsernam
 // --- Helper / Internal ---
 
 func (r *PostgresRepository) FetchContactsForUser(ctx context.Context, userID string) ([]*userv1.Contact, error) {
-	query := `SELECT id, user_id, contact_type, contact_value, is_primary FROM contacts WHERE user_id = $1`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	var contacts []*userv1.Contact
+	err := r.read(ctx, "FetchContactsForUser", func(db *sql.DB) (err error) {
+		contacts, err = r.queryContacts(ctx, db, userID)
+		return err
+	})
 	if err != nil {
-		return nil, repository.ErrDatabase
+		return nil, r.fail(ctx, err, "İletişim bilgileri sorgulanamadı")
+	}
+	return contacts, nil
+}
+
+// FetchContactsForUsers, birden çok kullanıcının iletişim bilgilerini tek sorguda okur.
+// İletişim bilgisi olmayan kullanıcılar map'te yer almaz.
+func (r *PostgresRepository) FetchContactsForUsers(ctx context.Context, userIDs []string) (map[string][]*userv1.Contact, error) {
+	byUser := make(map[string][]*userv1.Contact, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+	err := r.read(ctx, "FetchContactsForUsers", func(db *sql.DB) error {
+		clear(byUser)
+		rows, err := db.QueryContext(ctx, queryContactsForUsers, userIDs)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c userv1.Contact
+			if err := rows.Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary); err != nil {
+				return err
+			}
+			byUser[c.UserId] = append(byUser[c.UserId], &c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err, "İletişim bilgileri toplu sorgulanamadı")
+	}
+	return byUser, nil
+}
+
+// queryUser, kullanıcıyı iletişim bilgileriyle birlikte tek sorguda okur. Ham sürücü
+// hatası döner; tekrar deneme ve çeviri çağıran tarafa aittir.
+func (r *PostgresRepository) queryUser(ctx context.Context, db *sql.DB, query string, args ...any) (*userv1.User, error) {
+	var contactsJSON []byte
+	user, err := scanUser(db.QueryRowContext(ctx, query, args...), &contactsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if user.Contacts, err = decodeContacts(contactsJSON); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// scanUser, kullanıcı kolonlarını (ve varsa ek kolonları) okur.
+func scanUser(row *sql.Row, extra ...any) (*userv1.User, error) {
+	var user userv1.User
+	var name, langCode sql.NullString
+	dest := append([]any{&user.Id, &name, &user.TenantId, &user.UserType, &langCode}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if name.Valid {
+		user.Name = &name.String
+	}
+	if langCode.Valid {
+		user.PreferredLanguageCode = &langCode.String
+	}
+	return &user, nil
+}
+
+func (r *PostgresRepository) queryContacts(ctx context.Context, db *sql.DB, userID string) ([]*userv1.Contact, error) {
+	rows, err := db.QueryContext(ctx, queryContactsForUser, userID)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -174,9 +272,9 @@ func (r *PostgresRepository) FetchContactsForUser(ctx context.Context, userID st
 	for rows.Next() {
 		var c userv1.Contact
 		if err := rows.Scan(&c.Id, &c.UserId, &c.ContactType, &c.ContactValue, &c.IsPrimary); err != nil {
-			return nil, repository.ErrDatabase
+			return nil, err
 		}
 		contacts = append(contacts, &c)
 	}
-	return contacts, nil
+	return contacts, rows.Err()
 }
@@ -0,0 +1,90 @@
+// sentiric-user-service/internal/repository/postgres/queries.go
+package postgres
+
+// SQL metinleri database/sql (PostgresRepository) ve pgxpool (PoolRepository)
+// implementasyonları arasında paylaşılır; iki backend'in davranışı böylece aynı kalır.
+const (
+	// userColumns, kullanıcıyı iletişim bilgileriyle birlikte tek satırda döner. İletişim
+	// bilgileri LATERAL alt sorguda JSON dizisine toplanır; böylece kullanıcı okuması
+	// tek round trip'tir (ayrı bir contacts sorgusu gerekmez).
+	userColumns = `
+		SELECT u.id, u.name, u.tenant_id, u.user_type, u.preferred_language_code,
+			COALESCE(c.contacts, '[]'::json)
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'id', ct.id,
+				'user_id', ct.user_id,
+				'contact_type', ct.contact_type,
+				'contact_value', ct.contact_value,
+				'is_primary', ct.is_primary
+			) ORDER BY ct.id) AS contacts
+			FROM contacts ct
+			WHERE ct.user_id = u.id
+		) c ON true`
+
+	queryUserByID = userColumns + `
+		WHERE u.id = $1`
+
+	queryUserByContact = userColumns + `
+		WHERE u.id = (SELECT user_id FROM contacts WHERE contact_type = $1 AND contact_value = $2)`
+
+	queryInsertUser = `
+		INSERT INTO users (name, tenant_id, user_type, preferred_language_code) VALUES ($1, $2, $3, $4)
+		RETURNING id, name, tenant_id, user_type, preferred_language_code`
+
+	queryInsertContact = `
+		INSERT INTO contacts (user_id, contact_type, contact_value, is_primary) VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, contact_type, contact_value, is_primary`
+
+	queryContactsForUser = `SELECT id, user_id, contact_type, contact_value, is_primary FROM contacts WHERE user_id = $1 ORDER BY id`
+
+	queryContactsForUsers = `
+		SELECT id, user_id, contact_type, contact_value, is_primary
+		FROM contacts
+		WHERE user_id = ANY($1::uuid[])
+		ORDER BY user_id, id`
+
+	querySipCredentials = `SELECT sc.user_id, u.tenant_id, sc.ha1_hash FROM sip_credentials sc JOIN users u ON sc.user_id = u.id WHERE sc.sip_username = $1`
+
+	queryInsertSipCredential = `INSERT INTO sip_credentials (user_id, sip_username, ha1_hash) VALUES ($1, $2, $3)`
+
+	queryDeleteSipCredential = `DELETE FROM sip_credentials WHERE sip_username = $1`
+
+	queryAgentProfile = `
+		SELECT user_id, display_name, max_concurrent_calls, status
+		FROM agent_profiles
+		WHERE user_id = $1`
+
+	queryUpsertAgentProfile = `
+		INSERT INTO agent_profiles (user_id, tenant_id, display_name, max_concurrent_calls, status, last_status_change)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			display_name = EXCLUDED.display_name,
+			max_concurrent_calls = EXCLUDED.max_concurrent_calls,
+			status = EXCLUDED.status,
+			last_status_change = NOW()`
+
+	queryCountAgentsByStatus = `
+		SELECT tenant_id, status, COUNT(*)
+		FROM agent_profiles
+		GROUP BY tenant_id, status`
+
+	// queryReplicationLag, replikanın primary'nin kaç saniye gerisinde olduğunu ve WAL
+	// receiver'ının çalışıp çalışmadığını döner. Alınan tüm WAL uygulanmışsa gecikme 0 sayılır;
+	// aksi halde boşta duran bir primary'de son işlem zamanı eskidiği için replika yanlışlıkla
+	// geride görünürdü. Bu kısayol yalnızca receiver primary'ye bağlıyken doğrudur: bağlantı
+	// koptuğunda alınan ve uygulanan LSN eşit kalır ve replika sonsuza dek 0 gecikme bildirirdi.
+	// pg_read_all_stats yetkisi olmayan roller status'u NULL görür; bu durumda receiver'ın
+	// var olması yeterli sayılır. Primary'de 0 ve true döner.
+	queryReplicationLag = `
+		SELECT
+			CASE
+				WHEN NOT pg_is_in_recovery() THEN 0
+				WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+				ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+			END::float8,
+			NOT pg_is_in_recovery() OR EXISTS (
+				SELECT 1 FROM pg_stat_wal_receiver WHERE status IS NULL OR status = 'streaming'
+			)`
+)
@@ -0,0 +1,171 @@
+// sentiric-user-service/internal/repository/postgres/replicas.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// ReplicaOptions, okuma replikalarının sağlık ve gecikme kontrol ayarlarıdır.
+type ReplicaOptions struct {
+	// MaxLag: Bu süreden fazla geride kalan replika okuma trafiği almaz.
+	MaxLag time.Duration
+	// CheckInterval: Replikaların sağlık/gecikme sorgulama aralığı.
+	CheckInterval time.Duration
+	// CheckTimeout: Tek bir sağlık sorgusunun zaman aşımı.
+	CheckTimeout time.Duration
+}
+
+// replica, bir okuma replikasının bağlantısı ve son bilinen durumudur.
+type replica[T any] struct {
+	name    string
+	conn    T
+	healthy atomic.Bool
+}
+
+// replicaSet, okuma sorgularını sağlıklı ve yeterince güncel replikalara round-robin dağıtır.
+// Hiç uygun replika yoksa (veya hiç tanımlanmamışsa) primary kullanılır.
+type replicaSet[T any] struct {
+	primary  T
+	replicas []*replica[T]
+	opts     ReplicaOptions
+	probe    func(ctx context.Context, conn T) (time.Duration, error)
+	next     atomic.Uint64
+	log      zerolog.Logger
+}
+
+func newReplicaSet[T any](primary T, conns []T, opts ReplicaOptions, probe func(context.Context, T) (time.Duration, error), log zerolog.Logger) *replicaSet[T] {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 2 * time.Second
+	}
+	if opts.CheckTimeout <= 0 {
+		opts.CheckTimeout = time.Second
+	}
+	s := &replicaSet[T]{primary: primary, opts: opts, probe: probe, log: log}
+	for i, conn := range conns {
+		// Replikalar ilk başarılı kontrole kadar sağlıksız sayılır.
+		s.replicas = append(s.replicas, &replica[T]{name: "replica-" + strconv.Itoa(i), conn: conn})
+	}
+	return s
+}
+
+// reader, bir okuma sorgusu için bağlantı seçer. Dönen replica nil ise primary seçilmiştir.
+func (s *replicaSet[T]) reader(ctx context.Context) (T, *replica[T]) {
+	if len(s.replicas) == 0 || repository.PrimaryRequired(ctx) {
+		return s.primary, nil
+	}
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		rep := s.replicas[(int(start)+i)%len(s.replicas)]
+		if rep.healthy.Load() {
+			return rep.conn, rep
+		}
+	}
+	return s.primary, nil
+}
+
+// observe, replikadan dönen bağlantı hatalarında replikayı bir sonraki kontrole kadar devre dışı bırakır;
+// böylece aynı isteğin tekrar denemesi başka bir replikaya veya primary'ye gider.
+func (s *replicaSet[T]) observe(rep *replica[T], err error) {
+	if rep == nil || err == nil || !errors.Is(translateError(err), repository.ErrUnavailable) {
+		return
+	}
+	if rep.healthy.CompareAndSwap(true, false) {
+		s.log.Warn().
+			Str("event", logger.EventReplicaUnavailable).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("replica", rep.name)).
+			Msg("Replika erişilemez, okuma trafiği primary'ye yönlendirildi")
+		metrics.SetReplicaHealthy(rep.name, false)
+	}
+}
+
+// run, replikaları CheckInterval aralıklarla kontrol eder; ctx iptal edilince durur.
+func (s *replicaSet[T]) run(ctx context.Context) {
+	if len(s.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		s.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *replicaSet[T]) checkAll(ctx context.Context) {
+	for _, rep := range s.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, s.opts.CheckTimeout)
+		lag, err := s.probe(checkCtx, rep.conn)
+		cancel()
+
+		healthy := err == nil && lag <= s.opts.MaxLag
+		if err == nil {
+			metrics.SetReplicaLag(rep.name, lag)
+		}
+		metrics.SetReplicaHealthy(rep.name, healthy)
+
+		if rep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		event := s.log.Info()
+		if !healthy {
+			event = s.log.Warn()
+		}
+		event.
+			Str("event", logger.EventReplicaChanged).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("replica", rep.name).
+				Bool("healthy", healthy).
+				Dur("lag_ms", lag).
+				Dur("max_lag_ms", s.opts.MaxLag)).
+			Msg("Replika durumu değişti")
+	}
+}
+
+// errReplicaNotStreaming: WAL receiver primary'den akış almıyor; replikanın gecikmesi bilinemez.
+var errReplicaNotStreaming = errors.New("replika WAL receiver'ı primary'den akış almıyor")
+
+// probeSQL ve probePool, replikasyon gecikmesini ölçer.
+func probeSQL(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds float64
+	var streaming bool
+	if err := db.QueryRowContext(ctx, queryReplicationLag).Scan(&seconds, &streaming); err != nil {
+		return 0, err
+	}
+	return replicationLag(seconds, streaming)
+}
+
+func probePool(ctx context.Context, pool *pgxpool.Pool) (time.Duration, error) {
+	var seconds float64
+	var streaming bool
+	if err := pool.QueryRow(ctx, queryReplicationLag).Scan(&seconds, &streaming); err != nil {
+		return 0, err
+	}
+	return replicationLag(seconds, streaming)
+}
+
+// replicationLag, sorgu sonucunu yorumlar: akış almayan bir replika, bildirdiği gecikme ne
+// olursa olsun sağlıksızdır.
+func replicationLag(seconds float64, streaming bool) (time.Duration, error) {
+	lag := time.Duration(seconds * float64(time.Second))
+	if !streaming {
+		return lag, errReplicaNotStreaming
+	}
+	return lag, nil
+}
@@ -0,0 +1,107 @@
+// sentiric-user-service/internal/repository/postgres/replicas_test.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// fakeProbe, bağlantı adına göre sabit gecikme veya hata döner.
+func fakeProbe(lags map[string]time.Duration, down map[string]bool) func(context.Context, string) (time.Duration, error) {
+	return func(_ context.Context, conn string) (time.Duration, error) {
+		if down[conn] {
+			return 0, errors.New("connection refused")
+		}
+		return lags[conn], nil
+	}
+}
+
+func TestReplicaSetRoutesToFreshReplicas(t *testing.T) {
+	lags := map[string]time.Duration{"r1": 100 * time.Millisecond, "r2": 10 * time.Second}
+	s := newReplicaSet("primary", []string{"r1", "r2"}, ReplicaOptions{MaxLag: time.Second}, fakeProbe(lags, nil), zerolog.Nop())
+	s.checkAll(context.Background())
+
+	for i := 0; i < 4; i++ {
+		if conn, _ := s.reader(context.Background()); conn != "r1" {
+			t.Fatalf("reader() = %q, want r1 (r2 is lagging)", conn)
+		}
+	}
+}
+
+func TestReplicaSetFallsBackToPrimary(t *testing.T) {
+	lags := map[string]time.Duration{"r1": 10 * time.Second}
+	s := newReplicaSet("primary", []string{"r1"}, ReplicaOptions{MaxLag: time.Second}, fakeProbe(lags, nil), zerolog.Nop())
+
+	if conn, _ := s.reader(context.Background()); conn != "primary" {
+		t.Fatalf("before first check: reader() = %q, want primary", conn)
+	}
+	s.checkAll(context.Background())
+	if conn, _ := s.reader(context.Background()); conn != "primary" {
+		t.Fatalf("lagging replica: reader() = %q, want primary", conn)
+	}
+}
+
+func TestReplicaSetHonoursPrimaryRequired(t *testing.T) {
+	s := newReplicaSet("primary", []string{"r1"}, ReplicaOptions{MaxLag: time.Second}, fakeProbe(nil, nil), zerolog.Nop())
+	s.checkAll(context.Background())
+
+	if conn, _ := s.reader(context.Background()); conn != "r1" {
+		t.Fatalf("reader() = %q, want r1", conn)
+	}
+	if conn, _ := s.reader(repository.WithPrimary(context.Background())); conn != "primary" {
+		t.Fatalf("WithPrimary: reader() = %q, want primary", conn)
+	}
+}
+
+func TestReplicaSetEjectsUnreachableReplica(t *testing.T) {
+	down := map[string]bool{}
+	s := newReplicaSet("primary", []string{"r1"}, ReplicaOptions{MaxLag: time.Second}, fakeProbe(nil, down), zerolog.Nop())
+	s.checkAll(context.Background())
+
+	conn, rep := s.reader(context.Background())
+	if conn != "r1" {
+		t.Fatalf("reader() = %q, want r1", conn)
+	}
+	s.observe(rep, fmt.Errorf("read: %w", syscall.ECONNRESET))
+	if conn, _ := s.reader(context.Background()); conn != "primary" {
+		t.Fatalf("after connection reset: reader() = %q, want primary", conn)
+	}
+
+	// Bir sonraki başarılı kontrolde replika geri döner.
+	s.checkAll(context.Background())
+	if conn, _ := s.reader(context.Background()); conn != "r1" {
+		t.Fatalf("after recovery: reader() = %q, want r1", conn)
+	}
+
+	down["r1"] = true
+	s.checkAll(context.Background())
+	if conn, _ := s.reader(context.Background()); conn != "primary" {
+		t.Fatalf("replica down: reader() = %q, want primary", conn)
+	}
+}
+
+// TestReplicaSetEjectsDisconnectedReceiver: WAL receiver'ı kopmuş bir replikada alınan ve
+// uygulanan LSN eşit kaldığından sorgu 0 gecikme döner; replika yine de sağlıksız sayılmalıdır.
+func TestReplicaSetEjectsDisconnectedReceiver(t *testing.T) {
+	streaming := true
+	probe := func(context.Context, string) (time.Duration, error) { return replicationLag(0, streaming) }
+	s := newReplicaSet("primary", []string{"r1"}, ReplicaOptions{MaxLag: time.Second}, probe, zerolog.Nop())
+
+	s.checkAll(context.Background())
+	if conn, _ := s.reader(context.Background()); conn != "r1" {
+		t.Fatalf("streaming replica: reader() = %q, want r1", conn)
+	}
+
+	streaming = false
+	s.checkAll(context.Background())
+	if conn, _ := s.reader(context.Background()); conn != "primary" {
+		t.Fatalf("disconnected receiver: reader() = %q, want primary", conn)
+	}
+}
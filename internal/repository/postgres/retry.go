@@ -0,0 +1,103 @@
+// sentiric-user-service/internal/repository/postgres/retry.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// retryPolicy, geçici hatalarda uygulanacak jitter'lı üstel geri çekilme ayarıdır.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// defaultRetryPolicy: Bir failover genellikle birkaç yüz ms sürer; üç deneme ve
+// ~1 sn üst sınır, SIP signaling tarafındaki timeout'ların içinde kalır.
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts: 3,
+	baseDelay:   50 * time.Millisecond,
+	maxDelay:    time.Second,
+}
+
+// retryClassifier, çevrilmiş bir hatanın tekrar denenip denenemeyeceğine karar verir.
+type retryClassifier func(err error) bool
+
+// retryRead: Okuma sorguları yan etkisizdir; her geçici hata tekrar denenebilir.
+func retryRead(err error) bool {
+	return errors.Is(err, repository.ErrRetryable) || errors.Is(err, repository.ErrUnavailable)
+}
+
+// retryWrite: Yazma sorguları yalnızca sunucuya hiç ulaşmadıkları kesinse (SafeToRetry) ya da
+// sunucu işlemi tamamen geri aldıysa (serialization failure, deadlock) tekrar denenir.
+// Bağlantı yanıt beklerken koptuysa yazının uygulanıp uygulanmadığı bilinemez.
+func retryWrite(err error) bool {
+	return errors.Is(err, repository.ErrRetryable) || pgconn.SafeToRetry(err)
+}
+
+// commitError, transaction'ın commit aşamasında alınan hatayı işaretler.
+type commitError struct{ err error }
+
+func (e *commitError) Error() string { return e.err.Error() }
+func (e *commitError) Unwrap() error { return e.err }
+
+// retryTx: Commit öncesi hatalarda transaction sunucuda geri alınmıştır, tüm blok güvenle
+// tekrar çalıştırılabilir. Commit sırasındaki belirsiz hatalar yazma kuralına tabidir.
+func retryTx(err error) bool {
+	var ce *commitError
+	if errors.As(err, &ce) {
+		return retryWrite(err)
+	}
+	return retryRead(err)
+}
+
+// withRetry, fn'i policy'ye göre tekrar dener ve son hatayı çevrilmiş (translateError) olarak döner.
+// Bir sonraki bekleme isteğin deadline'ını aşacaksa beklenmez, son hata döner.
+func withRetry(ctx context.Context, p retryPolicy, log zerolog.Logger, operation string, retryable retryClassifier, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = translateError(fn())
+		if err == nil || attempt >= p.maxAttempts || !retryable(err) {
+			return err
+		}
+
+		delay := backoff(p, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return err
+		}
+
+		l := logger.ContextLogger(ctx, log)
+		l.Warn().
+			Str("event", logger.EventDBQueryRetry).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", operation).
+				Int("attempt", attempt).
+				Dur("backoff_ms", delay)).
+			Msg("Geçici veritabanı hatası, tekrar denenecek")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff, "full jitter" ile [0, min(maxDelay, base*2^(attempt-1))] aralığında bekleme döner.
+// Jitter, failover sonrası tüm replikaların aynı anda veritabanına yüklenmesini engeller.
+func backoff(p retryPolicy, attempt int) time.Duration {
+	ceiling := p.baseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.maxDelay {
+		ceiling = p.maxDelay
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
@@ -0,0 +1,97 @@
+// sentiric-user-service/internal/repository/postgres/retry_test.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+var testRetryPolicy = retryPolicy{maxAttempts: 3, baseDelay: time.Millisecond, maxDelay: 5 * time.Millisecond}
+
+func TestWithRetryRecoversFromTransientErrors(t *testing.T) {
+	transient := []error{
+		&pgconn.PgError{Code: sqlStateSerializationFailure},
+		&pgconn.PgError{Code: sqlStateAdminShutdown},
+		&pgconn.PgError{Code: "08006"},
+		fmt.Errorf("read: %w", syscall.ECONNRESET),
+	}
+	for _, failure := range transient {
+		calls := 0
+		err := withRetry(context.Background(), testRetryPolicy, zerolog.Nop(), "test", retryRead, func() error {
+			calls++
+			if calls == 1 {
+				return failure
+			}
+			return nil
+		})
+		if err != nil || calls != 2 {
+			t.Errorf("%v: err=%v calls=%d, want nil after 2 calls", failure, err, calls)
+		}
+	}
+}
+
+func TestWithRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), testRetryPolicy, zerolog.Nop(), "test", retryRead, func() error {
+		calls++
+		return &pgconn.PgError{Code: sqlStateUniqueViolation}
+	})
+	if !errors.Is(err, repository.ErrConflict) || calls != 1 {
+		t.Fatalf("err=%v calls=%d, want ErrConflict after 1 call", err, calls)
+	}
+}
+
+func TestWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), testRetryPolicy, zerolog.Nop(), "test", retryRead, func() error {
+		calls++
+		return &pgconn.PgError{Code: sqlStateDeadlockDetected}
+	})
+	if !errors.Is(err, repository.ErrRetryable) || calls != testRetryPolicy.maxAttempts {
+		t.Fatalf("err=%v calls=%d, want ErrRetryable after %d calls", err, calls, testRetryPolicy.maxAttempts)
+	}
+}
+
+func TestWithRetryStaysWithinDeadline(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 10, baseDelay: time.Second, maxDelay: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	calls := 0
+	err := withRetry(ctx, policy, zerolog.Nop(), "test", retryRead, func() error {
+		calls++
+		return &pgconn.PgError{Code: sqlStateCannotConnectNow}
+	})
+	if !errors.Is(err, repository.ErrUnavailable) {
+		t.Fatalf("err=%v, want ErrUnavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("retry ran for %v, past the request deadline", elapsed)
+	}
+}
+
+func TestRetryWriteOnlyWhenSafe(t *testing.T) {
+	// Yanıt beklerken kopan bağlantı: yazının uygulanıp uygulanmadığı bilinmez.
+	ambiguous := translateError(fmt.Errorf("read: %w", syscall.ECONNRESET))
+	if retryWrite(ambiguous) {
+		t.Error("retryWrite retried a write with an unknown outcome")
+	}
+	if !retryWrite(translateError(&pgconn.PgError{Code: sqlStateSerializationFailure})) {
+		t.Error("retryWrite refused a rolled-back serialization failure")
+	}
+	if retryTx(translateError(&commitError{err: fmt.Errorf("read: %w", syscall.ECONNRESET)})) {
+		t.Error("retryTx retried an ambiguous commit")
+	}
+	if !retryTx(translateError(fmt.Errorf("read: %w", syscall.ECONNRESET))) {
+		t.Error("retryTx refused a pre-commit connection reset")
+	}
+}
@@ -0,0 +1,34 @@
+// sentiric-user-service/internal/repository/postgres/tx.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// sqlTx, *sql.Tx'i TxHook'lara repository.Tx olarak verir.
+type sqlTx struct{ tx *sql.Tx }
+
+func (t sqlTx) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (t sqlTx) QueryRow(ctx context.Context, query string, args ...any) repository.Row {
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
+
+// pgxTx, pgx.Tx'i TxHook'lara repository.Tx olarak verir.
+type pgxTx struct{ tx pgx.Tx }
+
+func (t pgxTx) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := t.tx.Exec(ctx, query, args...)
+	return err
+}
+
+func (t pgxTx) QueryRow(ctx context.Context, query string, args ...any) repository.Row {
+	return t.tx.QueryRow(ctx, query, args...)
+}
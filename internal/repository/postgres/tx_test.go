@@ -0,0 +1,89 @@
+// sentiric-user-service/internal/repository/postgres/tx_test.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+)
+
+// TestTxHook: Hook, değişikliği aynı transaction içinde görmeli; hook hata dönerse
+// değişiklik geri alınmalıdır.
+func TestTxHook(t *testing.T) {
+	ctx := context.Background()
+	db, backends := testBackends(t)
+	errHook := errors.New("hook failed")
+	failing := repository.WithTxHook(ctx, func(context.Context, repository.Tx, string) error { return errHook })
+
+	for name, repo := range backends {
+		t.Run(name, func(t *testing.T) {
+			tenant := "txhook_" + uuid.NewString()
+			if _, err := db.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES ($1, 'TxHook')`, tenant); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				db.ExecContext(ctx, `DELETE FROM agent_profiles WHERE tenant_id = $1`, tenant)
+				db.ExecContext(ctx, `DELETE FROM users WHERE tenant_id = $1`, tenant)
+				db.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenant)
+				db.ExecContext(ctx, `DELETE FROM outbox_events WHERE tenant_id = $1`, tenant)
+			})
+
+			// Hook, transaction içinde yeni kullanıcıyı görür ve ID'sini alır.
+			var hookID, hookTenant string
+			seeUser := repository.WithTxHook(ctx, func(ctx context.Context, tx repository.Tx, id string) error {
+				hookID = id
+				return tx.QueryRow(ctx, `SELECT tenant_id FROM users WHERE id = $1`, id).Scan(&hookTenant)
+			})
+			user, err := repo.CreateUser(seeUser, &userv1.User{TenantId: tenant, UserType: "agent"},
+				&userv1.CreateUserRequest_InitialContact{ContactType: "email"}, uuid.NewString()+"@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hookID != user.Id || hookTenant != tenant {
+				t.Fatalf("hook saw id=%q tenant=%q, want %q %q", hookID, hookTenant, user.Id, tenant)
+			}
+
+			if _, err := repo.CreateUser(failing, &userv1.User{TenantId: tenant, UserType: "agent"},
+				&userv1.CreateUserRequest_InitialContact{ContactType: "email"}, "rolled-back-"+tenant); err == nil {
+				t.Fatal("CreateUser succeeded with a failing hook")
+			}
+			if _, err := repo.FetchUserByContact(repository.WithPrimary(ctx), "email", "rolled-back-"+tenant); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("user committed despite failing hook: %v", err)
+			}
+
+			sipUsername := "txhook-" + uuid.NewString()
+			if err := repo.CreateSipCredential(failing, user.Id, sipUsername, "ha1"); err == nil {
+				t.Fatal("CreateSipCredential succeeded with a failing hook")
+			}
+			if _, _, _, err := repo.FetchSipCredentials(repository.WithPrimary(ctx), sipUsername); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("SIP credential committed despite failing hook: %v", err)
+			}
+
+			if err := repo.CreateSipCredential(ctx, user.Id, sipUsername, "ha1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.DeleteSipCredential(failing, sipUsername); err == nil {
+				t.Fatal("DeleteSipCredential succeeded with a failing hook")
+			}
+			if _, _, _, err := repo.FetchSipCredentials(repository.WithPrimary(ctx), sipUsername); err != nil {
+				t.Fatalf("SIP credential deleted despite failing hook: %v", err)
+			}
+			// Silinecek satır yoksa hook çağrılmaz; hata ErrNotFound olarak kalır.
+			if err := repo.DeleteSipCredential(failing, "missing-"+sipUsername); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("DeleteSipCredential(missing) = %v, want ErrNotFound", err)
+			}
+
+			profile := &userv1.AgentProfile{UserId: user.Id, DisplayName: "Ayşe", MaxConcurrentCalls: 2, Status: "ONLINE"}
+			if err := repo.UpsertAgentProfile(failing, profile, tenant); err == nil {
+				t.Fatal("UpsertAgentProfile succeeded with a failing hook")
+			}
+			if _, err := repo.GetAgentProfile(repository.WithPrimary(ctx), user.Id); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("agent profile committed despite failing hook: %v", err)
+			}
+		})
+	}
+}
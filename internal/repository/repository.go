@@ -21,8 +21,18 @@ type UserRepository interface {
 
 	// Helper
 	FetchContactsForUser(ctx context.Context, userID string) ([]*userv1.Contact, error)
+	// FetchContactsForUsers, liste/export akışları için iletişim bilgilerini kullanıcı ID'sine göre toplu döner.
+	FetchContactsForUsers(ctx context.Context, userIDs []string) (map[string][]*userv1.Contact, error)
 
 	// [YENİ] Agent Profiles
 	GetAgentProfile(ctx context.Context, userID string) (*userv1.AgentProfile, error)
 	UpsertAgentProfile(ctx context.Context, profile *userv1.AgentProfile, tenantID string) error
+	CountAgentsByStatus(ctx context.Context) ([]AgentStatusCount, error)
+}
+
+// AgentStatusCount, bir tenant'taki belirli durumdaki ajan sayısıdır (metrikler için).
+type AgentStatusCount struct {
+	TenantID string
+	Status   string
+	Count    int64
 }
@@ -0,0 +1,375 @@
+// sentiric-user-service/internal/repository/repotest/repotest.go
+
+// Package repotest, her repository.UserRepository implementasyonunun çalıştırması gereken
+// ortak uyumluluk (conformance) testlerini içerir. Yeni bir backend eklendiğinde kendi
+// _test.go dosyasından Run çağrılır; böylece backend'ler arasında davranış farkı oluşamaz.
+package repotest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"google.golang.org/protobuf/proto"
+)
+
+// Harness, test edilen repository ve testin ihtiyaç duyduğu backend'e özgü yardımcılardır.
+type Harness struct {
+	Repo repository.UserRepository
+
+	// Tenant ve OtherTenant, kayıtlı iki farklı tenant ID'sidir.
+	Tenant      string
+	OtherTenant string
+
+	// AddContact, kullanıcıya ek bir iletişim bilgisi ekler. UserRepository'de karşılığı
+	// olmadığı için backend'e özgüdür; tekillik ihlalinde ErrConflict dönmelidir.
+	AddContact func(ctx context.Context, userID, contactType, contactValue string, primary bool) error
+}
+
+// Factory, her alt test için boş (veya testlerle çakışmayacak) bir Harness hazırlar.
+// Temizlik gerekiyorsa t.Cleanup ile kaydedilmelidir.
+type Factory func(t *testing.T) Harness
+
+// Run, uyumluluk testlerini çalıştırır.
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, h Harness)
+	}{
+		{"UserNotFound", testUserNotFound},
+		{"CreateAndFetchUser", testCreateAndFetchUser},
+		{"CreateUserConflict", testCreateUserConflict},
+		{"CreateUserUnknownTenant", testCreateUserUnknownTenant},
+		{"ContactOrderingAndPrimary", testContactOrderingAndPrimary},
+		{"FetchContactsForUsers", testFetchContactsForUsers},
+		{"SipCredentials", testSipCredentials},
+		{"AgentProfileUpsert", testAgentProfileUpsert},
+		{"AgentProfileConstraints", testAgentProfileConstraints},
+		{"CountAgentsByStatus", testCountAgentsByStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory(t))
+		})
+	}
+}
+
+// unique, paylaşılan bir veritabanında önceki çalıştırmalarla çakışmayan değerler üretir.
+func unique(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
+
+func createUser(t *testing.T, h Harness, tenant, userType, contactType, contactValue string) *userv1.User {
+	t.Helper()
+	name := "Conformance " + userType
+	lang := "tr"
+	user, err := h.Repo.CreateUser(context.Background(),
+		&userv1.User{Name: &name, TenantId: tenant, UserType: userType, PreferredLanguageCode: &lang},
+		&userv1.CreateUserRequest_InitialContact{ContactType: contactType, ContactValue: contactValue},
+		contactValue,
+	)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+func expectErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("%s: err = %v, want %v", op, err, want)
+	}
+}
+
+func expectUser(t *testing.T, op string, got, want *userv1.User) {
+	t.Helper()
+	if !proto.Equal(got, want) {
+		t.Fatalf("%s:\n got  %v\n want %v", op, got, want)
+	}
+}
+
+func testUserNotFound(t *testing.T, h Harness) {
+	ctx := context.Background()
+	_, err := h.Repo.FetchUserByID(ctx, uuid.NewString())
+	expectErr(t, "FetchUserByID", err, repository.ErrNotFound)
+
+	_, err = h.Repo.FetchUserByContact(ctx, "phone", unique("+90"))
+	expectErr(t, "FetchUserByContact", err, repository.ErrNotFound)
+
+	contacts, err := h.Repo.FetchContactsForUser(ctx, uuid.NewString())
+	if err != nil || len(contacts) != 0 {
+		t.Fatalf("FetchContactsForUser(unknown) = %v, %v; want empty, nil", contacts, err)
+	}
+}
+
+func testCreateAndFetchUser(t *testing.T, h Harness) {
+	ctx := context.Background()
+	phone := unique("+90")
+	created := createUser(t, h, h.Tenant, "caller", "phone", phone)
+
+	if created.GetId() == "" {
+		t.Fatal("CreateUser returned an empty id")
+	}
+	if created.GetTenantId() != h.Tenant || created.GetUserType() != "caller" ||
+		created.GetName() != "Conformance caller" || created.GetPreferredLanguageCode() != "tr" {
+		t.Fatalf("CreateUser returned unexpected fields: %v", created)
+	}
+	if len(created.GetContacts()) != 1 {
+		t.Fatalf("CreateUser returned %d contacts, want 1", len(created.GetContacts()))
+	}
+	c := created.GetContacts()[0]
+	if c.GetUserId() != created.GetId() || c.GetContactType() != "phone" || c.GetContactValue() != phone || !c.GetIsPrimary() || c.GetId() == 0 {
+		t.Fatalf("CreateUser returned unexpected contact: %v", c)
+	}
+
+	byID, err := h.Repo.FetchUserByID(ctx, created.GetId())
+	if err != nil {
+		t.Fatalf("FetchUserByID: %v", err)
+	}
+	expectUser(t, "FetchUserByID", byID, created)
+
+	byContact, err := h.Repo.FetchUserByContact(ctx, "phone", phone)
+	if err != nil {
+		t.Fatalf("FetchUserByContact: %v", err)
+	}
+	expectUser(t, "FetchUserByContact", byContact, created)
+
+	// Aynı değer farklı tipte başka bir iletişim bilgisidir.
+	_, err = h.Repo.FetchUserByContact(ctx, "whatsapp_id", phone)
+	expectErr(t, "FetchUserByContact(other type)", err, repository.ErrNotFound)
+}
+
+func testCreateUserConflict(t *testing.T, h Harness) {
+	email := unique("conflict-") + "@example.com"
+	createUser(t, h, h.Tenant, "caller", "email", email)
+
+	_, err := h.Repo.CreateUser(context.Background(),
+		&userv1.User{TenantId: h.OtherTenant, UserType: "guest"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "email", ContactValue: email},
+		email,
+	)
+	expectErr(t, "CreateUser(duplicate contact)", err, repository.ErrConflict)
+
+	// Başarısız oluşturma yarım kayıt bırakmamalıdır: iletişim bilgisi hâlâ ilk kullanıcıya aittir.
+	user, err := h.Repo.FetchUserByContact(context.Background(), "email", email)
+	if err != nil || user.GetTenantId() != h.Tenant {
+		t.Fatalf("after conflict: user=%v err=%v", user, err)
+	}
+}
+
+func testCreateUserUnknownTenant(t *testing.T, h Harness) {
+	phone := unique("+90")
+	_, err := h.Repo.CreateUser(context.Background(),
+		&userv1.User{TenantId: unique("missing-tenant-"), UserType: "caller"},
+		&userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: phone},
+		phone,
+	)
+	expectErr(t, "CreateUser(unknown tenant)", err, repository.ErrForeignKey)
+
+	_, err = h.Repo.FetchUserByContact(context.Background(), "phone", phone)
+	expectErr(t, "FetchUserByContact(after failed create)", err, repository.ErrNotFound)
+}
+
+func testContactOrderingAndPrimary(t *testing.T, h Harness) {
+	ctx := context.Background()
+	user := createUser(t, h, h.Tenant, "agent", "phone", unique("+90"))
+
+	email := unique("agent-") + "@example.com"
+	whatsapp := unique("wa-")
+	if err := h.AddContact(ctx, user.GetId(), "email", email, false); err != nil {
+		t.Fatalf("AddContact(email): %v", err)
+	}
+	if err := h.AddContact(ctx, user.GetId(), "whatsapp_id", whatsapp, false); err != nil {
+		t.Fatalf("AddContact(whatsapp): %v", err)
+	}
+	expectErr(t, "AddContact(duplicate)", h.AddContact(ctx, user.GetId(), "email", email, false), repository.ErrConflict)
+
+	wantOrder := []string{"phone", "email", "whatsapp_id"}
+	check := func(op string, contacts []*userv1.Contact) {
+		t.Helper()
+		if len(contacts) != len(wantOrder) {
+			t.Fatalf("%s: %d contacts, want %d", op, len(contacts), len(wantOrder))
+		}
+		for i, c := range contacts {
+			if c.GetContactType() != wantOrder[i] {
+				t.Fatalf("%s: contact[%d] = %s, want %s (insertion order)", op, i, c.GetContactType(), wantOrder[i])
+			}
+			if c.GetIsPrimary() != (i == 0) {
+				t.Fatalf("%s: contact[%d].is_primary = %v", op, i, c.GetIsPrimary())
+			}
+			if i > 0 && c.GetId() <= contacts[i-1].GetId() {
+				t.Fatalf("%s: contact ids not ascending: %d after %d", op, c.GetId(), contacts[i-1].GetId())
+			}
+		}
+	}
+
+	byID, err := h.Repo.FetchUserByID(ctx, user.GetId())
+	if err != nil {
+		t.Fatalf("FetchUserByID: %v", err)
+	}
+	check("FetchUserByID", byID.GetContacts())
+
+	byEmail, err := h.Repo.FetchUserByContact(ctx, "email", email)
+	if err != nil {
+		t.Fatalf("FetchUserByContact(secondary): %v", err)
+	}
+	check("FetchUserByContact(secondary)", byEmail.GetContacts())
+
+	contacts, err := h.Repo.FetchContactsForUser(ctx, user.GetId())
+	if err != nil {
+		t.Fatalf("FetchContactsForUser: %v", err)
+	}
+	check("FetchContactsForUser", contacts)
+}
+
+func testFetchContactsForUsers(t *testing.T, h Harness) {
+	ctx := context.Background()
+	a := createUser(t, h, h.Tenant, "caller", "phone", unique("+90"))
+	b := createUser(t, h, h.OtherTenant, "caller", "phone", unique("+90"))
+	if err := h.AddContact(ctx, b.GetId(), "email", unique("b-")+"@example.com", false); err != nil {
+		t.Fatalf("AddContact: %v", err)
+	}
+	missing := uuid.NewString()
+
+	byUser, err := h.Repo.FetchContactsForUsers(ctx, []string{a.GetId(), b.GetId(), missing})
+	if err != nil {
+		t.Fatalf("FetchContactsForUsers: %v", err)
+	}
+	if len(byUser) != 2 || len(byUser[a.GetId()]) != 1 || len(byUser[b.GetId()]) != 2 {
+		t.Fatalf("FetchContactsForUsers = %v, want 1 contact for a and 2 for b", byUser)
+	}
+	if _, ok := byUser[missing]; ok {
+		t.Fatal("FetchContactsForUsers returned an entry for an unknown user")
+	}
+	if byUser[b.GetId()][0].GetContactType() != "phone" || byUser[b.GetId()][1].GetContactType() != "email" {
+		t.Fatalf("FetchContactsForUsers did not keep insertion order: %v", byUser[b.GetId()])
+	}
+
+	empty, err := h.Repo.FetchContactsForUsers(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("FetchContactsForUsers(nil) = %v, %v; want empty, nil", empty, err)
+	}
+}
+
+func testSipCredentials(t *testing.T, h Harness) {
+	ctx := context.Background()
+	user := createUser(t, h, h.Tenant, "agent", "phone", unique("+90"))
+	username := unique("sip-")
+
+	_, _, _, err := h.Repo.FetchSipCredentials(ctx, username)
+	expectErr(t, "FetchSipCredentials(missing)", err, repository.ErrNotFound)
+
+	if err := h.Repo.CreateSipCredential(ctx, user.GetId(), username, "ha1-a"); err != nil {
+		t.Fatalf("CreateSipCredential: %v", err)
+	}
+	expectErr(t, "CreateSipCredential(duplicate)",
+		h.Repo.CreateSipCredential(ctx, user.GetId(), username, "ha1-b"), repository.ErrConflict)
+	expectErr(t, "CreateSipCredential(unknown user)",
+		h.Repo.CreateSipCredential(ctx, uuid.NewString(), unique("sip-"), "ha1"), repository.ErrForeignKey)
+
+	userID, tenantID, ha1, err := h.Repo.FetchSipCredentials(ctx, username)
+	if err != nil {
+		t.Fatalf("FetchSipCredentials: %v", err)
+	}
+	if userID != user.GetId() || tenantID != h.Tenant || ha1 != "ha1-a" {
+		t.Fatalf("FetchSipCredentials = (%q, %q, %q), want (%q, %q, ha1-a)", userID, tenantID, ha1, user.GetId(), h.Tenant)
+	}
+
+	if err := h.Repo.DeleteSipCredential(ctx, username); err != nil {
+		t.Fatalf("DeleteSipCredential: %v", err)
+	}
+	expectErr(t, "DeleteSipCredential(again)", h.Repo.DeleteSipCredential(ctx, username), repository.ErrNotFound)
+	_, _, _, err = h.Repo.FetchSipCredentials(ctx, username)
+	expectErr(t, "FetchSipCredentials(deleted)", err, repository.ErrNotFound)
+}
+
+func testAgentProfileUpsert(t *testing.T, h Harness) {
+	ctx := context.Background()
+	user := createUser(t, h, h.Tenant, "agent", "phone", unique("+90"))
+
+	_, err := h.Repo.GetAgentProfile(ctx, user.GetId())
+	expectErr(t, "GetAgentProfile(missing)", err, repository.ErrNotFound)
+
+	first := &userv1.AgentProfile{UserId: user.GetId(), DisplayName: "Ayşe", MaxConcurrentCalls: 1, Status: "OFFLINE"}
+	if err := h.Repo.UpsertAgentProfile(ctx, first, h.Tenant); err != nil {
+		t.Fatalf("UpsertAgentProfile(insert): %v", err)
+	}
+	got, err := h.Repo.GetAgentProfile(ctx, user.GetId())
+	if err != nil || !proto.Equal(got, first) {
+		t.Fatalf("GetAgentProfile after insert = %v, %v; want %v", got, err, first)
+	}
+
+	// Güncelleme display_name, max_concurrent_calls ve status alanlarını tamamen değiştirir.
+	second := &userv1.AgentProfile{UserId: user.GetId(), DisplayName: "Ayşe Y.", MaxConcurrentCalls: 3, Status: "AVAILABLE"}
+	if err := h.Repo.UpsertAgentProfile(ctx, second, h.Tenant); err != nil {
+		t.Fatalf("UpsertAgentProfile(update): %v", err)
+	}
+	got, err = h.Repo.GetAgentProfile(ctx, user.GetId())
+	if err != nil || !proto.Equal(got, second) {
+		t.Fatalf("GetAgentProfile after update = %v, %v; want %v", got, err, second)
+	}
+}
+
+func testAgentProfileConstraints(t *testing.T, h Harness) {
+	ctx := context.Background()
+	user := createUser(t, h, h.Tenant, "agent", "phone", unique("+90"))
+
+	expectErr(t, "UpsertAgentProfile(max_concurrent_calls=0)",
+		h.Repo.UpsertAgentProfile(ctx, &userv1.AgentProfile{UserId: user.GetId(), MaxConcurrentCalls: 0, Status: "OFFLINE"}, h.Tenant),
+		repository.ErrCheckViolation)
+	expectErr(t, "UpsertAgentProfile(unknown user)",
+		h.Repo.UpsertAgentProfile(ctx, &userv1.AgentProfile{UserId: uuid.NewString(), MaxConcurrentCalls: 1, Status: "OFFLINE"}, h.Tenant),
+		repository.ErrForeignKey)
+	expectErr(t, "UpsertAgentProfile(unknown tenant)",
+		h.Repo.UpsertAgentProfile(ctx, &userv1.AgentProfile{UserId: user.GetId(), MaxConcurrentCalls: 1, Status: "OFFLINE"}, unique("missing-tenant-")),
+		repository.ErrForeignKey)
+
+	_, err := h.Repo.GetAgentProfile(ctx, user.GetId())
+	expectErr(t, "GetAgentProfile(after rejected upserts)", err, repository.ErrNotFound)
+}
+
+func testCountAgentsByStatus(t *testing.T, h Harness) {
+	ctx := context.Background()
+	upsert := func(tenant, status string) string {
+		t.Helper()
+		user := createUser(t, h, tenant, "agent", "phone", unique("+90"))
+		if err := h.Repo.UpsertAgentProfile(ctx, &userv1.AgentProfile{UserId: user.GetId(), MaxConcurrentCalls: 1, Status: status}, tenant); err != nil {
+			t.Fatalf("UpsertAgentProfile: %v", err)
+		}
+		return user.GetId()
+	}
+	upsert(h.Tenant, "AVAILABLE")
+	upsert(h.Tenant, "AVAILABLE")
+	moved := upsert(h.Tenant, "BUSY")
+	upsert(h.OtherTenant, "AVAILABLE")
+
+	// Mevcut profilde tenant_id güncellenmez; yalnızca status değişir.
+	if err := h.Repo.UpsertAgentProfile(ctx, &userv1.AgentProfile{UserId: moved, MaxConcurrentCalls: 1, Status: "AVAILABLE"}, h.OtherTenant); err != nil {
+		t.Fatalf("UpsertAgentProfile(other tenant): %v", err)
+	}
+
+	counts, err := h.Repo.CountAgentsByStatus(ctx)
+	if err != nil {
+		t.Fatalf("CountAgentsByStatus: %v", err)
+	}
+	got := map[string]map[string]int64{}
+	for _, c := range counts {
+		if c.TenantID != h.Tenant && c.TenantID != h.OtherTenant {
+			continue // paylaşılan veritabanındaki diğer veriler
+		}
+		if got[c.TenantID] == nil {
+			got[c.TenantID] = map[string]int64{}
+		}
+		got[c.TenantID][c.Status] = c.Count
+	}
+	if got[h.Tenant]["AVAILABLE"] != 3 || got[h.Tenant]["BUSY"] != 0 || got[h.OtherTenant]["AVAILABLE"] != 1 {
+		t.Fatalf("CountAgentsByStatus = %v, want %s: AVAILABLE=3, %s: AVAILABLE=1", got, h.Tenant, h.OtherTenant)
+	}
+}
@@ -0,0 +1,42 @@
+// sentiric-user-service/internal/repository/tx.go
+package repository
+
+import "context"
+
+// Tx, bir yazmanın transaction'ını sürücüden (database/sql, pgx) bağımsız gösterir.
+type Tx interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	QueryRow(ctx context.Context, query string, args ...any) Row
+}
+
+// Row, tek satırlık sorgu sonucudur; *sql.Row ve pgx.Row bu arayüzü sağlar.
+type Row interface {
+	Scan(dest ...any) error
+}
+
+// TxHook, bir değişiklikle aynı transaction'da, commit'ten önce çalışır. id, değişen kaydın
+// kimliğidir (oluşturulan kullanıcının ID'si, SIP kullanıcı adı, ajan profilinin user_id'si).
+// Hook hata dönerse değişiklik geri alınır. Transaction'ı olmayan repository'ler (memory)
+// hook'u tx nil olarak, değişikliği uygulamadan hemen önce çağırır.
+type TxHook func(ctx context.Context, tx Tx, id string) error
+
+type txHookKey struct{}
+
+// WithTxHook, context'teki değişiklikler için çalışacak hook'u ekler.
+func WithTxHook(ctx context.Context, hook TxHook) context.Context {
+	return context.WithValue(ctx, txHookKey{}, hook)
+}
+
+// HasTxHook, context'te bir TxHook olup olmadığını döner.
+func HasTxHook(ctx context.Context) bool {
+	hook, _ := ctx.Value(txHookKey{}).(TxHook)
+	return hook != nil
+}
+
+// RunTxHook, context'te bir hook varsa onu çalıştırır.
+func RunTxHook(ctx context.Context, tx Tx, id string) error {
+	if hook, _ := ctx.Value(txHookKey{}).(TxHook); hook != nil {
+		return hook(ctx, tx, id)
+	}
+	return nil
+}
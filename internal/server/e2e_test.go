@@ -0,0 +1,780 @@
+// sentiric-user-service/internal/server/e2e_test.go
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/authz"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/logger/logtest"
+	"github.com/sentiric/sentiric-user-service/internal/repository/memory"
+	"github.com/sentiric/sentiric-user-service/internal/service"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	e2eTenant     = "tenant-e2e"
+	e2eRealm      = "sentiric.e2e"
+	e2eServerName = "user-service.e2e"
+	e2eClientID   = "spiffe://sentiric/e2e-client"
+	e2eIntruderID = "spiffe://sentiric/intruder"
+)
+
+// testPKI, testler için tek kullanımlık bir CA ve bu CA'nın imzaladığı sertifikaları üretir.
+type testPKI struct {
+	t    *testing.T
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestPKI(t *testing.T, name string) *testPKI {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("CA anahtarı üretilemedi: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CA sertifikası üretilemedi: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testPKI{
+		t:    t,
+		dir:  t.TempDir(),
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// caFile, CA sertifikasını diske yazar ve yolunu döner.
+func (p *testPKI) caFile() string {
+	path := filepath.Join(p.dir, "ca.crt")
+	if err := os.WriteFile(path, p.pem, 0o600); err != nil {
+		p.t.Fatal(err)
+	}
+	return path
+}
+
+func (p *testPKI) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(p.cert)
+	return pool
+}
+
+// issue, verilen isimler için leaf sertifika üretir. spiffeID boş değilse URI SAN olarak eklenir.
+func (p *testPKI) issue(commonName, spiffeID string, dnsNames []string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	p.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+	}
+	if spiffeID != "" {
+		u, err := url.Parse(spiffeID)
+		if err != nil {
+			p.t.Fatal(err)
+		}
+		tmpl.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.cert, &key.PublicKey, p.key)
+	if err != nil {
+		p.t.Fatalf("leaf sertifika üretilemedi: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// issueFiles, sunucu sertifikasını ve anahtarını diske yazar.
+func (p *testPKI) issueFiles(name string, dnsNames []string) (certPath, keyPath string) {
+	p.t.Helper()
+	certPEM, keyPEM := p.issue(name, "", dnsNames, x509.ExtKeyUsageServerAuth)
+	certPath = filepath.Join(p.dir, name+".crt")
+	keyPath = filepath.Join(p.dir, name+".key")
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		p.t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		p.t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+// clientCert, istemci tarafı mTLS sertifikasını döner.
+func (p *testPKI) clientCert(commonName, spiffeID string) tls.Certificate {
+	p.t.Helper()
+	certPEM, keyPEM := p.issue(commonName, spiffeID, nil, x509.ExtKeyUsageClientAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return cert
+}
+
+// e2eEnv, bufconn üzerinde mTLS ile çalışan gerçek bir gRPC sunucusudur.
+type e2eEnv struct {
+	t        *testing.T
+	pki      *testPKI
+	srv      *GrpcServer
+	listener *bufconn.Listener
+	logs     *logtest.Recorder
+}
+
+// startE2E, sunucuyu başlatır; opts test'e özel ayarları (ör. rate limit) uygular.
+func startE2E(t *testing.T, opts ...func(*config.Config)) *e2eEnv {
+	t.Helper()
+	pki := newTestPKI(t, "e2e-ca")
+	certPath, keyPath := pki.issueFiles("server", []string{e2eServerName})
+
+	policy, err := json.Marshal(map[string]any{
+		"rules": []authz.Rule{{Identity: e2eClientID, Methods: []string{authz.Wildcard}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyPath := filepath.Join(pki.dir, "policy.json")
+	if err := os.WriteFile(policyPath, policy, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Env:             "test",
+		SipRealm:        e2eRealm,
+		TLSMode:         config.TLSModeMutual,
+		CertPath:        certPath,
+		KeyPath:         keyPath,
+		CaPath:          pki.caFile(),
+		AuthzPolicyPath: policyPath,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	log, logs := logtest.New()
+
+	svc := service.NewUserService(memory.New(e2eTenant), cfg, log)
+	srv := NewGrpcServer(svc, grpchealth.NewServer(), cfg, log)
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	return &e2eEnv{t: t, pki: pki, srv: srv, listener: listener, logs: logs}
+}
+
+// dial, verilen istemci sertifikası ve güvenilen CA ile bağlanır.
+func (e *e2eEnv) dial(certs []tls.Certificate, roots *x509.CertPool) *grpc.ClientConn {
+	e.t.Helper()
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: certs,
+		RootCAs:      roots,
+		ServerName:   e2eServerName,
+	})
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return e.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		e.t.Fatalf("istemci oluşturulamadı: %v", err)
+	}
+	e.t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// client, policy'de yetkili olan istemci kimliğiyle bağlanır.
+func (e *e2eEnv) client() userv1.UserServiceClient {
+	cert := e.pki.clientCert("e2e-client", e2eClientID)
+	return userv1.NewUserServiceClient(e.dial([]tls.Certificate{cert}, e.pki.pool()))
+}
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func assertCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("code = %v (%v), want %v", got, err, want)
+	}
+}
+
+func TestE2EUserServiceRPCs(t *testing.T) {
+	env := startE2E(t)
+	client := env.client()
+	ctx := testContext(t)
+
+	name := "E2E Agent"
+	created, err := client.CreateUser(ctx, &userv1.CreateUserRequest{
+		TenantId: e2eTenant,
+		UserType: "agent",
+		Name:     &name,
+		InitialContact: &userv1.CreateUserRequest_InitialContact{
+			ContactType:  "phone",
+			ContactValue: "0555 111 22 33",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	userID := created.GetUser().GetId()
+
+	got, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: userID})
+	if err != nil || got.GetUser().GetTenantId() != e2eTenant {
+		t.Fatalf("GetUser: user=%v err=%v", got.GetUser(), err)
+	}
+
+	found, err := client.FindUserByContact(ctx, &userv1.FindUserByContactRequest{
+		ContactType:  "phone",
+		ContactValue: "+905551112233",
+	})
+	if err != nil || found.GetUser().GetId() != userID {
+		t.Fatalf("FindUserByContact: user=%v err=%v", found.GetUser(), err)
+	}
+
+	if _, err := client.CreateSipCredential(ctx, &userv1.CreateSipCredentialRequest{
+		UserId:      userID,
+		SipUsername: "e2e-sip",
+		Password:    "s3cret",
+	}); err != nil {
+		t.Fatalf("CreateSipCredential: %v", err)
+	}
+
+	creds, err := client.GetSipCredentials(ctx, &userv1.GetSipCredentialsRequest{SipUsername: "e2e-sip", Realm: e2eRealm})
+	if err != nil {
+		t.Fatalf("GetSipCredentials: %v", err)
+	}
+	wantHA1 := fmt.Sprintf("%x", md5.Sum([]byte("e2e-sip:"+e2eRealm+":s3cret")))
+	if creds.GetHa1Hash() != wantHA1 || creds.GetUserId() != userID {
+		t.Fatalf("GetSipCredentials = %v, want ha1 %s", creds, wantHA1)
+	}
+
+	profile, err := client.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: userID})
+	if err != nil || profile.GetProfile().GetStatus() != "OFFLINE" {
+		t.Fatalf("GetAgentProfile: profile=%v err=%v", profile.GetProfile(), err)
+	}
+
+	if _, err := client.DeleteSipCredential(ctx, &userv1.DeleteSipCredentialRequest{SipUsername: "e2e-sip"}); err != nil {
+		t.Fatalf("DeleteSipCredential: %v", err)
+	}
+	_, err = client.GetSipCredentials(ctx, &userv1.GetSipCredentialsRequest{SipUsername: "e2e-sip"})
+	assertCode(t, err, codes.NotFound)
+
+	// HA1, SIP sunucusunun parolayı doğrulamasına yeten bir sırdır; hiçbir log satırında yer almamalı.
+	if strings.Contains(env.logs.String(), wantHA1) {
+		t.Fatalf("HA1 hash loglara yazılmış:\n%s", env.logs.String())
+	}
+	if strings.Contains(env.logs.String(), "s3cret") {
+		t.Fatalf("SIP parolası loglara yazılmış")
+	}
+}
+
+// TestE2EPIIRedaction, iletişim değerlerinin ve SIP kullanıcı adlarının ne loglarda ne de
+// loglanan hata mesajlarında açık yazıldığını doğrular (varsayılan politika: mask).
+func TestE2EPIIRedaction(t *testing.T) {
+	env := startE2E(t)
+	client := env.client()
+	ctx := testContext(t)
+
+	_, err := client.FindUserByContact(ctx, &userv1.FindUserByContactRequest{ContactType: "phone", ContactValue: "+90 555 999 88 77"})
+	assertCode(t, err, codes.NotFound)
+	if msg := status.Convert(err).Message(); strings.Contains(msg, "905559998877") || !strings.Contains(msg, "8877") {
+		t.Fatalf("NotFound mesajı maskelenmemiş: %q", msg)
+	}
+
+	create := &userv1.CreateUserRequest{
+		TenantId: e2eTenant,
+		UserType: "caller",
+		InitialContact: &userv1.CreateUserRequest_InitialContact{
+			ContactType: "email", ContactValue: "ayse.yilmaz@example.com",
+		},
+	}
+	if _, err := client.CreateUser(ctx, create); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_, err = client.CreateUser(ctx, create)
+	assertCode(t, err, codes.AlreadyExists)
+
+	_, err = client.GetSipCredentials(ctx, &userv1.GetSipCredentialsRequest{SipUsername: "ghost-extension"})
+	assertCode(t, err, codes.NotFound)
+
+	logs := env.logs.String()
+	for _, secret := range []string{"905559998877", "ayse.yilmaz", "ghost-extension"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("%q loglara açık yazılmış", secret)
+		}
+	}
+	if !strings.Contains(logs, "a***@example.com") {
+		t.Errorf("maskelenmiş e-posta loglarda yok:\n%s", logs)
+	}
+}
+
+func TestE2EStatusCodes(t *testing.T) {
+	env := startE2E(t)
+	client := env.client()
+	ctx := testContext(t)
+	missing := "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"GetUser/invalid", func() error {
+			_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: "not-a-uuid"})
+			return err
+		}, codes.InvalidArgument},
+		{"GetUser/missing", func() error {
+			_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: missing})
+			return err
+		}, codes.NotFound},
+		{"FindUserByContact/invalid", func() error {
+			_, err := client.FindUserByContact(ctx, &userv1.FindUserByContactRequest{ContactType: "fax", ContactValue: "1"})
+			return err
+		}, codes.InvalidArgument},
+		{"FindUserByContact/missing", func() error {
+			_, err := client.FindUserByContact(ctx, &userv1.FindUserByContactRequest{ContactType: "email", ContactValue: "nobody@example.com"})
+			return err
+		}, codes.NotFound},
+		{"CreateUser/unknown-tenant", func() error {
+			_, err := client.CreateUser(ctx, &userv1.CreateUserRequest{
+				TenantId: "no-such-tenant",
+				UserType: "caller",
+				InitialContact: &userv1.CreateUserRequest_InitialContact{
+					ContactType: "email", ContactValue: "a@example.com",
+				},
+			})
+			return err
+		}, codes.FailedPrecondition},
+		{"GetSipCredentials/invalid", func() error {
+			_, err := client.GetSipCredentials(ctx, &userv1.GetSipCredentialsRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"CreateSipCredential/missing-user", func() error {
+			_, err := client.CreateSipCredential(ctx, &userv1.CreateSipCredentialRequest{UserId: missing, SipUsername: "x", Password: "y"})
+			return err
+		}, codes.NotFound},
+		{"DeleteSipCredential/missing", func() error {
+			_, err := client.DeleteSipCredential(ctx, &userv1.DeleteSipCredentialRequest{SipUsername: "ghost"})
+			return err
+		}, codes.NotFound},
+		{"GetAgentProfile/missing", func() error {
+			_, err := client.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: missing})
+			return err
+		}, codes.NotFound},
+		{"UpdateUser/unimplemented", func() error {
+			_, err := client.UpdateUser(ctx, &userv1.UpdateUserRequest{})
+			return err
+		}, codes.Unimplemented},
+		{"DeleteUser/unimplemented", func() error {
+			_, err := client.DeleteUser(ctx, &userv1.DeleteUserRequest{})
+			return err
+		}, codes.Unimplemented},
+		{"AddContact/unimplemented", func() error {
+			_, err := client.AddContact(ctx, &userv1.AddContactRequest{})
+			return err
+		}, codes.Unimplemented},
+		{"UpdateContact/unimplemented", func() error {
+			_, err := client.UpdateContact(ctx, &userv1.UpdateContactRequest{})
+			return err
+		}, codes.Unimplemented},
+		{"DeleteContact/unimplemented", func() error {
+			_, err := client.DeleteContact(ctx, &userv1.DeleteContactRequest{})
+			return err
+		}, codes.Unimplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCode(t, tt.call(), tt.want)
+		})
+	}
+}
+
+func TestE2ETraceIDPropagation(t *testing.T) {
+	env := startE2E(t)
+	client := env.client()
+
+	const traceID = "e2e-trace-0001"
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "x-trace-id", traceID)
+	startup := len(env.logs.Entries(t))
+	_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"})
+	assertCode(t, err, codes.NotFound)
+
+	var traced int
+	// Başlangıç loglarından sonraki her olay (interceptor ve servis katmanı) isteğe aittir.
+	for _, entry := range env.logs.Entries(t)[startup:] {
+		if entry["trace_id"] != traceID {
+			t.Fatalf("trace_id = %v, want %s: %v", entry["trace_id"], traceID, entry)
+		}
+		traced++
+	}
+	if traced < 3 {
+		t.Fatalf("trace_id içeren istek logu bulunamadı:\n%s", env.logs.String())
+	}
+}
+
+// TestE2ETraceIDWithTracerProvider: Gerçek bir tracer provider'la gelen traceparent olmadan
+// yeni bir kök span açılır; upstream'in x-trace-id'si yine de loglarda ve span'de bulunmalıdır.
+func TestE2ETraceIDWithTracerProvider(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		_ = tp.Shutdown(context.Background())
+	})
+
+	env := startE2E(t)
+	const legacyID = "e2e-legacy-0001"
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "x-trace-id", legacyID)
+	_, err := env.client().GetUser(ctx, &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"})
+	assertCode(t, err, codes.NotFound)
+
+	ended := spans.Ended()
+	if len(ended) == 0 {
+		t.Fatal("sunucu span'i kaydedilmedi")
+	}
+	server := ended[len(ended)-1]
+	var tagged bool
+	for _, attr := range server.Attributes() {
+		if string(attr.Key) == legacyTraceAttribute && attr.Value.AsString() == legacyID {
+			tagged = true
+		}
+	}
+	if !tagged {
+		t.Errorf("span %q %s attribute'u taşımıyor: %v", server.Name(), legacyTraceAttribute, server.Attributes())
+	}
+
+	var traced int
+	for _, entry := range env.logs.Entries(t) {
+		if _, ok := entry["event"]; !ok || entry["x_trace_id"] == nil {
+			continue
+		}
+		if entry["x_trace_id"] != legacyID || entry["trace_id"] != server.SpanContext().TraceID().String() || entry["span_id"] == nil {
+			t.Fatalf("trace alanları = %v/%v/%v, want %s ve span'in trace ID'si", entry["trace_id"], entry["span_id"], entry["x_trace_id"], legacyID)
+		}
+		traced++
+	}
+	if traced < 3 {
+		t.Fatalf("x_trace_id içeren istek logu bulunamadı:\n%s", env.logs.String())
+	}
+}
+
+// TestE2ETenantInLogs, istek loglarının tenant_id alanını tam bir kez ve isteğin tenant'ıyla
+// yazdığını doğrular: önce x-tenant-id metadata'sı, sonra istek gövdesi, yoksa "system".
+func TestE2ETenantInLogs(t *testing.T) {
+	missing := "00000000-0000-0000-0000-000000000000"
+	tests := []struct {
+		name     string
+		metadata []string
+		call     func(ctx context.Context, client userv1.UserServiceClient) error
+		want     string
+	}{
+		{
+			name: "request body",
+			call: func(ctx context.Context, client userv1.UserServiceClient) error {
+				_, err := client.CreateUser(ctx, &userv1.CreateUserRequest{
+					TenantId: e2eTenant,
+					UserType: "caller",
+					InitialContact: &userv1.CreateUserRequest_InitialContact{
+						ContactType: "phone", ContactValue: "+90 555 444 33 22",
+					},
+				})
+				return err
+			},
+			want: e2eTenant,
+		},
+		{
+			name:     "metadata",
+			metadata: []string{"x-tenant-id", "tenant-meta"},
+			call: func(ctx context.Context, client userv1.UserServiceClient) error {
+				_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: missing})
+				return err
+			},
+			want: "tenant-meta",
+		},
+		{
+			name: "fallback",
+			call: func(ctx context.Context, client userv1.UserServiceClient) error {
+				_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: missing})
+				return err
+			},
+			want: "system",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := startE2E(t)
+			ctx := metadata.AppendToOutgoingContext(testContext(t), append(tt.metadata, "x-trace-id", "e2e-tenant")...)
+			_ = tt.call(ctx, env.client())
+
+			env.logs.RequireSUTS(t)
+			var checked int
+			for _, entry := range env.logs.Entries(t) {
+				if entry["trace_id"] != "e2e-tenant" {
+					continue
+				}
+				if entry["tenant_id"] != tt.want {
+					t.Fatalf("tenant_id = %v, want %s: %v", entry["tenant_id"], tt.want, entry)
+				}
+				checked++
+			}
+			if checked < 2 {
+				t.Fatalf("istek logu bulunamadı:\n%s", env.logs.String())
+			}
+		})
+	}
+}
+
+func TestE2EHealth(t *testing.T) {
+	env := startE2E(t)
+	cert := env.pki.clientCert("e2e-client", e2eClientID)
+	health := healthpb.NewHealthClient(env.dial([]tls.Certificate{cert}, env.pki.pool()))
+
+	resp, err := health.Check(testContext(t), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Health.Check = %v, %v", resp.GetStatus(), err)
+	}
+}
+
+func TestE2EMutualTLS(t *testing.T) {
+	env := startE2E(t)
+	ctx := testContext(t)
+	req := &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"}
+
+	t.Run("no client certificate", func(t *testing.T) {
+		client := userv1.NewUserServiceClient(env.dial(nil, env.pki.pool()))
+		_, err := client.GetUser(ctx, req)
+		assertCode(t, err, codes.Unavailable)
+	})
+
+	t.Run("untrusted client CA", func(t *testing.T) {
+		rogue := newTestPKI(t, "rogue-ca")
+		cert := rogue.clientCert("e2e-client", e2eClientID)
+		client := userv1.NewUserServiceClient(env.dial([]tls.Certificate{cert}, env.pki.pool()))
+		_, err := client.GetUser(ctx, req)
+		assertCode(t, err, codes.Unavailable)
+	})
+
+	t.Run("untrusted server", func(t *testing.T) {
+		rogue := newTestPKI(t, "rogue-ca")
+		cert := env.pki.clientCert("e2e-client", e2eClientID)
+		client := userv1.NewUserServiceClient(env.dial([]tls.Certificate{cert}, rogue.pool()))
+		_, err := client.GetUser(ctx, req)
+		assertCode(t, err, codes.Unavailable)
+	})
+
+	t.Run("identity not in policy", func(t *testing.T) {
+		cert := env.pki.clientCert("intruder", e2eIntruderID)
+		client := userv1.NewUserServiceClient(env.dial([]tls.Certificate{cert}, env.pki.pool()))
+		_, err := client.GetUser(ctx, req)
+		assertCode(t, err, codes.PermissionDenied)
+	})
+}
+
+// TestE2EReflectionAuthorized: Reflection bir stream servisidir; unary zincirin dışında
+// kaldığı için stream interceptor'ı policy'yi ayrıca uygulamalıdır.
+func TestE2EReflectionAuthorized(t *testing.T) {
+	env := startE2E(t)
+	listServices := func(cert tls.Certificate) (*reflectionpb.ServerReflectionResponse, error) {
+		client := reflectionpb.NewServerReflectionClient(env.dial([]tls.Certificate{cert}, env.pki.pool()))
+		stream, err := client.ServerReflectionInfo(testContext(t))
+		if err != nil {
+			return nil, err
+		}
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}); err != nil {
+			return nil, err
+		}
+		return stream.Recv()
+	}
+
+	resp, err := listServices(env.pki.clientCert("e2e-client", e2eClientID))
+	if err != nil || len(resp.GetListServicesResponse().GetService()) == 0 {
+		t.Fatalf("yetkili istemci için ListServices = %v, %v", resp, err)
+	}
+
+	_, err = listServices(env.pki.clientCert("intruder", e2eIntruderID))
+	assertCode(t, err, codes.PermissionDenied)
+}
+
+// TestE2EPolicyMethodNames: Kurallar tam metod adıyla eşleşir; kısa ad yalnızca tek bir
+// servisteki metoda izin verir, başka servislerdeki aynı adlı metodlara değil.
+func TestE2EPolicyMethodNames(t *testing.T) {
+	env := startE2E(t, func(cfg *config.Config) {
+		policy, err := json.Marshal(map[string]any{
+			"rules": []authz.Rule{{Identity: e2eClientID, Methods: []string{
+				"GetUser", "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+			}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.AuthzPolicyPath = filepath.Join(t.TempDir(), "policy.json")
+		if err := os.WriteFile(cfg.AuthzPolicyPath, policy, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	})
+	conn := env.dial([]tls.Certificate{env.pki.clientCert("e2e-client", e2eClientID)}, env.pki.pool())
+	client := userv1.NewUserServiceClient(conn)
+	ctx := testContext(t)
+
+	_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.FindUserByContact(ctx, &userv1.FindUserByContactRequest{ContactType: "email", ContactValue: "a@example.com"})
+	assertCode(t, err, codes.PermissionDenied)
+
+	v1, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err == nil {
+		err = v1.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}})
+	}
+	if err == nil {
+		_, err = v1.Recv()
+	}
+	if err != nil {
+		t.Fatalf("v1 reflection: %v", err)
+	}
+	// v1alpha aynı metod adını taşır ama kuralda adı geçmez.
+	stream, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	assertCode(t, err, codes.PermissionDenied)
+}
+
+// TestRegisteredServices: Policy'nin metod adlarını çözdüğü liste, sunucuya kayıtlı
+// servislerle aynı olmalıdır.
+func TestRegisteredServices(t *testing.T) {
+	env := startE2E(t)
+	want := make(map[string]bool)
+	for _, sd := range registeredServices {
+		want[sd.ServiceName] = true
+	}
+	info := env.srv.GetServiceInfo()
+	for name := range info {
+		if !want[name] {
+			t.Errorf("servis %s kayıtlı ama registeredServices listesinde yok", name)
+		}
+	}
+	for name := range want {
+		if _, ok := info[name]; !ok {
+			t.Errorf("servis %s registeredServices listesinde ama kayıtlı değil", name)
+		}
+	}
+}
+
+// TestE2ERateLimit: İstemci limiti tenant değiştirilerek aşılamaz; tenant limiti yalnızca
+// güvenilir kimliklerin bildirdiği tenant'a uygulanır ve reddedilen istek istemci kotası harcamaz.
+func TestE2ERateLimit(t *testing.T) {
+	getUser := func(client userv1.UserServiceClient, tenant string) error {
+		ctx := testContext(t)
+		if tenant != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", tenant)
+		}
+		_, err := client.GetUser(ctx, &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"})
+		return err
+	}
+
+	t.Run("rotating tenants", func(t *testing.T) {
+		env := startE2E(t, func(cfg *config.Config) {
+			cfg.RateLimits = "GetUser=0.001:2"
+			cfg.TenantRateLimits = "GetUser=0.001:100"
+			cfg.TenantTrustedIdentities = []string{e2eClientID}
+		})
+		client := env.client()
+		assertCode(t, getUser(client, "tenant-1"), codes.NotFound)
+		assertCode(t, getUser(client, "tenant-2"), codes.NotFound)
+		assertCode(t, getUser(client, "tenant-3"), codes.ResourceExhausted)
+	})
+
+	t.Run("trusted tenant", func(t *testing.T) {
+		env := startE2E(t, func(cfg *config.Config) {
+			cfg.RateLimits = "GetUser=0.001:2"
+			cfg.TenantRateLimits = "GetUser=0.001:1"
+			cfg.TenantTrustedIdentities = []string{e2eClientID}
+		})
+		client := env.client()
+		assertCode(t, getUser(client, "tenant-a"), codes.NotFound)
+		assertCode(t, getUser(client, "tenant-a"), codes.ResourceExhausted)
+		assertCode(t, getUser(client, "tenant-b"), codes.NotFound)
+	})
+
+	t.Run("untrusted tenant ignored", func(t *testing.T) {
+		env := startE2E(t, func(cfg *config.Config) {
+			cfg.RateLimits = "GetUser=0.001:3"
+			cfg.TenantRateLimits = "GetUser=0.001:1"
+		})
+		client := env.client()
+		for range 3 {
+			assertCode(t, getUser(client, "tenant-a"), codes.NotFound)
+		}
+		assertCode(t, getUser(client, "tenant-a"), codes.ResourceExhausted)
+	})
+}
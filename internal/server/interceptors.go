@@ -0,0 +1,306 @@
+// sentiric-user-service/internal/server/interceptors.go
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"path"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/authz"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+	"github.com/sentiric/sentiric-user-service/internal/ratelimit"
+	"github.com/sentiric/sentiric-user-service/internal/validation"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// legacyTraceMetadataKey: W3C öncesi servislerin taşıdığı trace ID metadata anahtarı.
+const (
+	legacyTraceMetadataKey = "x-trace-id"
+	legacyTraceAttribute   = "sentiric.x_trace_id"
+)
+
+// healthMethodPrefix: Health probe'ları saniyede birkaç kez geldiği için loglanmaz.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// unaryInterceptors: Tüm RPC'ler için ortak zinciri kurar.
+// Sıralama önemlidir: metrics en dışta durur ki recovery'nin panikten ürettiği
+// Internal kodunu da saysın; recovery ise sonraki tüm interceptor'ların paniklerini yakalar.
+func unaryInterceptors(policy *authz.Policy, limiter *ratelimit.Limiter, trusted trustedTenants, log zerolog.Logger) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(
+		metricsInterceptor(),
+		recoveryInterceptor(log),
+		tracePropagationInterceptor(),
+		loggingInterceptor(log),
+		authzInterceptor(policy, log),
+		rateLimitInterceptor(limiter, trusted, log),
+		validationInterceptor(),
+	)
+}
+
+// recoveryInterceptor: Handler içindeki panikleri yakalar ve codes.Internal'a çevirir.
+// Böylece tek bir hatalı istek tüm süreci düşürmez.
+func recoveryInterceptor(log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				l := logger.ContextLogger(ctx, log)
+				l.Error().
+					Str("event", logger.EventGrpcPanic).
+					Dict("attributes", zerolog.Dict().
+						Str("method", path.Base(info.FullMethod)).
+						Interface("panic", r).
+						Str("stack", string(debug.Stack()))).
+					Msg("gRPC handler paniği yakalandı")
+				resp = nil
+				err = status.Errorf(codes.Internal, "Sunucu iç hatası")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// metricsInterceptor: Metod ve durum koduna göre istek sayısı ve süresini kaydeder.
+func metricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		metrics.ObserveGrpcRequest(path.Base(info.FullMethod), status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
+
+// tracePropagationInterceptor: Gelen metadata'yı (x-trace-id vb.) giden metadata'ya kopyalar.
+// W3C traceparent ve span oluşturma otelgrpc stats handler'ı tarafından yapılır; eski
+// x-trace-id, trace backend'inde de aranabilmesi için span'e attribute olarak eklenir.
+func tracePropagationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = metadata.NewOutgoingContext(ctx, md)
+			if vals := md.Get(legacyTraceMetadataKey); len(vals) > 0 && vals[0] != "" {
+				trace.SpanFromContext(ctx).SetAttributes(attribute.String(legacyTraceAttribute, vals[0]))
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authzInterceptor: İstemci sertifikasındaki kimliği context'e koyar ve policy'ye göre
+// metoda erişimi denetler. policy nil ise yalnızca kimlik çıkarılır, erişim kısıtlanmaz.
+func authzInterceptor(policy *authz.Policy, log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, policy, info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuthzInterceptor: authzInterceptor'ın stream karşılığıdır. Reflection gibi stream
+// servisleri de aynı policy'ye tabidir; policy'de adı geçmeyen kimlikler erişemez.
+func streamAuthzInterceptor(policy *authz.Policy, log zerolog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), policy, info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize, kimliği context'e koyar ve metoda erişim yoksa PermissionDenied döner.
+func authorize(ctx context.Context, policy *authz.Policy, fullMethod string, log zerolog.Logger) (context.Context, error) {
+	id, ok := authz.PeerIdentity(ctx)
+	if ok {
+		ctx = authz.WithIdentity(ctx, id)
+	}
+
+	if policy == nil || strings.HasPrefix(fullMethod, healthMethodPrefix) {
+		return ctx, nil
+	}
+
+	if !ok || !policy.Allowed(id, fullMethod) {
+		// [SUTS]: AUDIT LOG - Yetkisiz erişim denemesi
+		l := logger.ContextLogger(ctx, log)
+		l.Warn().
+			Str("event", logger.EventAuthzDenied).
+			Dict("attributes", zerolog.Dict().
+				Bool("audit", true).
+				Str("identity", id.String()).
+				Str("method", path.Base(fullMethod))).
+			Msg("Yetkisiz gRPC çağrısı reddedildi")
+		return ctx, status.Errorf(codes.PermissionDenied, "Bu metoda erişim yetkiniz yok: %s", path.Base(fullMethod))
+	}
+	return ctx, nil
+}
+
+// contextStream, stream handler'ına zenginleştirilmiş context'i verir.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// tenantMetadataKey: İsteği yapan tenant'ı belirten opsiyonel metadata anahtarı.
+const tenantMetadataKey = "x-tenant-id"
+
+// rateLimitInterceptor: Metod bazlı iki token bucket uygular: istemci kimliği başına ve tenant başına.
+// Tenant limiti yalnızca tenant güvenilir bir çağırandan geldiğinde uygulanır (bkz. trustedTenants).
+// Limit aşıldığında codes.ResourceExhausted döner ve "retry-after" trailer'ı (saniye) eklenir.
+func rateLimitInterceptor(limiter *ratelimit.Limiter, trusted trustedTenants, log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if limiter == nil || strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(ctx, req)
+		}
+
+		method := path.Base(info.FullMethod)
+		client := clientKey(ctx)
+		tenant := trusted.tenant(ctx, req)
+
+		ok, retryAfter := limiter.Allow(method, client, tenant)
+		if ok {
+			return handler(ctx, req)
+		}
+
+		metrics.IncThrottled(method)
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+
+		l := logger.ContextLogger(ctx, log)
+		l.Warn().
+			Str("event", logger.EventRateLimited).
+			Dict("attributes", zerolog.Dict().
+				Str("method", method).
+				Str("client", client).
+				Str("tenant_id", tenant).
+				Dur("retry_after_ms", retryAfter)).
+			Msg("İstek rate limit nedeniyle reddedildi")
+
+		return nil, status.Errorf(codes.ResourceExhausted, "İstek limiti aşıldı, %d saniye sonra tekrar deneyin", seconds)
+	}
+}
+
+// trustedTenants: İstek tenant'ını (x-tenant-id veya gövde) belirlemesine güvenilen kimlikler,
+// örn. son kullanıcının tenant'ını doğrulayan api-gateway. Diğer istemciler tenant'ı serbestçe
+// değiştirebildiği için onların gönderdiği tenant rate limit anahtarı olarak kullanılmaz.
+type trustedTenants map[string]struct{}
+
+func newTrustedTenants(identities []string) trustedTenants {
+	t := make(trustedTenants, len(identities))
+	for _, id := range identities {
+		t[id] = struct{}{}
+	}
+	return t
+}
+
+// tenant, çağıran güvenilir bir kimlikse isteğin tenant'ını, değilse "" döner.
+func (t trustedTenants) tenant(ctx context.Context, req interface{}) string {
+	id, ok := authz.IdentityFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, name := range id.Names() {
+		if _, ok := t[name]; ok {
+			return requestTenant(ctx, req)
+		}
+	}
+	return ""
+}
+
+// clientKey: mTLS kimliği varsa onu, yoksa (geliştirme modları) peer adresini döner.
+func clientKey(ctx context.Context) string {
+	if id, ok := authz.IdentityFromContext(ctx); ok {
+		return id.String()
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// requestTenant: Tenant'ı önce metadata'dan, yoksa istek gövdesinden (tenant_id alanı) okur.
+func requestTenant(ctx context.Context, req interface{}) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(tenantMetadataKey); len(vals) > 0 && vals[0] != "" {
+			return vals[0]
+		}
+	}
+	if r, ok := req.(interface{ GetTenantId() string }); ok {
+		return r.GetTenantId()
+	}
+	return ""
+}
+
+// validationInterceptor: İsteği handler'a ulaşmadan validation kurallarına göre denetler.
+// Hatalar alan bazlı errdetails.BadRequest içeren InvalidArgument olarak döner.
+func validationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validation.Validate(info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// loggingInterceptor: Her RPC için istek ve yanıt loglarını (süre ve durum kodu ile) basar.
+// İstek gövdesi bilinçli olarak loglanmaz; hassas alanlar (parola, iletişim bilgisi) içerebilir.
+func loggingInterceptor(log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(ctx, req)
+		}
+
+		// Tenant context'e bir kez yazılır; handler ve altındaki katmanların logları tenant_id'yi buradan alır.
+		ctx = logger.WithTenant(ctx, requestTenant(ctx, req))
+		l := logger.ContextLogger(ctx, log)
+		method := path.Base(info.FullMethod)
+
+		l.Debug().
+			Str("event", logger.EventGrpcRequest).
+			Dict("attributes", zerolog.Dict().
+				Str("method", method)).
+			Msg("gRPC İstek Alındı")
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		var event *zerolog.Event
+		switch code {
+		case codes.OK:
+			event = l.Info()
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			event = l.Error().Err(err)
+		default:
+			event = l.Warn().Err(err)
+		}
+
+		event.
+			Str("event", logger.EventGrpcResponse).
+			Dict("attributes", zerolog.Dict().
+				Str("method", method).
+				Str("code", code.String()).
+				Dur("duration_ms", time.Since(start))).
+			Msg("gRPC İstek Tamamlandı")
+
+		return resp, err
+	}
+}
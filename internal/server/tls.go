@@ -0,0 +1,204 @@
+// sentiric-user-service/internal/server/tls.go
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// reloadDebounce: Sertifika rotasyonunda cert ve key ayrı ayrı yazılır; ikisi de
+// diske inene kadar beklemek için olaylar bu süre boyunca birleştirilir.
+const reloadDebounce = 500 * time.Millisecond
+
+// certReloader, sunucu sertifikası, anahtarı ve CA dosyalarını izler ve değiştiklerinde
+// yeniden yükler. Her handshake GetConfigForClient ile o anki config'in bir kopyasını aldığı
+// için devam eden handshake'ler rotasyondan etkilenmez.
+type certReloader struct {
+	certPath   string
+	keyPath    string
+	caPath     string
+	clientAuth tls.ClientAuthType
+	log        zerolog.Logger
+
+	current atomic.Pointer[tls.Config]
+}
+
+// serverCredentials, cfg.TLSMode'a göre gRPC taşıma kimlik bilgilerini hazırlar.
+// mTLS dışındaki modlar config katmanında yalnızca geliştirme profillerine izin verilerek gelir.
+func serverCredentials(cfg *config.Config, log zerolog.Logger) credentials.TransportCredentials {
+	clientAuth := tls.RequireAndVerifyClientCert
+
+	switch cfg.TLSMode {
+	case config.TLSModeInsecure:
+		warnInsecureTransport(cfg, log, "gRPC trafiği ŞİFRELENMEDEN taşınıyor, istemci kimliği doğrulanmıyor")
+		return insecure.NewCredentials()
+	case config.TLSModeServer:
+		warnInsecureTransport(cfg, log, "gRPC istemci sertifikası zorunlu değil (mTLS kapalı)")
+		clientAuth = tls.NoClientCert
+		if cfg.CaPath != "" {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	reloader, err := newCertReloader(cfg.CertPath, cfg.KeyPath, cfg.CaPath, clientAuth, log)
+	if err != nil {
+		log.Fatal().
+			Str("event", logger.EventStartupFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("cert_path", cfg.CertPath)).
+			Msg("TLS kimlik bilgileri yüklenemedi")
+	}
+	if err := reloader.watch(); err != nil {
+		log.Warn().
+			Str("event", logger.EventTLSWatchFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().Str("cert_path", cfg.CertPath)).
+			Msg("Sertifika değişiklikleri izlenemiyor, rotasyon için yeniden başlatma gerekecek")
+	}
+	return credentials.NewTLS(reloader.TLSConfig())
+}
+
+func warnInsecureTransport(cfg *config.Config, log zerolog.Logger, reason string) {
+	log.Warn().
+		Str("event", logger.EventInsecureMode).
+		Dict("attributes", zerolog.Dict().
+			Str("tls_mode", cfg.TLSMode).
+			Str("profile", cfg.Env)).
+		Msgf("⚠️⚠️⚠️ GÜVENSİZ MOD: %s. Bu mod SADECE yerel geliştirme içindir, production'da ASLA kullanmayın! ⚠️⚠️⚠️", reason)
+}
+
+func newCertReloader(certPath, keyPath, caPath string, clientAuth tls.ClientAuthType, log zerolog.Logger) (*certReloader, error) {
+	r := &certReloader{certPath: certPath, keyPath: keyPath, caPath: caPath, clientAuth: clientAuth, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig, gRPC credentials'a verilecek config'i döner.
+func (r *certReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+// reload, dosyaları okur ve ancak hepsi geçerliyse aktif config'i değiştirir.
+// Hata durumunda önceki config kullanılmaya devam eder.
+func (r *certReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return fmt.Errorf("sunucu sertifikası yüklenemedi: %w", err)
+	}
+
+	var caPool *x509.CertPool
+	if r.caPath != "" {
+		caCert, err := os.ReadFile(r.caPath)
+		if err != nil {
+			return fmt.Errorf("CA sertifikası okunamadı: %w", err)
+		}
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("CA sertifikası havuza eklenemedi")
+		}
+	}
+
+	r.current.Store(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   r.clientAuth,
+		ClientCAs:    caPool,
+		// GetConfigForClient'ın döndürdüğü config, credentials.NewTLS'in eklediği
+		// ALPN ayarını içermez; gRPC istemcileri h2 olmadan bağlantıyı reddeder.
+		NextProtos: []string{"h2"},
+	})
+	return nil
+}
+
+// watch, dosyaların bulunduğu dizinleri izler. Dizin izlenir çünkü Kubernetes secret
+// mount'ları dosyaları yerinde değiştirmez, "..data" symlink'ini atomik olarak değiştirir.
+func (r *certReloader) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("dosya izleyici oluşturulamadı: %w", err)
+	}
+
+	dirs := map[string]struct{}{}
+	for _, p := range []string{r.certPath, r.keyPath, r.caPath} {
+		if p == "" {
+			continue
+		}
+		dirs[filepath.Dir(p)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("dizin izlenemedi (%s): %w", dir, err)
+		}
+	}
+
+	go r.loop(watcher)
+	return nil
+}
+
+func (r *certReloader) loop(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			pending = time.After(reloadDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			r.log.Warn().
+				Str("event", logger.EventTLSWatchFailed).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("cert_path", r.certPath)).
+				Msg("Sertifika dosya izleyici hatası")
+		case <-pending:
+			pending = nil
+			r.reloadAndLog()
+		}
+	}
+}
+
+func (r *certReloader) reloadAndLog() {
+	if err := r.reload(); err != nil {
+		r.log.Error().
+			Str("event", logger.EventTLSReloadFailed).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("cert_path", r.certPath).
+				Str("ca_path", r.caPath)).
+			Msg("TLS sertifikaları yeniden yüklenemedi, önceki sertifikalar kullanılmaya devam ediyor")
+		return
+	}
+
+	l := r.log.Info().Str("event", logger.EventTLSReloaded)
+	attrs := zerolog.Dict().Str("cert_path", r.certPath)
+	if leaf := r.current.Load().Certificates[0].Leaf; leaf != nil {
+		attrs.Time("not_after", leaf.NotAfter)
+	}
+	l.Dict("attributes", attrs).Msg("TLS sertifikaları yeniden yüklendi")
+}
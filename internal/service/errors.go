@@ -0,0 +1,40 @@
+// sentiric-user-service/internal/service/errors.go
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// repoStatus, repository hatasını uygun gRPC koduna çevirir. msg istemciye dönen
+// açıklamadır; SQL hatası veya constraint adı gibi iç detaylar istemciye sızdırılmaz.
+func repoStatus(err error, msg string) error {
+	return status.Error(repoCode(err), msg)
+}
+
+func repoCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, repository.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(err, repository.ErrForeignKey):
+		return codes.FailedPrecondition
+	case errors.Is(err, repository.ErrCheckViolation):
+		return codes.InvalidArgument
+	case errors.Is(err, repository.ErrRetryable):
+		return codes.Aborted
+	case errors.Is(err, repository.ErrUnavailable):
+		return codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,98 @@
+// sentiric-user-service/internal/service/phone.go
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/sentiric/sentiric-user-service/internal/validation"
+)
+
+// E.164: '+' ve ardından sıfırla başlamayan en fazla 15 hane. Alt sınır, kabul edilen
+// en kısa gerçek numaralara (ör. +690 XXXX) göre belirlenmiştir.
+const (
+	minE164Digits = 7
+	maxE164Digits = 15
+)
+
+// defaultCountryCode: Tek "0" ile başlayan ulusal formatlı numaralar için varsayılan ülke kodu.
+const defaultCountryCode = "90"
+
+// errInvalidPhone, numaranın E.164'e çevrilemediğini belirtir.
+var errInvalidPhone = errors.New("geçerli bir E.164 telefon numarası değil")
+
+// phoneSeparators: Yazım biçimi olarak kabul edilen ve atılan karakterler (boşluklar ayrıca kabul edilir).
+const phoneSeparators = "-./()"
+
+// normalizePhoneNumber, farklı yazımlarla girilmiş bir numarayı E.164 biçimine (+905551112233) çevirir.
+//   - Her Unicode ondalık hanesi (ör. "٠٥٥٥", tam genişlikli rakamlar) ASCII karşılığına çevrilir.
+//   - "+" yalnızca başta olabilir; "00" uluslararası öneki "+" ile aynı anlama gelir.
+//   - Tek "0" ile başlayan ulusal numaralara varsayılan ülke kodu eklenir.
+//
+// Harf, ortada "+" veya geçersiz uzunluk gibi durumlarda errInvalidPhone döner.
+// Fonksiyon idempotenttir: çıktısı tekrar verildiğinde aynı değeri döner.
+func normalizePhoneNumber(phone string) (string, error) {
+	var digits strings.Builder
+	plus := false
+	for _, r := range strings.TrimSpace(phone) {
+		switch {
+		case r == '+':
+			if plus || digits.Len() > 0 {
+				return "", errInvalidPhone
+			}
+			plus = true
+		case unicode.IsDigit(r):
+			d, ok := digitValue(r)
+			if !ok {
+				return "", errInvalidPhone
+			}
+			digits.WriteByte('0' + d)
+		case unicode.IsSpace(r) || strings.ContainsRune(phoneSeparators, r):
+		default:
+			return "", errInvalidPhone
+		}
+	}
+
+	number := digits.String()
+	if !plus {
+		switch {
+		case strings.HasPrefix(number, "00"):
+			number = number[2:]
+		case strings.HasPrefix(number, "0"):
+			number = defaultCountryCode + number[1:]
+		}
+	}
+
+	if len(number) < minE164Digits || len(number) > maxE164Digits || number[0] == '0' {
+		return "", errInvalidPhone
+	}
+	return "+" + number, nil
+}
+
+// digitValue, bir Unicode ondalık hanesinin (Nd) sayısal değerini döner. Unicode, her
+// yazı sistemindeki 0-9 hanelerini ardışık ve sıfırdan başlayan bloklar halinde kodlar;
+// unicode.Nd aralıkları da bu blokların birleşimidir.
+func digitValue(r rune) (byte, bool) {
+	if r >= '0' && r <= '9' {
+		return byte(r - '0'), true
+	}
+	for _, rng := range unicode.Nd.R16 {
+		if rune(rng.Lo) <= r && r <= rune(rng.Hi) {
+			return byte((r - rune(rng.Lo)) % 10), true
+		}
+	}
+	for _, rng := range unicode.Nd.R32 {
+		if rune(rng.Lo) <= r && r <= rune(rng.Hi) {
+			return byte((r - rune(rng.Lo)) % 10), true
+		}
+	}
+	return 0, false
+}
+
+// invalidPhone, validation katmanıyla aynı biçimde (errdetails.BadRequest) InvalidArgument döner.
+func invalidPhone(field string) error {
+	var v validation.Violations
+	v.Add(field, errInvalidPhone.Error())
+	return v.Err()
+}
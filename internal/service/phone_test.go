@@ -0,0 +1,148 @@
+// sentiric-user-service/internal/service/phone_test.go
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// phoneEquivalents: Aynı numaranın farklı ülkelerde ve kanallarda görülen yazımları.
+// Fuzz seed corpus'u olarak da kullanılır.
+var phoneEquivalents = []struct {
+	want  string
+	forms []string
+}{
+	{"+905551112233", []string{
+		"+905551112233", "+90 555 111 22 33", "+90 (555) 111-22-33", "0090 555 111 22 33",
+		"0555 111 22 33", "0 (555) 111 22 33", "905551112233", "0555.111.22.33",
+		" +90 555 111 2233", "+９０５５５１１１２２３３", "٠٥٥٥١١١٢٢٣٣",
+	}},
+	{"+14155552671", []string{
+		"+1 (415) 555-2671", "+1-415-555-2671", "001 415 555 2671", "1 415 555 2671", "+1.415.555.2671",
+	}},
+	{"+442079460958", []string{
+		"+44 20 7946 0958", "0044 20 7946 0958", "+44 (20) 7946-0958", "44 20 7946 0958",
+	}},
+	{"+4930901820", []string{
+		"+49 30 901820", "0049 30 901820", "+49 (30) 90 18 20", "+49/30/901820",
+	}},
+	{"+33142685300", []string{
+		"+33 1 42 68 53 00", "0033 1 42 68 53 00", "+33.1.42.68.53.00",
+	}},
+	{"+919876543210", []string{
+		"+91 98765 43210", "0091-98765-43210", "+९१ ९८७६५ ४३२१०",
+	}},
+	{"+971501234567", []string{
+		"+971 50 123 4567", "00971 50 123 4567", "+٩٧١ ٥٠ ١٢٣ ٤٥٦٧",
+	}},
+	{"+8613812345678", []string{
+		"+86 138 1234 5678", "0086 138 1234 5678", "+86-138-1234-5678",
+	}},
+	{"+6904321", []string{"+690 4321", "00690 4321"}},
+}
+
+var invalidPhones = []string{
+	"", " ", "+", "00", "0", "abc", "+90 555 ABC 22 33", "+90 555+111 22 33", "++905551112233",
+	"555-CALL-NOW", "+0 555 111 22 33", "123456", "+1234567890123456", "0000000000",
+	"+90 555 111 22 33 ext. 12", "tel:+905551112233", "+90\x00555",
+}
+
+func TestNormalizePhoneNumberEquivalentForms(t *testing.T) {
+	for _, group := range phoneEquivalents {
+		for _, form := range group.forms {
+			got, err := normalizePhoneNumber(form)
+			if err != nil {
+				t.Errorf("normalizePhoneNumber(%q) error: %v", form, err)
+				continue
+			}
+			if got != group.want {
+				t.Errorf("normalizePhoneNumber(%q) = %q, want %q", form, got, group.want)
+			}
+		}
+	}
+}
+
+func TestNormalizePhoneNumberRejectsInvalid(t *testing.T) {
+	for _, in := range invalidPhones {
+		if got, err := normalizePhoneNumber(in); err == nil {
+			t.Errorf("normalizePhoneNumber(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+// reformat, bir E.164 numarasını aynı anlamı taşıyan başka bir yazıma çevirir.
+var reformats = map[string]func(string) string{
+	"spaces": func(s string) string {
+		return s[:3] + " " + s[3:]
+	},
+	"parens-dashes": func(s string) string {
+		return s[:3] + " (" + s[3:5] + ")-" + s[5:]
+	},
+	"00-prefix": func(s string) string {
+		return "00" + s[1:]
+	},
+	"fullwidth": func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r - '0' + '０'
+			}
+			return r
+		}, s)
+	},
+	"arabic-indic": func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r - '0' + '٠'
+			}
+			return r
+		}, s)
+	},
+}
+
+// checkPhoneProperties, herhangi bir girdi için geçerli olması gereken özellikleri doğrular.
+func checkPhoneProperties(t *testing.T, in string) {
+	t.Helper()
+	got, err := normalizePhoneNumber(in)
+	if err != nil {
+		return
+	}
+	if !e164Pattern.MatchString(got) {
+		t.Fatalf("normalizePhoneNumber(%q) = %q, E.164 değil", in, got)
+	}
+	again, err := normalizePhoneNumber(got)
+	if err != nil || again != got {
+		t.Fatalf("idempotent değil: %q -> %q -> %q (%v)", in, got, again, err)
+	}
+	for name, reformat := range reformats {
+		alt, err := normalizePhoneNumber(reformat(got))
+		if err != nil || alt != got {
+			t.Fatalf("%s yazımı farklı sonuç verdi: %q -> %q, want %q (%v)", name, reformat(got), alt, got, err)
+		}
+	}
+}
+
+func TestNormalizePhoneNumberProperties(t *testing.T) {
+	for _, group := range phoneEquivalents {
+		for _, form := range group.forms {
+			checkPhoneProperties(t, form)
+		}
+	}
+	for _, in := range invalidPhones {
+		checkPhoneProperties(t, in)
+	}
+}
+
+func FuzzNormalizePhoneNumber(f *testing.F) {
+	for _, group := range phoneEquivalents {
+		for _, form := range group.forms {
+			f.Add(form)
+		}
+	}
+	for _, in := range invalidPhones {
+		f.Add(in)
+	}
+	f.Fuzz(checkPhoneProperties)
+}
@@ -7,12 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/rs/zerolog"
 	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
 	"github.com/sentiric/sentiric-user-service/internal/config"
 	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
 	"github.com/sentiric/sentiric-user-service/internal/repository"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -45,15 +45,17 @@ func (s *userService) GetUser(ctx context.Context, req *userv1.GetUserRequest) (
 			return nil, status.Errorf(codes.NotFound, "Kullanıcı bulunamadı: %s", req.GetUserId())
 		}
 		l.Error().
-			Str("event", "DB_ERROR").
+			Str("event", logger.EventDBError).
 			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "get_user")).
 			Msg("Veritabanı hatası")
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	l.Debug().
 		Str("event", logger.EventUserLookup).
-		Str("tenant_id", user.TenantId).
+		Func(logger.Tenant(user.TenantId)).
 		Dict("attributes", zerolog.Dict().
 			Str("user_id", user.Id)).
 		Msg("Kullanıcı ID ile getirildi")
@@ -66,7 +68,11 @@ func (s *userService) FindUserByContact(ctx context.Context, req *userv1.FindUse
 	contactValue := req.GetContactValue()
 
 	if req.GetContactType() == "phone" {
-		contactValue = normalizePhoneNumber(contactValue)
+		normalized, err := normalizePhoneNumber(contactValue)
+		if err != nil {
+			return nil, invalidPhone("contact_value")
+		}
+		contactValue = normalized
 	}
 
 	user, err := s.repo.FetchUserByContact(ctx, req.GetContactType(), contactValue)
@@ -76,22 +82,24 @@ func (s *userService) FindUserByContact(ctx context.Context, req *userv1.FindUse
 				Str("event", logger.EventUserLookupFailed).
 				Dict("attributes", zerolog.Dict().
 					Str("contact_type", req.GetContactType()).
-					Str("contact_value", contactValue)).
+					Func(logger.Contact("contact_value", contactValue))).
 				Msg("İletişim bilgisine ait kullanıcı yok")
 
-			return nil, status.Errorf(codes.NotFound, "Kullanıcı bulunamadı: %s", contactValue)
+			return nil, status.Errorf(codes.NotFound, "Kullanıcı bulunamadı: %s", logger.Redact(logger.PIIContact, contactValue))
 		}
 		l.Error().
-			Str("event", "DB_ERROR").
+			Str("event", logger.EventDBError).
 			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "find_user_by_contact")).
 			Msg("Veritabanı hatası")
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	// [SUTS]: Kullanıcı bulundu
 	l.Info().
 		Str("event", logger.EventUserLookup).
-		Str("tenant_id", user.TenantId).
+		Func(logger.Tenant(user.TenantId)).
 		Dict("attributes", zerolog.Dict().
 			Str("user_id", user.Id).
 			Str("contact_type", req.GetContactType())).
@@ -103,9 +111,17 @@ func (s *userService) FindUserByContact(ctx context.Context, req *userv1.FindUse
 func (s *userService) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.CreateUserResponse, error) {
 	l := logger.ContextLogger(ctx, s.log)
 
+	if req.GetInitialContact() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "initial_contact zorunludur")
+	}
+
 	normalizedValue := req.InitialContact.GetContactValue()
 	if req.InitialContact.GetContactType() == "phone" {
-		normalizedValue = normalizePhoneNumber(req.InitialContact.GetContactValue())
+		normalized, err := normalizePhoneNumber(normalizedValue)
+		if err != nil {
+			return nil, invalidPhone("initial_contact.contact_value")
+		}
+		normalizedValue = normalized
 	}
 
 	newUser := &userv1.User{
@@ -121,21 +137,27 @@ func (s *userService) CreateUser(ctx context.Context, req *userv1.CreateUserRequ
 			l.Warn().
 				Str("event", logger.EventUserConflict).
 				Dict("attributes", zerolog.Dict().
-					Str("contact_value", normalizedValue)).
+					Func(logger.Contact("contact_value", normalizedValue))).
 				Msg("Kullanıcı/Kontak zaten mevcut")
-			return nil, status.Errorf(codes.AlreadyExists, "Bu iletişim bilgisi zaten kayıtlı: %s", normalizedValue)
+			return nil, status.Errorf(codes.AlreadyExists, "Bu iletişim bilgisi zaten kayıtlı: %s", logger.Redact(logger.PIIContact, normalizedValue))
+		}
+		if errors.Is(err, repository.ErrForeignKey) {
+			return nil, status.Errorf(codes.FailedPrecondition, "Tenant bulunamadı: %s", req.GetTenantId())
 		}
 		l.Error().
-			Str("event", "USER_CREATION_FAIL").
+			Str("event", logger.EventUserCreateFailed).
+			Func(logger.Tenant(req.GetTenantId())).
 			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("user_type", req.GetUserType())).
 			Msg("Kullanıcı oluşturma hatası")
-		return nil, status.Errorf(codes.Internal, "Kullanıcı oluşturulamadı: %v", err)
+		return nil, repoStatus(err, "Kullanıcı oluşturulamadı")
 	}
 
 	// [SUTS]: AUDIT LOG - Explicit Tenant Override
 	l.Info().
 		Str("event", logger.EventUserCreated).
-		Str("tenant_id", user.TenantId).
+		Func(logger.Tenant(user.TenantId)).
 		Dict("attributes", zerolog.Dict().
 			Str("user_id", user.Id).
 			Str("user_type", user.UserType)).
@@ -150,31 +172,35 @@ func (s *userService) GetSipCredentials(ctx context.Context, req *userv1.GetSipC
 	l.Debug().
 		Str("event", logger.EventSipAuthAttempt).
 		Dict("attributes", zerolog.Dict().
-			Str("username", req.SipUsername).
+			Func(logger.SipUsername("username", req.SipUsername)).
 			Str("requested_realm", req.Realm)).
 		Msg("SIP Kimlik Bilgileri İsteniyor")
 
 	userID, tenantID, ha1Hash, err := s.repo.FetchSipCredentials(ctx, req.GetSipUsername())
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
+			metrics.IncSipAuth(metrics.SipAuthNotFound)
 			l.Warn().
 				Str("event", logger.EventSipAuthFailure).
 				Dict("attributes", zerolog.Dict().
 					Str("reason", "user_not_found").
-					Str("username", req.GetSipUsername())).
+					Func(logger.SipUsername("username", req.GetSipUsername()))).
 				Msg("SIP Auth Başarısız: Kullanıcı yok")
 
-			return nil, status.Errorf(codes.NotFound, "SIP kullanıcısı bulunamadı: %s", req.GetSipUsername())
+			return nil, status.Errorf(codes.NotFound, "SIP kullanıcısı bulunamadı: %s", logger.Redact(logger.PIISipUsername, req.GetSipUsername()))
 		}
 		l.Error().
-			Str("event", "DB_ERROR").
+			Str("event", logger.EventDBError).
 			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "get_sip_credentials")).
 			Msg("Veritabanı hatası")
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	// Realm Check
 	if req.Realm != "" && req.Realm != s.config.SipRealm {
+		metrics.IncSipAuth(metrics.SipAuthRealmMismatch)
 		l.Warn().
 			Str("event", logger.EventSipAuthFailure).
 			Dict("attributes", zerolog.Dict().
@@ -183,9 +209,10 @@ func (s *userService) GetSipCredentials(ctx context.Context, req *userv1.GetSipC
 				Str("received", req.Realm)).
 			Msg("SIP Auth Uyarısı: Realm uyuşmazlığı")
 	} else {
+		metrics.IncSipAuth(metrics.SipAuthSuccess)
 		l.Info().
 			Str("event", logger.EventSipAuthSuccess).
-			Str("tenant_id", tenantID).
+			Func(logger.Tenant(tenantID)).
 			Dict("attributes", zerolog.Dict().
 				Str("user_id", userID)).
 			Msg("SIP Kimlik Bilgileri Sağlandı")
@@ -201,12 +228,14 @@ func (s *userService) GetSipCredentials(ctx context.Context, req *userv1.GetSipC
 func (s *userService) CreateSipCredential(ctx context.Context, req *userv1.CreateSipCredentialRequest) (*userv1.CreateSipCredentialResponse, error) {
 	l := logger.ContextLogger(ctx, s.log)
 
-	user, err := s.repo.FetchUserByID(ctx, req.UserId)
+	// Kullanıcı az önce oluşturulmuş olabilir (provisioning akışı); replika gecikmesi
+	// yüzünden NotFound dönmemesi için primary'den okunur.
+	user, err := s.repo.FetchUserByID(repository.WithPrimary(ctx), req.UserId)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, status.Errorf(codes.NotFound, "İlişkili kullanıcı bulunamadı")
 		}
-		return nil, status.Errorf(codes.Internal, "Kullanıcı sorgulanamadı")
+		return nil, repoStatus(err, "Kullanıcı sorgulanamadı")
 	}
 
 	realm := s.config.SipRealm
@@ -218,22 +247,29 @@ func (s *userService) CreateSipCredential(ctx context.Context, req *userv1.Creat
 	if err != nil {
 		if errors.Is(err, repository.ErrConflict) {
 			l.Warn().
-				Str("event", "SIP_CRED_CONFLICT").
+				Str("event", logger.EventSipCredConflict).
+				Func(logger.Tenant(user.TenantId)).
 				Dict("attributes", zerolog.Dict().
-					Str("username", req.SipUsername)).
+					Func(logger.SipUsername("username", req.SipUsername))).
 				Msg("SIP kullanıcı adı çakışması")
 			return nil, status.Errorf(codes.AlreadyExists, "Bu SIP kullanıcı adı zaten mevcut")
 		}
-		l.Error().Err(err).Msg("Veritabanı hatası")
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		l.Error().
+			Str("event", logger.EventDBError).
+			Func(logger.Tenant(user.TenantId)).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "create_sip_credential")).
+			Msg("Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	l.Info().
 		Str("event", logger.EventSipCredCreated).
-		Str("tenant_id", user.TenantId).
+		Func(logger.Tenant(user.TenantId)).
 		Dict("attributes", zerolog.Dict().
 			Str("user_id", req.UserId).
-			Str("sip_username", req.SipUsername).
+			Func(logger.SipUsername("sip_username", req.SipUsername)).
 			Str("realm", realm)).
 		Msg("Yeni SIP kimliği oluşturuldu")
 
@@ -248,42 +284,24 @@ func (s *userService) DeleteSipCredential(ctx context.Context, req *userv1.Delet
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, status.Errorf(codes.NotFound, "Silinecek SIP kullanıcısı bulunamadı")
 		}
-		l.Error().Err(err).Msg("Veritabanı hatası")
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		l.Error().
+			Str("event", logger.EventDBError).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "delete_sip_credential")).
+			Msg("Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	l.Info().
-		Str("event", "SIP_CRED_DELETED").
+		Str("event", logger.EventSipCredDeleted).
 		Dict("attributes", zerolog.Dict().
-			Str("sip_username", req.SipUsername)).
+			Func(logger.SipUsername("sip_username", req.SipUsername))).
 		Msg("SIP kimliği silindi")
 
 	return &userv1.DeleteSipCredentialResponse{Success: true}, nil
 }
 
-// [Kritik Düzeltme]: Tam E.164 Uyumluluğu
-func normalizePhoneNumber(phone string) string {
-	var cleaned strings.Builder
-	for i, r := range phone {
-		if i == 0 && r == '+' {
-			cleaned.WriteRune(r)
-		} else if r >= '0' && r <= '9' {
-			cleaned.WriteRune(r)
-		}
-	}
-
-	result := cleaned.String()
-
-	if strings.HasPrefix(result, "0") {
-		return "+90" + result[1:]
-	}
-
-	if !strings.HasPrefix(result, "+") {
-		return "+" + result
-	}
-	return result
-}
-
 // [YENİ METOD]
 func (s *userService) GetAgentProfile(ctx context.Context, req *userv1.GetAgentProfileRequest) (*userv1.GetAgentProfileResponse, error) {
 	l := logger.ContextLogger(ctx, s.log)
@@ -294,16 +312,27 @@ func (s *userService) GetAgentProfile(ctx context.Context, req *userv1.GetAgentP
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, status.Errorf(codes.NotFound, "Kullanıcı bulunamadı")
 		}
-		return nil, status.Errorf(codes.Internal, "Veritabanı hatası")
+		return nil, repoStatus(err, "Veritabanı hatası")
 	}
 
 	if user.UserType != "agent" && user.UserType != "supervisor" {
-		l.Warn().Str("user_id", req.UserId).Str("type", user.UserType).Msg("Ajan olmayan kullanıcı profili istendi")
+		l.Warn().
+			Str("event", logger.EventAgentNotAgent).
+			Func(logger.Tenant(user.TenantId)).
+			Dict("attributes", zerolog.Dict().
+				Str("user_id", req.UserId).
+				Str("user_type", user.UserType)).
+			Msg("Ajan olmayan kullanıcı profili istendi")
 		return nil, status.Errorf(codes.PermissionDenied, "Bu kullanıcı bir ajan değil")
 	}
 
 	// 2. Profili getir
 	profile, err := s.repo.GetAgentProfile(ctx, req.UserId)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Replika geride olabilir: Yeni yazılmış bir profil (ör. AVAILABLE) varsayılan OFFLINE
+		// profille ezilmesin diye lazy init kararı primary'den okunarak verilir.
+		profile, err = s.repo.GetAgentProfile(repository.WithPrimary(ctx), req.UserId)
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			// Profil yoksa varsayılan (boş/offline) bir profil oluşturup dönelim (Lazy Init)
@@ -315,11 +344,17 @@ func (s *userService) GetAgentProfile(ctx context.Context, req *userv1.GetAgentP
 			}
 			// DB'ye de yazalım ki bir dahaki sefere bulunsun
 			if err := s.repo.UpsertAgentProfile(ctx, defaultProfile, user.TenantId); err != nil {
-				l.Error().Err(err).Msg("Varsayılan ajan profili oluşturulamadı")
+				l.Error().
+					Str("event", logger.EventAgentInitFailed).
+					Func(logger.Tenant(user.TenantId)).
+					Err(err).
+					Dict("attributes", zerolog.Dict().
+						Str("user_id", req.UserId)).
+					Msg("Varsayılan ajan profili oluşturulamadı")
 			}
 			return &userv1.GetAgentProfileResponse{Profile: defaultProfile}, nil
 		}
-		return nil, status.Errorf(codes.Internal, "Profil sorgulama hatası")
+		return nil, repoStatus(err, "Profil sorgulama hatası")
 	}
 
 	return &userv1.GetAgentProfileResponse{Profile: profile}, nil
@@ -0,0 +1,215 @@
+// sentiric-user-service/internal/service/user_test.go
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"github.com/sentiric/sentiric-user-service/internal/config"
+	"github.com/sentiric/sentiric-user-service/internal/logger/logtest"
+	"github.com/sentiric/sentiric-user-service/internal/repository"
+	"github.com/sentiric/sentiric-user-service/internal/repository/memory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testRealm = "sentiric.test"
+
+func newTestService(t *testing.T) UserService {
+	t.Helper()
+	// Servisin yazdığı her log satırı test sonunda SUTS şemasına göre denetlenir.
+	log, logs := logtest.New()
+	t.Cleanup(func() { logs.RequireSUTS(t) })
+	return NewUserService(memory.New("tenant-a"), &config.Config{SipRealm: testRealm}, log)
+}
+
+func createUser(t *testing.T, svc UserService, userType, phone string) *userv1.User {
+	t.Helper()
+	name := "Test " + userType
+	resp, err := svc.CreateUser(context.Background(), &userv1.CreateUserRequest{
+		TenantId: "tenant-a",
+		UserType: userType,
+		Name:     &name,
+		InitialContact: &userv1.CreateUserRequest_InitialContact{
+			ContactType:  "phone",
+			ContactValue: phone,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return resp.GetUser()
+}
+
+func assertCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("code = %v (%v), want %v", got, err, want)
+	}
+}
+
+func TestCreateAndFindUserByNormalizedPhone(t *testing.T) {
+	svc := newTestService(t)
+	created := createUser(t, svc, "caller", "0 (555) 111 22 33")
+
+	if got := created.GetContacts()[0].GetContactValue(); got != "+905551112233" {
+		t.Fatalf("stored contact = %q, want +905551112233", got)
+	}
+
+	resp, err := svc.FindUserByContact(context.Background(), &userv1.FindUserByContactRequest{
+		ContactType:  "phone",
+		ContactValue: "+90 555 111 22 33",
+	})
+	if err != nil {
+		t.Fatalf("FindUserByContact: %v", err)
+	}
+	if resp.GetUser().GetId() != created.GetId() {
+		t.Fatalf("found user %q, want %q", resp.GetUser().GetId(), created.GetId())
+	}
+
+	got, err := svc.GetUser(context.Background(), &userv1.GetUserRequest{UserId: created.GetId()})
+	if err != nil || got.GetUser().GetTenantId() != "tenant-a" {
+		t.Fatalf("GetUser: user=%v err=%v", got.GetUser(), err)
+	}
+}
+
+func TestCreateUserErrors(t *testing.T) {
+	svc := newTestService(t)
+	createUser(t, svc, "caller", "+905551112233")
+
+	_, err := svc.CreateUser(context.Background(), &userv1.CreateUserRequest{
+		TenantId:       "tenant-a",
+		UserType:       "caller",
+		InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: "05551112233"},
+	})
+	assertCode(t, err, codes.AlreadyExists)
+
+	_, err = svc.CreateUser(context.Background(), &userv1.CreateUserRequest{
+		TenantId:       "missing-tenant",
+		UserType:       "caller",
+		InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "email", ContactValue: "a@example.com"},
+	})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	_, err = svc.CreateUser(context.Background(), &userv1.CreateUserRequest{TenantId: "tenant-a", UserType: "caller"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = svc.CreateUser(context.Background(), &userv1.CreateUserRequest{
+		TenantId:       "tenant-a",
+		UserType:       "caller",
+		InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: "+90 555+111"},
+	})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = svc.FindUserByContact(context.Background(), &userv1.FindUserByContactRequest{ContactType: "phone", ContactValue: "anonymous"})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestLookupsNotFound(t *testing.T) {
+	svc := newTestService(t)
+
+	_, err := svc.GetUser(context.Background(), &userv1.GetUserRequest{UserId: "00000000-0000-0000-0000-000000000000"})
+	assertCode(t, err, codes.NotFound)
+
+	_, err = svc.FindUserByContact(context.Background(), &userv1.FindUserByContactRequest{ContactType: "email", ContactValue: "nobody@example.com"})
+	assertCode(t, err, codes.NotFound)
+
+	_, err = svc.GetSipCredentials(context.Background(), &userv1.GetSipCredentialsRequest{SipUsername: "nobody"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestSipCredentialLifecycle(t *testing.T) {
+	svc := newTestService(t)
+	user := createUser(t, svc, "agent", "+905551112233")
+	ctx := context.Background()
+
+	_, err := svc.CreateSipCredential(ctx, &userv1.CreateSipCredentialRequest{UserId: user.GetId(), SipUsername: "1001", Password: "secret"})
+	if err != nil {
+		t.Fatalf("CreateSipCredential: %v", err)
+	}
+	_, err = svc.CreateSipCredential(ctx, &userv1.CreateSipCredentialRequest{UserId: user.GetId(), SipUsername: "1001", Password: "other"})
+	assertCode(t, err, codes.AlreadyExists)
+
+	_, err = svc.CreateSipCredential(ctx, &userv1.CreateSipCredentialRequest{UserId: "00000000-0000-0000-0000-000000000000", SipUsername: "1002", Password: "x"})
+	assertCode(t, err, codes.NotFound)
+
+	resp, err := svc.GetSipCredentials(ctx, &userv1.GetSipCredentialsRequest{SipUsername: "1001", Realm: testRealm})
+	if err != nil {
+		t.Fatalf("GetSipCredentials: %v", err)
+	}
+	wantHA1 := fmt.Sprintf("%x", md5.Sum([]byte("1001:"+testRealm+":secret")))
+	if resp.GetHa1Hash() != wantHA1 || resp.GetUserId() != user.GetId() || resp.GetTenantId() != "tenant-a" {
+		t.Fatalf("unexpected credentials: %+v", resp)
+	}
+
+	if _, err := svc.DeleteSipCredential(ctx, &userv1.DeleteSipCredentialRequest{SipUsername: "1001"}); err != nil {
+		t.Fatalf("DeleteSipCredential: %v", err)
+	}
+	_, err = svc.DeleteSipCredential(ctx, &userv1.DeleteSipCredentialRequest{SipUsername: "1001"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestGetAgentProfile(t *testing.T) {
+	svc := newTestService(t)
+	agent := createUser(t, svc, "agent", "+905551112233")
+	caller := createUser(t, svc, "caller", "+905554445566")
+	ctx := context.Background()
+
+	resp, err := svc.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: agent.GetId()})
+	if err != nil {
+		t.Fatalf("GetAgentProfile: %v", err)
+	}
+	if p := resp.GetProfile(); p.GetStatus() != "OFFLINE" || p.GetMaxConcurrentCalls() != 1 || p.GetDisplayName() != agent.GetName() {
+		t.Fatalf("unexpected default profile: %+v", p)
+	}
+
+	// Varsayılan profil ilk çağrıda kaydedilir; ikinci çağrı aynı profili döner.
+	again, err := svc.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: agent.GetId()})
+	if err != nil || again.GetProfile().GetUserId() != agent.GetId() {
+		t.Fatalf("second GetAgentProfile: profile=%v err=%v", again.GetProfile(), err)
+	}
+
+	_, err = svc.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: caller.GetId()})
+	assertCode(t, err, codes.PermissionDenied)
+}
+
+// laggingReplica, WithPrimary ile işaretlenmemiş ajan profili okumalarını geride kalmış
+// bir replika gibi yanıtlar: profil henüz replikaya ulaşmamıştır.
+type laggingReplica struct {
+	repository.UserRepository
+}
+
+func (r laggingReplica) GetAgentProfile(ctx context.Context, userID string) (*userv1.AgentProfile, error) {
+	if !repository.PrimaryRequired(ctx) {
+		return nil, repository.ErrNotFound
+	}
+	return r.UserRepository.GetAgentProfile(ctx, userID)
+}
+
+func TestGetAgentProfileLazyInitReadsPrimary(t *testing.T) {
+	mem := memory.New("tenant-a")
+	svc := NewUserService(laggingReplica{mem}, &config.Config{SipRealm: testRealm}, zerolog.Nop())
+	agent := createUser(t, svc, "agent", "+905551112233")
+	ctx := context.Background()
+
+	available := &userv1.AgentProfile{UserId: agent.GetId(), DisplayName: "Ayşe", MaxConcurrentCalls: 3, Status: "AVAILABLE"}
+	if err := mem.UpsertAgentProfile(ctx, available, "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := svc.GetAgentProfile(ctx, &userv1.GetAgentProfileRequest{UserId: agent.GetId()})
+	if err != nil {
+		t.Fatalf("GetAgentProfile: %v", err)
+	}
+	if got := resp.GetProfile().GetStatus(); got != "AVAILABLE" {
+		t.Fatalf("returned status = %s, want AVAILABLE", got)
+	}
+	stored, err := mem.GetAgentProfile(ctx, agent.GetId())
+	if err != nil || stored.GetStatus() != "AVAILABLE" || stored.GetMaxConcurrentCalls() != 3 {
+		t.Fatalf("stored profile = %+v, %v; lazy init overwrote it", stored, err)
+	}
+}
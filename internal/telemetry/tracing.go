@@ -0,0 +1,104 @@
+// sentiric-user-service/internal/telemetry/tracing.go
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName, servis içinde açılan span'lerin instrumentation scope adıdır.
+const TracerName = "github.com/sentiric/sentiric-user-service"
+
+// Desteklenen exporter tipleri (OTEL_TRACES_EXPORTER).
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Options, tracing kurulumu için gereken ayarlardır.
+type Options struct {
+	ServiceName  string
+	Version      string
+	Env          string
+	Exporter     string
+	OtlpEndpoint string
+	OtlpInsecure bool
+	SampleRatio  float64
+}
+
+// Setup, global TracerProvider ve W3C propagator'ı kurar.
+// Exporter "none" olsa bile propagator kurulur; böylece gelen traceparent
+// log satırlarına ve giden çağrılara taşınmaya devam eder.
+// Dönen fonksiyon, kapanışta bekleyen span'leri flush etmek için çağrılmalıdır.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if opts.Exporter == "" || opts.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", opts.ServiceName),
+		attribute.String("service.version", opts.Version),
+		attribute.String("deployment.environment", opts.Env),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("trace resource oluşturulamadı: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch opts.Exporter {
+	case ExporterOTLP:
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.OtlpEndpoint)}
+		if opts.OtlpInsecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		exp, err := otlptracegrpc.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("OTLP exporter oluşturulamadı: %w", err)
+		}
+		return exp, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("stdout exporter oluşturulamadı: %w", err)
+		}
+		return exp, nil
+	default:
+		return nil, fmt.Errorf("bilinmeyen trace exporter: %s", opts.Exporter)
+	}
+}
+
+// Tracer, servis genelinde kullanılan tracer'ı döner.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
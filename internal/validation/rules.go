@@ -0,0 +1,91 @@
+// sentiric-user-service/internal/validation/rules.go
+package validation
+
+import (
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+)
+
+// Alan uzunluk sınırları API düzeyindedir; veritabanı kolonları TEXT olduğundan
+// şemada karşılığı yoktur, aşırı büyük girdileri reddetmek için konmuştur.
+const (
+	maxNameLen         = 255
+	maxContactValueLen = 255
+	maxSipUsernameLen  = 128
+)
+
+// ContactTypes, desteklenen iletişim kanalı tipleridir.
+var ContactTypes = map[string]struct{}{
+	"phone":       {},
+	"email":       {},
+	"whatsapp_id": {},
+}
+
+// UserTypes, desteklenen kullanıcı tipleridir.
+var UserTypes = map[string]struct{}{
+	"caller":     {},
+	"guest":      {},
+	"agent":      {},
+	"supervisor": {},
+	"admin":      {},
+}
+
+// rules, UserService metodlarının doğrulama kurallarıdır (tam metod adına göre).
+// Yeni bir RPC eklendiğinde kuralı buraya eklenmelidir; kuralı olmayan metodlar doğrulanmadan geçer.
+var rules = map[string]func(v *Violations, req interface{}){
+	userv1.UserService_GetUser_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.GetUserRequest)
+		v.UUID("user_id", r.GetUserId())
+	},
+	userv1.UserService_FindUserByContact_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.FindUserByContactRequest)
+		v.OneOf("contact_type", r.GetContactType(), ContactTypes)
+		v.Required("contact_value", r.GetContactValue())
+		v.MaxLen("contact_value", r.GetContactValue(), maxContactValueLen)
+	},
+	userv1.UserService_CreateUser_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.CreateUserRequest)
+		v.Required("tenant_id", r.GetTenantId())
+		v.OneOf("user_type", r.GetUserType(), UserTypes)
+		v.MaxLen("name", r.GetName(), maxNameLen)
+		v.LanguageTag("preferred_language_code", r.PreferredLanguageCode)
+		if r.GetInitialContact() == nil {
+			v.Add("initial_contact", "zorunlu alan")
+			return
+		}
+		v.OneOf("initial_contact.contact_type", r.GetInitialContact().GetContactType(), ContactTypes)
+		v.Required("initial_contact.contact_value", r.GetInitialContact().GetContactValue())
+		v.MaxLen("initial_contact.contact_value", r.GetInitialContact().GetContactValue(), maxContactValueLen)
+	},
+	userv1.UserService_GetSipCredentials_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.GetSipCredentialsRequest)
+		v.Required("sip_username", r.GetSipUsername())
+		v.MaxLen("sip_username", r.GetSipUsername(), maxSipUsernameLen)
+	},
+	userv1.UserService_CreateSipCredential_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.CreateSipCredentialRequest)
+		v.UUID("user_id", r.GetUserId())
+		v.Required("sip_username", r.GetSipUsername())
+		v.MaxLen("sip_username", r.GetSipUsername(), maxSipUsernameLen)
+		v.Required("password", r.GetPassword())
+	},
+	userv1.UserService_DeleteSipCredential_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.DeleteSipCredentialRequest)
+		v.Required("sip_username", r.GetSipUsername())
+		v.MaxLen("sip_username", r.GetSipUsername(), maxSipUsernameLen)
+	},
+	userv1.UserService_GetAgentProfile_FullMethodName: func(v *Violations, req interface{}) {
+		r := req.(*userv1.GetAgentProfileRequest)
+		v.UUID("user_id", r.GetUserId())
+	},
+}
+
+// Validate, metodun kurallarını çalıştırır. Kural yoksa nil döner.
+func Validate(fullMethod string, req interface{}) error {
+	rule, ok := rules[fullMethod]
+	if !ok {
+		return nil
+	}
+	var v Violations
+	rule(&v, req)
+	return v.Err()
+}
@@ -0,0 +1,89 @@
+// sentiric-user-service/internal/validation/validation.go
+package validation
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Violations, bir istekteki tüm alan hatalarını toplar. İlk hatada durmaz;
+// istemci tüm sorunları tek yanıtta görür.
+type Violations struct {
+	fields []*errdetails.BadRequest_FieldViolation
+}
+
+// Add, bir alan hatası ekler.
+func (v *Violations) Add(field, description string) {
+	v.fields = append(v.fields, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+}
+
+// Err, hata yoksa nil; varsa errdetails.BadRequest detaylı InvalidArgument döner.
+func (v *Violations) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("Geçersiz istek: %d alan hatalı", len(v.fields)))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v.fields})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// --- Kurallar ---
+
+// Required, alanın boş olmamasını kontrol eder.
+func (v *Violations) Required(field, value string) bool {
+	if value == "" {
+		v.Add(field, "zorunlu alan")
+		return false
+	}
+	return true
+}
+
+// UUID, alanın zorunlu ve geçerli bir UUID olmasını kontrol eder.
+func (v *Violations) UUID(field, value string) {
+	if !v.Required(field, value) {
+		return
+	}
+	if !uuidPattern.MatchString(value) {
+		v.Add(field, "geçerli bir UUID olmalı")
+	}
+}
+
+// OneOf, alanın zorunlu ve izinli değerlerden biri olmasını kontrol eder.
+func (v *Violations) OneOf(field, value string, allowed map[string]struct{}) {
+	if !v.Required(field, value) {
+		return
+	}
+	if _, ok := allowed[value]; !ok {
+		v.Add(field, fmt.Sprintf("desteklenmeyen değer: %q", value))
+	}
+}
+
+// MaxLen, alanın en fazla n karakter olmasını kontrol eder.
+func (v *Violations) MaxLen(field, value string, n int) {
+	if len([]rune(value)) > n {
+		v.Add(field, fmt.Sprintf("en fazla %d karakter olabilir", n))
+	}
+}
+
+// LanguageTag, opsiyonel alan verildiyse geçerli bir BCP-47 dil kodu olmasını kontrol eder.
+func (v *Violations) LanguageTag(field string, value *string) {
+	if value == nil {
+		return
+	}
+	if _, err := language.Parse(*value); err != nil {
+		v.Add(field, fmt.Sprintf("geçerli bir BCP-47 dil kodu olmalı: %q", *value))
+	}
+}
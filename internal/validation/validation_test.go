@@ -0,0 +1,164 @@
+// sentiric-user-service/internal/validation/validation_test.go
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	userv1 "github.com/sentiric/sentiric-contracts/gen/go/sentiric/user/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const validUUID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+
+func ptr(s string) *string { return &s }
+
+// fieldViolations, hatanın InvalidArgument olduğunu doğrular ve BadRequest detayındaki alanları döner.
+func fieldViolations(t *testing.T, err error) []*errdetails.BadRequest_FieldViolation {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			return br.GetFieldViolations()
+		}
+	}
+	t.Fatalf("InvalidArgument without BadRequest details: %v", err)
+	return nil
+}
+
+func fieldNames(violations []*errdetails.BadRequest_FieldViolation) []string {
+	var names []string
+	for _, fv := range violations {
+		names = append(names, fv.GetField())
+	}
+	return names
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		want   []string
+	}{
+		// GetUser
+		{"GetUser valid", userv1.UserService_GetUser_FullMethodName, &userv1.GetUserRequest{UserId: validUUID}, nil},
+		{"GetUser missing id", userv1.UserService_GetUser_FullMethodName, &userv1.GetUserRequest{}, []string{"user_id"}},
+		{"GetUser invalid id", userv1.UserService_GetUser_FullMethodName, &userv1.GetUserRequest{UserId: "123"}, []string{"user_id"}},
+
+		// FindUserByContact
+		{"FindUserByContact valid", userv1.UserService_FindUserByContact_FullMethodName,
+			&userv1.FindUserByContactRequest{ContactType: "phone", ContactValue: "+905551112233"}, nil},
+		{"FindUserByContact empty", userv1.UserService_FindUserByContact_FullMethodName,
+			&userv1.FindUserByContactRequest{}, []string{"contact_type", "contact_value"}},
+		{"FindUserByContact unknown type", userv1.UserService_FindUserByContact_FullMethodName,
+			&userv1.FindUserByContactRequest{ContactType: "fax", ContactValue: "1"}, []string{"contact_type"}},
+		{"FindUserByContact value too long", userv1.UserService_FindUserByContact_FullMethodName,
+			&userv1.FindUserByContactRequest{ContactType: "email", ContactValue: strings.Repeat("a", maxContactValueLen+1)}, []string{"contact_value"}},
+
+		// CreateUser
+		{"CreateUser valid", userv1.UserService_CreateUser_FullMethodName, &userv1.CreateUserRequest{
+			TenantId: "tenant-a", UserType: "caller", Name: ptr("Ayşe"), PreferredLanguageCode: ptr("tr-TR"),
+			InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "phone", ContactValue: "+905551112233"},
+		}, nil},
+		{"CreateUser empty", userv1.UserService_CreateUser_FullMethodName, &userv1.CreateUserRequest{},
+			[]string{"tenant_id", "user_type", "initial_contact"}},
+		{"CreateUser invalid fields", userv1.UserService_CreateUser_FullMethodName, &userv1.CreateUserRequest{
+			TenantId: "tenant-a", UserType: "robot", Name: ptr(strings.Repeat("ş", maxNameLen+1)), PreferredLanguageCode: ptr("not a tag!"),
+			InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "fax", ContactValue: strings.Repeat("1", maxContactValueLen+1)},
+		}, []string{"user_type", "name", "preferred_language_code", "initial_contact.contact_type", "initial_contact.contact_value"}},
+		{"CreateUser empty contact value", userv1.UserService_CreateUser_FullMethodName, &userv1.CreateUserRequest{
+			TenantId: "tenant-a", UserType: "agent",
+			InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "email"},
+		}, []string{"initial_contact.contact_value"}},
+		{"CreateUser name at limit", userv1.UserService_CreateUser_FullMethodName, &userv1.CreateUserRequest{
+			TenantId: "tenant-a", UserType: "guest", Name: ptr(strings.Repeat("ş", maxNameLen)),
+			InitialContact: &userv1.CreateUserRequest_InitialContact{ContactType: "whatsapp_id", ContactValue: "905551112233"},
+		}, nil},
+
+		// GetSipCredentials
+		{"GetSipCredentials valid", userv1.UserService_GetSipCredentials_FullMethodName, &userv1.GetSipCredentialsRequest{SipUsername: "1001"}, nil},
+		{"GetSipCredentials missing", userv1.UserService_GetSipCredentials_FullMethodName, &userv1.GetSipCredentialsRequest{}, []string{"sip_username"}},
+		{"GetSipCredentials too long", userv1.UserService_GetSipCredentials_FullMethodName,
+			&userv1.GetSipCredentialsRequest{SipUsername: strings.Repeat("1", maxSipUsernameLen+1)}, []string{"sip_username"}},
+
+		// CreateSipCredential
+		{"CreateSipCredential valid", userv1.UserService_CreateSipCredential_FullMethodName,
+			&userv1.CreateSipCredentialRequest{UserId: validUUID, SipUsername: "1001", Password: "s3cret"}, nil},
+		{"CreateSipCredential empty", userv1.UserService_CreateSipCredential_FullMethodName,
+			&userv1.CreateSipCredentialRequest{}, []string{"user_id", "sip_username", "password"}},
+		{"CreateSipCredential invalid", userv1.UserService_CreateSipCredential_FullMethodName,
+			&userv1.CreateSipCredentialRequest{UserId: "x", SipUsername: strings.Repeat("1", maxSipUsernameLen+1), Password: "p"}, []string{"user_id", "sip_username"}},
+
+		// DeleteSipCredential
+		{"DeleteSipCredential valid", userv1.UserService_DeleteSipCredential_FullMethodName, &userv1.DeleteSipCredentialRequest{SipUsername: "1001"}, nil},
+		{"DeleteSipCredential missing", userv1.UserService_DeleteSipCredential_FullMethodName, &userv1.DeleteSipCredentialRequest{}, []string{"sip_username"}},
+		{"DeleteSipCredential too long", userv1.UserService_DeleteSipCredential_FullMethodName,
+			&userv1.DeleteSipCredentialRequest{SipUsername: strings.Repeat("1", maxSipUsernameLen+1)}, []string{"sip_username"}},
+
+		// GetAgentProfile
+		{"GetAgentProfile valid", userv1.UserService_GetAgentProfile_FullMethodName, &userv1.GetAgentProfileRequest{UserId: validUUID}, nil},
+		{"GetAgentProfile invalid", userv1.UserService_GetAgentProfile_FullMethodName, &userv1.GetAgentProfileRequest{UserId: "agent-1"}, []string{"user_id"}},
+
+		// Kuralı olmayan metodlar doğrulanmadan geçer.
+		{"no rule", "/grpc.health.v1.Health/Check", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldNames(fieldViolations(t, Validate(tt.method, tt.req)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRulesMatchServiceMethods: Kural anahtarı tam metod adıyla eşleşmezse kural hiç çalışmaz.
+func TestRulesMatchServiceMethods(t *testing.T) {
+	methods := map[string]struct{}{}
+	for _, m := range userv1.UserService_ServiceDesc.Methods {
+		methods["/"+userv1.UserService_ServiceDesc.ServiceName+"/"+m.MethodName] = struct{}{}
+	}
+	for full := range rules {
+		if _, ok := methods[full]; !ok {
+			t.Errorf("%s kuralı UserService'te olmayan bir metoda ait", full)
+		}
+	}
+}
+
+func TestViolationsErr(t *testing.T) {
+	var v Violations
+	if err := v.Err(); err != nil {
+		t.Fatalf("empty Violations.Err() = %v", err)
+	}
+
+	v.Add("user_id", "zorunlu alan")
+	v.OneOf("contact_type", "fax", ContactTypes)
+	err := v.Err()
+
+	if msg := status.Convert(err).Message(); !strings.Contains(msg, "2 alan") {
+		t.Fatalf("message = %q, want violation count", msg)
+	}
+	got := fieldViolations(t, err)
+	want := []*errdetails.BadRequest_FieldViolation{
+		{Field: "user_id", Description: "zorunlu alan"},
+		{Field: "contact_type", Description: `desteklenmeyen değer: "fax"`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].GetField() != want[i].GetField() || got[i].GetDescription() != want[i].GetDescription() {
+			t.Fatalf("violation %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
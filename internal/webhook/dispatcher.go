@@ -0,0 +1,290 @@
+// sentiric-user-service/internal/webhook/dispatcher.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/logger"
+	"github.com/sentiric/sentiric-user-service/internal/metrics"
+)
+
+// userAgent: Alıcıların webhook trafiğini ayırt edebilmesi için gönderilir.
+const userAgent = "sentiric-user-service-webhooks/1.0"
+
+// pruneInterval: Tamamlanmış eski teslimatların silinme sıklığı.
+const pruneInterval = time.Hour
+
+// Options, dispatcher davranışını belirler. Sıfır değerler için varsayılanlar kullanılır.
+type Options struct {
+	// BatchSize, tek seferde kiralanacak en fazla teslimat sayısıdır.
+	BatchSize int
+	// Concurrency, aynı anda yapılacak en fazla HTTP isteği sayısıdır.
+	Concurrency int
+	// PollInterval, NOTIFY gelmediğinde teslimatların kontrol edilme sıklığıdır.
+	PollInterval time.Duration
+	// Timeout, tek bir HTTP isteğinin süre sınırıdır.
+	Timeout time.Duration
+	// MaxAttempts, teslimatın dead durumuna düşmeden önceki toplam deneme sayısıdır.
+	MaxAttempts int
+	// MinBackoff ve MaxBackoff, başarısız denemeler arasındaki gecikmeyi sınırlar.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention, tamamlanmış (delivered, dead) teslimatların silinmeden önce tutulma süresidir.
+	// 0 ise silinmez.
+	Retention time.Duration
+	// AllowInsecureTargets, http adreslerine ve iç ağ (loopback, private, link-local) hedeflerine
+	// gönderime izin verir. Yalnızca geliştirme profillerinde açılmalıdır.
+	AllowInsecureTargets bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 50
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 8
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 8
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 10 * time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = time.Hour
+	}
+	return o
+}
+
+// Dispatcher, zamanı gelen teslimatları imzalayıp abonelik adreslerine POST eder.
+// 2xx dışındaki her yanıt ve bağlantı hatası başarısız denemedir.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	opts   Options
+	log    zerolog.Logger
+
+	wake chan struct{}
+	now  func() time.Time
+}
+
+// NewDispatcher, bir Dispatcher oluşturur. Yönlendirmeler takip edilmez; imzalı gövdenin
+// abonelikte kayıtlı olmayan bir adrese gitmesi istenmez. AllowInsecureTargets kapalıyken
+// iç ağ adreslerine bağlantı açılmaz ve proxy kullanılmaz (proxy hedef kontrolünü atlatırdı).
+func NewDispatcher(store Store, opts Options, log zerolog.Logger) *Dispatcher {
+	opts = opts.withDefaults()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opts.AllowInsecureTargets {
+		dialer := &net.Dialer{Timeout: opts.Timeout, Control: dialControl}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &Dispatcher{
+		store: store,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		opts: opts,
+		log:  log,
+		wake: make(chan struct{}, 1),
+		now:  time.Now,
+	}
+}
+
+// Wake, dispatcher'ı bir sonraki poll'u beklemeden çalıştırır (NOTIFY geldiğinde çağrılır).
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run, ctx iptal edilene kadar teslimatları işler.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+
+	for {
+		for ctx.Err() == nil {
+			n, err := d.dispatchOnce(ctx)
+			if err != nil || n < d.opts.BatchSize {
+				break
+			}
+		}
+		if d.opts.Retention > 0 && d.now().Sub(lastPrune) >= pruneInterval {
+			d.prune(ctx)
+			lastPrune = d.now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// dispatchOnce, bir batch kiralar ve eşzamanlı olarak gönderir. Kiralanan teslimat sayısını döner.
+func (d *Dispatcher) dispatchOnce(ctx context.Context) (int, error) {
+	// Lease, en yavaş isteğin bitip sonucunun kaydedilmesine yetmelidir.
+	jobs, err := d.store.ClaimDue(ctx, d.opts.BatchSize, 2*d.opts.Timeout+time.Minute)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.log.Error().
+				Str("event", logger.EventWebhookStoreError).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("operation", "claim")).
+				Msg("Webhook teslimatları okunamadı")
+		}
+		return 0, err
+	}
+
+	sem := make(chan struct{}, d.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			d.deliver(ctx, job)
+		}()
+	}
+	wg.Wait()
+	return len(jobs), nil
+}
+
+// deliver, tek bir teslimatı gönderir ve sonucu kaydeder.
+func (d *Dispatcher) deliver(ctx context.Context, job Job) {
+	attempt := Attempt{DeliveryID: job.ID, Attempt: job.Attempts + 1, AttemptedAt: d.now()}
+	start := time.Now()
+	attempt.StatusCode, attempt.Error = d.post(ctx, job)
+	attempt.Duration = time.Since(start)
+
+	status := StatusDelivered
+	var retryAfter time.Duration
+	switch {
+	case attempt.Error == "":
+		metrics.IncWebhookDelivery(metrics.WebhookDelivered)
+	case attempt.Attempt >= d.opts.MaxAttempts:
+		status = StatusDead
+		metrics.IncWebhookDelivery(metrics.WebhookDead)
+		d.log.Warn().
+			Str("event", logger.EventWebhookDead).
+			Func(logger.Tenant(job.TenantID)).
+			Dict("attributes", zerolog.Dict().
+				Int64("delivery_id", job.ID).
+				Str("subscription_id", job.SubscriptionID).
+				Str("event_type", job.EventType).
+				Int("attempts", attempt.Attempt).
+				Str("last_error", attempt.Error)).
+			Msg("Webhook teslimatı deneme hakkını doldurdu, dead-letter'a alındı")
+	default:
+		status = StatusPending
+		retryAfter = d.backoff(job.Attempts)
+		metrics.IncWebhookDelivery(metrics.WebhookRetry)
+		d.log.Debug().
+			Str("event", logger.EventWebhookRetry).
+			Func(logger.Tenant(job.TenantID)).
+			Dict("attributes", zerolog.Dict().
+				Int64("delivery_id", job.ID).
+				Int("attempt", attempt.Attempt).
+				Str("error", attempt.Error).
+				Dur("retry_after_ms", retryAfter)).
+			Msg("Webhook teslimatı başarısız, tekrar denenecek")
+	}
+
+	// Süreç kapanırken bile sonuç kaydedilmeli; aksi halde başarılı teslimat lease dolunca tekrar gönderilir.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.store.RecordAttempt(recordCtx, attempt, status, retryAfter); err != nil {
+		d.log.Error().
+			Str("event", logger.EventWebhookStoreError).
+			Func(logger.Tenant(job.TenantID)).
+			Err(err).
+			Dict("attributes", zerolog.Dict().
+				Str("operation", "record_attempt").
+				Int64("delivery_id", job.ID)).
+			Msg("Webhook denemesi kaydedilemedi")
+	}
+}
+
+// post, isteği gönderir; başarılıysa hata metni boş döner.
+func (d *Dispatcher) post(ctx context.Context, job Job) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	// Geliştirme profilinde oluşturulmuş http abonelikleri production'da gönderilmez.
+	if req.URL.Scheme != "https" && !d.opts.AllowInsecureTargets {
+		return 0, "webhook adresi https değil"
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, job.EventType)
+	req.Header.Set(HeaderEventID, job.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, d.now(), job.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+	// Bağlantının yeniden kullanılabilmesi için gövde (sınırlı olarak) okunur.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
+
+// backoff, önceki deneme sayısına göre üstel gecikme döner.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.MinBackoff
+	for i := 0; i < attempts && delay < d.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.opts.MaxBackoff)
+}
+
+func (d *Dispatcher) prune(ctx context.Context) {
+	n, err := d.store.Prune(ctx, d.now().Add(-d.opts.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			d.log.Warn().
+				Str("event", logger.EventWebhookStoreError).
+				Err(err).
+				Dict("attributes", zerolog.Dict().Str("operation", "prune")).
+				Msg("Eski webhook teslimatları silinemedi")
+		}
+		return
+	}
+	if n > 0 {
+		d.log.Debug().
+			Str("event", logger.EventWebhookPruned).
+			Dict("attributes", zerolog.Dict().Int64("deleted", n)).
+			Msg("Eski webhook teslimatları silindi")
+	}
+}
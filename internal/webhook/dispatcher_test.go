@@ -0,0 +1,275 @@
+// sentiric-user-service/internal/webhook/dispatcher_test.go
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// memStore, teslimat tablosunun kiralama davranışını taklit eder.
+type memStore struct {
+	mu       sync.Mutex
+	jobs     []Job
+	leased   map[int64]bool
+	attempts []Attempt
+	status   map[int64]string
+	retry    map[int64]time.Duration
+	pruned   []time.Time
+}
+
+func newMemStore(jobs ...Job) *memStore {
+	s := &memStore{leased: map[int64]bool{}, status: map[int64]string{}, retry: map[int64]time.Duration{}}
+	for _, j := range jobs {
+		s.status[j.ID] = StatusPending
+	}
+	s.jobs = jobs
+	return s
+}
+
+func (s *memStore) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Job
+	for _, j := range s.jobs {
+		if len(out) == limit {
+			break
+		}
+		if s.status[j.ID] != StatusPending || s.leased[j.ID] {
+			continue
+		}
+		s.leased[j.ID] = true
+		out = append(out, j)
+	}
+	return out, nil
+}
+
+func (s *memStore) RecordAttempt(_ context.Context, a Attempt, status string, retryAfter time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, a)
+	s.status[a.DeliveryID] = status
+	s.retry[a.DeliveryID] = retryAfter
+	for i := range s.jobs {
+		if s.jobs[i].ID == a.DeliveryID {
+			s.jobs[i].Attempts++
+		}
+	}
+	// Testlerde gecikme beklenmez; teslimat hemen tekrar alınabilir.
+	delete(s.leased, a.DeliveryID)
+	return nil
+}
+
+func (s *memStore) Prune(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruned = append(s.pruned, before)
+	return 0, nil
+}
+
+// testOptions, httptest sunucularına (http, loopback) gönderim yapabilmek için iç ağ hedeflerine izin verir.
+var testOptions = Options{AllowInsecureTargets: true}
+
+func testJob(id int64, url string, attempts int) Job {
+	return Job{
+		Delivery: Delivery{
+			ID:        id,
+			EventID:   "evt-" + strconv.FormatInt(id, 10),
+			EventType: "UserCreated",
+			TenantID:  "tenant-a",
+			Payload:   []byte(`{"event_type":"UserCreated"}`),
+			Attempts:  attempts,
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+func TestDispatcherDeliversSignedRequest(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(testJob(7, srv.URL, 0))
+	d := NewDispatcher(store, testOptions, zerolog.Nop())
+	if n, err := d.dispatchOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("dispatchOnce = %d, %v", n, err)
+	}
+
+	if store.status[7] != StatusDelivered {
+		t.Fatalf("status = %q, want delivered", store.status[7])
+	}
+	if got.Header.Get(HeaderEvent) != "UserCreated" || got.Header.Get(HeaderEventID) != "evt-7" || got.Header.Get(HeaderDelivery) != "7" {
+		t.Fatalf("headers = %v", got.Header)
+	}
+	if err := Verify("whsec_test", got.Header.Get(HeaderSignature), body, time.Minute, time.Now()); err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if a := store.attempts[0]; a.Attempt != 1 || a.StatusCode != http.StatusNoContent || a.Error != "" {
+		t.Fatalf("attempt = %+v", a)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(testJob(1, srv.URL, 2))
+	d := NewDispatcher(store, Options{MinBackoff: time.Second, MaxBackoff: time.Minute, AllowInsecureTargets: true}, zerolog.Nop())
+	d.dispatchOnce(context.Background())
+
+	if store.status[1] != StatusPending {
+		t.Fatalf("status = %q, want pending", store.status[1])
+	}
+	if store.retry[1] != 4*time.Second {
+		t.Fatalf("retry after = %v, want 4s", store.retry[1])
+	}
+	if a := store.attempts[0]; a.Attempt != 3 || a.StatusCode != 500 || a.Error != "HTTP 500" {
+		t.Fatalf("attempt = %+v", a)
+	}
+}
+
+func TestDispatcherDeadLettersAfterMaxAttempts(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(testJob(1, srv.URL, 0))
+	d := NewDispatcher(store, Options{MaxAttempts: 3, AllowInsecureTargets: true}, zerolog.Nop())
+	for range 5 {
+		d.dispatchOnce(context.Background())
+	}
+
+	if store.status[1] != StatusDead {
+		t.Fatalf("status = %q, want dead", store.status[1])
+	}
+	if calls != 3 || len(store.attempts) != 3 {
+		t.Fatalf("calls = %d attempts = %d, want 3", calls, len(store.attempts))
+	}
+}
+
+func TestDispatcherDoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(testJob(1, srv.URL, 0))
+	NewDispatcher(store, testOptions, zerolog.Nop()).dispatchOnce(context.Background())
+
+	if followed {
+		t.Fatal("redirect was followed")
+	}
+	if store.status[1] != StatusPending || store.attempts[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %q attempt = %+v", store.status[1], store.attempts[0])
+	}
+}
+
+func TestDispatcherConnectionError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	store := newMemStore(testJob(1, url, 0))
+	NewDispatcher(store, testOptions, zerolog.Nop()).dispatchOnce(context.Background())
+
+	if a := store.attempts[0]; a.StatusCode != 0 || a.Error == "" {
+		t.Fatalf("attempt = %+v, want connection error", a)
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := NewDispatcher(newMemStore(), Options{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, zerolog.Nop())
+	for attempts, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+// TestDispatcherRejectsInternalTargets: Geliştirme profili dışında http adreslerine ve iç ağa
+// çözümlenen hedeflere istek gönderilmemelidir.
+func TestDispatcherRejectsInternalTargets(t *testing.T) {
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ })
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+
+	store := newMemStore(testJob(1, plain.URL, 0), testJob(2, tlsSrv.URL, 0))
+	NewDispatcher(store, Options{}, zerolog.Nop()).dispatchOnce(context.Background())
+
+	if calls != 0 {
+		t.Fatalf("internal target received %d requests", calls)
+	}
+	if len(store.attempts) != 2 {
+		t.Fatalf("attempts = %+v, want 2", store.attempts)
+	}
+	for _, a := range store.attempts {
+		if a.StatusCode != 0 || a.Error == "" || store.status[a.DeliveryID] != StatusPending {
+			t.Fatalf("attempt = %+v status = %q, want rejected pending delivery", a, store.status[a.DeliveryID])
+		}
+		// https hedef ancak bağlantı anında, çözümlenen adres kontrol edilerek reddedilebilir.
+		if a.DeliveryID == 2 && !strings.Contains(a.Error, errInternalTarget.Error()) {
+			t.Fatalf("https loopback attempt = %+v, want rejection at dial time", a)
+		}
+	}
+}
+
+func TestDispatcherRunPrunes(t *testing.T) {
+	store := newMemStore()
+	d := NewDispatcher(store, Options{PollInterval: time.Hour, Retention: 24 * time.Hour}, zerolog.Nop())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	deadline := time.After(5 * time.Second)
+	for {
+		store.mu.Lock()
+		n := len(store.pruned)
+		store.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("dispatcher did not prune")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	if want := now.Add(-24 * time.Hour); !store.pruned[0].Equal(want) {
+		t.Fatalf("pruned before %v, want %v", store.pruned[0], want)
+	}
+}
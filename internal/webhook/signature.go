@@ -0,0 +1,70 @@
+// sentiric-user-service/internal/webhook/signature.go
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// İstek başlıkları.
+const (
+	HeaderSignature = "X-Sentiric-Signature"
+	HeaderEvent     = "X-Sentiric-Event"
+	HeaderEventID   = "X-Sentiric-Event-Id"
+	HeaderDelivery  = "X-Sentiric-Delivery"
+)
+
+// ErrInvalidSignature, imzanın eksik, bozuk, süresi geçmiş veya eşleşmiyor olduğunu belirtir.
+var ErrInvalidSignature = errors.New("geçersiz webhook imzası")
+
+// Sign, X-Sentiric-Signature başlık değerini üretir: "t=<unix>,v1=<hex>".
+// İmza HMAC-SHA256(secret, "<unix>.<body>") değeridir; zaman damgası imzaya dahil edildiği
+// için yakalanan bir istek tolerans süresi dışında tekrar oynatılamaz.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, mac(secret, ts, body))
+}
+
+// Verify, alıcı tarafta imzayı doğrular. tolerance, zaman damgası ile now arasındaki
+// kabul edilebilir en büyük farktır.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+	expected := mac(secret, ts, body)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
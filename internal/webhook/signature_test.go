@@ -0,0 +1,85 @@
+// sentiric-user-service/internal/webhook/signature_test.go
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"event_type":"UserCreated"}`)
+	header := Sign("whsec_test", now, body)
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("header = %q", header)
+	}
+	if err := Verify("whsec_test", header, body, time.Minute, now.Add(30*time.Second)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"event_type":"UserCreated"}`)
+	header := Sign("whsec_test", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		{"tampered body", "whsec_test", header, []byte(`{"event_type":"UserDeleted"}`), now},
+		{"wrong secret", "whsec_other", header, body, now},
+		{"expired", "whsec_test", header, body, now.Add(10 * time.Minute)},
+		{"from the future", "whsec_test", header, body, now.Add(-10 * time.Minute)},
+		{"missing signature", "whsec_test", "t=1700000000", body, now},
+		{"missing timestamp", "whsec_test", strings.TrimPrefix(header, "t=1700000000,"), body, now},
+		{"malformed", "whsec_test", "garbage", body, now},
+		{"empty", "whsec_test", "", body, now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, raw := range []string{"https://hooks.example.com/sentiric", "https://203.0.113.10:8443/in"} {
+		if err := ValidateURL(raw, false); err != nil {
+			t.Errorf("ValidateURL(%q) = %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"", "hooks.example.com", "/relative", "ftp://example.com", "https://"} {
+		if err := ValidateURL(raw, true); err == nil {
+			t.Errorf("ValidateURL(%q, insecure) succeeded", raw)
+		}
+	}
+	// Geliştirme profili dışında http ve iç ağ hedefleri reddedilir.
+	for _, raw := range []string{
+		"http://hooks.example.com/in",
+		"https://localhost/in",
+		"https://127.0.0.1/in",
+		"https://10.0.0.5:8080/in",
+		"https://192.168.1.1/in",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/in",
+		"https://[fd00::1]/in",
+		"https://[::ffff:127.0.0.1]/in",
+	} {
+		if err := ValidateURL(raw, false); err == nil {
+			t.Errorf("ValidateURL(%q) succeeded outside development", raw)
+		}
+		if err := ValidateURL(raw, true); err != nil {
+			t.Errorf("ValidateURL(%q, insecure) = %v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// sentiric-user-service/internal/webhook/store.go
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	queryInsertSubscription = `
+		INSERT INTO webhook_subscriptions (tenant_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, active, created_at`
+	querySubscriptionsByTenant = `
+		SELECT id, tenant_id, url, array_to_string(event_types, ','), active, created_at
+		FROM webhook_subscriptions WHERE tenant_id = $1 ORDER BY created_at`
+	queryDeleteSubscription = `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+	queryClaimDeliveries = `
+		WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND s.active
+			ORDER BY d.id
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2::float8 * INTERVAL '1 millisecond'
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING ` + deliveryColumns + `, s.url, s.secret`
+	queryInsertAttempt = `
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''), $5)`
+	queryUpdateDelivery = `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+		    status = $2::text,
+		    last_error = NULLIF($3, ''),
+		    next_attempt_at = NOW() + $4::float8 * INTERVAL '1 millisecond',
+		    delivered_at = CASE WHEN $2::text = 'delivered' THEN NOW() END
+		WHERE id = $1`
+	queryReplayDelivery = `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = NULL, delivered_at = NULL
+		WHERE id = $1`
+	queryDeliveries = `
+		SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+		WHERE ($1::text = '' OR d.tenant_id = $1) AND ($2::text = '' OR d.status = $2)
+		ORDER BY d.id DESC
+		LIMIT $3`
+	// queryPruneDeliveries, tamamlanmış eski teslimatları siler; denemeler ON DELETE CASCADE ile gider.
+	queryPruneDeliveries = `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`
+	queryAttempts        = `
+		SELECT id, delivery_id, attempt, attempted_at, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms
+		FROM webhook_attempts WHERE delivery_id = $1 ORDER BY id`
+
+	deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.tenant_id, d.payload, d.status,
+		d.attempts, d.next_attempt_at, COALESCE(d.last_error, ''), d.created_at, d.delivered_at`
+)
+
+// defaultDeliveryLimit: DeliveryFilter.Limit verilmediğinde dönen en fazla kayıt sayısı.
+const defaultDeliveryLimit = 100
+
+// SQLStore, webhook tablolarını database/sql üzerinden yönetir.
+type SQLStore struct {
+	db            *sql.DB
+	allowInsecure bool
+}
+
+// NewSQLStore, bir SQLStore oluşturur. allowInsecureURLs yalnızca geliştirme profillerinde
+// true olmalıdır; http ve iç ağ adresli aboneliklere izin verir (bkz. ValidateURL).
+func NewSQLStore(db *sql.DB, allowInsecureURLs bool) *SQLStore {
+	return &SQLStore{db: db, allowInsecure: allowInsecureURLs}
+}
+
+// --- Abonelikler ---
+
+// CreateSubscription, rastgele bir imza anahtarıyla yeni bir abonelik oluşturur.
+func (s *SQLStore) CreateSubscription(ctx context.Context, tenantID, rawURL string, eventTypes []string) (*Subscription, error) {
+	if err := ValidateURL(rawURL, s.allowInsecure); err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	sub := &Subscription{TenantID: tenantID, URL: rawURL, Secret: secret, EventTypes: eventTypes}
+	err = s.db.QueryRowContext(ctx, queryInsertSubscription, tenantID, rawURL, secret, eventTypes).
+		Scan(&sub.ID, &sub.Active, &sub.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions, tenant'ın aboneliklerini imza anahtarı olmadan döner.
+func (s *SQLStore) ListSubscriptions(ctx context.Context, tenantID string) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, querySubscriptionsByTenant, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		var types string
+		if err := rows.Scan(&sub.ID, &sub.TenantID, &sub.URL, &types, &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		if types != "" {
+			sub.EventTypes = strings.Split(types, ",")
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription, aboneliği ve teslimat geçmişini siler.
+func (s *SQLStore) DeleteSubscription(ctx context.Context, id string) error {
+	return s.execOne(ctx, queryDeleteSubscription, id)
+}
+
+// --- Teslimatlar ---
+
+func (s *SQLStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Job, error) {
+	rows, err := s.db.QueryContext(ctx, queryClaimDeliveries, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		if err := scanDelivery(rows, &j.Delivery, &j.URL, &j.Secret); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID < jobs[k].ID })
+	return jobs, nil
+}
+
+func (s *SQLStore) RecordAttempt(ctx context.Context, a Attempt, status string, retryAfter time.Duration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, queryInsertAttempt, a.DeliveryID, a.Attempt, a.StatusCode, a.Error, a.Duration.Milliseconds()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, queryUpdateDelivery, a.DeliveryID, status, a.Error, retryAfter.Milliseconds()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, queryPruneDeliveries, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Replay, teslimatı (dead veya delivered olsa da) deneme sayacını sıfırlayarak tekrar kuyruğa alır.
+// Önceki denemeler geçmişte kalır.
+func (s *SQLStore) Replay(ctx context.Context, deliveryID int64) error {
+	return s.execOne(ctx, queryReplayDelivery, deliveryID)
+}
+
+// ListDeliveries, teslimatları en yeniden eskiye döner.
+func (s *SQLStore) ListDeliveries(ctx context.Context, f DeliveryFilter) ([]Delivery, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	rows, err := s.db.QueryContext(ctx, queryDeliveries, f.TenantID, f.Status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ListAttempts, bir teslimatın denemelerini sırayla döner.
+func (s *SQLStore) ListAttempts(ctx context.Context, deliveryID int64) ([]Attempt, error) {
+	rows, err := s.db.QueryContext(ctx, queryAttempts, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []Attempt
+	for rows.Next() {
+		var a Attempt
+		var durationMs int64
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.AttemptedAt, &a.StatusCode, &a.Error, &durationMs); err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// --- Helper ---
+
+func (s *SQLStore) execOne(ctx context.Context, query string, args ...any) error {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanDelivery, deliveryColumns sırasındaki kolonları (ve varsa ek kolonları) okur.
+func scanDelivery(rows *sql.Rows, d *Delivery, extra ...any) error {
+	var payload []byte
+	var deliveredAt sql.NullTime
+	dest := append([]any{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.TenantID, &payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &deliveredAt}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	d.Payload = payload
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,159 @@
+// sentiric-user-service/internal/webhook/store_test.go
+package webhook
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sentiric/sentiric-user-service/internal/database"
+)
+
+const testDSNEnv = "USER_SERVICE_TEST_DSN"
+
+// TestSQLStoreFanOutAndReplay, 0004 tetikleyicisinin outbox olaylarını eşleşen aboneliklere
+// dağıttığını ve teslimat yaşam döngüsünü gerçek PostgreSQL'e karşı doğrular.
+func TestSQLStoreFanOutAndReplay(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s tanımlı değil, webhook PostgreSQL testi atlandı", testDSNEnv)
+	}
+	ctx := context.Background()
+	log := zerolog.Nop()
+
+	db, err := database.Connect(dsn, 1, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := "webhook_" + uuid.NewString()
+	if _, err := db.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES ($1, 'Webhook')`, tenant); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.ExecContext(ctx, `DELETE FROM users WHERE tenant_id = $1`, tenant)
+		db.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenant)
+		db.ExecContext(ctx, `DELETE FROM outbox_events WHERE tenant_id = $1`, tenant)
+	})
+
+	store := NewSQLStore(db, false)
+	if _, err := store.CreateSubscription(ctx, tenant, "not-a-url", nil); err == nil {
+		t.Fatal("CreateSubscription accepted an invalid URL")
+	}
+	if _, err := store.CreateSubscription(ctx, tenant, "http://hooks.example.com/in", nil); err == nil {
+		t.Fatal("CreateSubscription accepted an http URL outside development")
+	}
+	sub, err := store.CreateSubscription(ctx, tenant, "https://hooks.example.com/in", []string{"UserCreated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sub.Active || len(sub.Secret) < 32 {
+		t.Fatalf("subscription = %+v", sub)
+	}
+	subs, err := store.ListSubscriptions(ctx, tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Secret != "" || !slices.Equal(subs[0].EventTypes, []string{"UserCreated"}) {
+		t.Fatalf("subscriptions = %+v", subs)
+	}
+
+	// Yalnızca UserCreated aboneliğe eşleşir; ContactAdded dağıtılmaz.
+	var userID string
+	if err := db.QueryRowContext(ctx, `INSERT INTO users (tenant_id, user_type) VALUES ($1, 'agent') RETURNING id`, tenant).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO contacts (user_id, contact_type, contact_value, is_primary) VALUES ($1, 'email', $2, true)`, userID, tenant+"@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := store.ListDeliveries(ctx, DeliveryFilter{TenantID: tenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].EventType != "UserCreated" || deliveries[0].Status != StatusPending {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	delivery := deliveries[0]
+
+	claimed, err := store.ClaimDue(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job *Job
+	for i := range claimed {
+		if claimed[i].ID == delivery.ID {
+			job = &claimed[i]
+		}
+	}
+	if job == nil || job.URL != sub.URL || job.Secret != sub.Secret {
+		t.Fatalf("claimed job = %+v", job)
+	}
+
+	if err := store.RecordAttempt(ctx, Attempt{DeliveryID: job.ID, Attempt: 1, StatusCode: 500, Error: "HTTP 500", Duration: 12 * time.Millisecond}, StatusDead, 0); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := store.ListDeliveries(ctx, DeliveryFilter{TenantID: tenant, Status: StatusDead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].Attempts != 1 || dead[0].LastError != "HTTP 500" {
+		t.Fatalf("dead deliveries = %+v", dead)
+	}
+
+	if err := store.Replay(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordAttempt(ctx, Attempt{DeliveryID: job.ID, Attempt: 1, StatusCode: 204, Duration: 5 * time.Millisecond}, StatusDelivered, 0); err != nil {
+		t.Fatal(err)
+	}
+	delivered, err := store.ListDeliveries(ctx, DeliveryFilter{TenantID: tenant, Status: StatusDelivered})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 1 || delivered[0].DeliveredAt == nil || delivered[0].LastError != "" {
+		t.Fatalf("delivered = %+v", delivered)
+	}
+
+	attempts, err := store.ListAttempts(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[0].StatusCode != 500 || attempts[1].StatusCode != 204 || attempts[1].Error != "" {
+		t.Fatalf("attempts = %+v", attempts)
+	}
+
+	// Tamamlanmış teslimat retention sonunda denemeleriyle birlikte silinir.
+	if _, err := store.Prune(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, err := store.ListDeliveries(ctx, DeliveryFilter{TenantID: tenant}); err != nil || len(remaining) != 0 {
+		t.Fatalf("deliveries after prune = %+v, %v", remaining, err)
+	}
+	if attempts, err := store.ListAttempts(ctx, job.ID); err != nil || len(attempts) != 0 {
+		t.Fatalf("attempts after prune = %+v, %v", attempts, err)
+	}
+
+	if err := store.Replay(ctx, -1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Replay(missing) = %v, want ErrNotFound", err)
+	}
+	if err := store.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteSubscription(ctx, sub.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second DeleteSubscription = %v, want ErrNotFound", err)
+	}
+}
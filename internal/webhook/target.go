@@ -0,0 +1,66 @@
+// sentiric-user-service/internal/webhook/target.go
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// errInternalTarget, webhook hedefinin iç ağa çözümlendiğini belirtir. Abonelik adresleri
+// tenant'lardan geldiği için servisin erişebildiği iç adreslere istek gönderilmez (SSRF).
+var errInternalTarget = errors.New("webhook hedefi iç ağ adresine çözümleniyor")
+
+// internalPrefixes, net/netip yardımcılarının kapsamadığı özel amaçlı bloklardır.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "bu ağ"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+}
+
+// isInternal, adresin loopback, private, link-local veya yönlendirilemeyen bir adres olup olmadığını döner.
+func isInternal(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, p := range internalPrefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHost, abonelik oluşturulurken açıkça iç ağı gösteren host'ları reddeder. Alan adları
+// burada çözümlenmez; DNS sonradan değişebileceği için asıl kontrol dialControl'dedir.
+func checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", errInternalTarget, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && isInternal(ip) {
+		return fmt.Errorf("%w: %s", errInternalTarget, host)
+	}
+	return nil
+}
+
+// dialControl, bağlantı çözümlenmiş IP'ye açılmadan hemen önce çağrılır; iç ağ adreslerini reddeder.
+// Kontrol DNS çözümlemesinden sonra yapıldığı için DNS rebinding ile atlatılamaz.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if isInternal(ip) {
+		return fmt.Errorf("%w: %s", errInternalTarget, ip)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// sentiric-user-service/internal/webhook/webhook.go
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Teslimat durumları (webhook_deliveries.status).
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusDead: Deneme hakkı bitmiş teslimat. Yalnızca Replay ile tekrar gönderilir.
+	StatusDead = "dead"
+)
+
+// ErrNotFound, istenen abonelik veya teslimatın bulunamadığını belirtir.
+var ErrNotFound = errors.New("webhook kaydı bulunamadı")
+
+// Subscription, bir tenant'ın olayları alacağı HTTP uç noktasıdır.
+type Subscription struct {
+	ID       string
+	TenantID string
+	URL      string
+	// Secret, X-Sentiric-Signature imzasının anahtarıdır; yalnızca oluşturulurken gösterilir.
+	Secret string
+	// EventTypes boşsa tenant'ın tüm olayları gönderilir.
+	EventTypes []string
+	Active     bool
+	CreatedAt  time.Time
+}
+
+// Delivery, bir olayın bir aboneliğe teslimatıdır.
+type Delivery struct {
+	ID             int64
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	TenantID       string
+	// Payload, uç noktaya gönderilen gövdedir (outbox zarfı ile aynı biçim).
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+// Job, dispatcher'ın gönderim için kiraladığı teslimattır.
+type Job struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+// Attempt, tek bir gönderim denemesinin kaydıdır.
+type Attempt struct {
+	ID          int64
+	DeliveryID  int64
+	Attempt     int
+	AttemptedAt time.Time
+	// StatusCode, HTTP yanıt kodudur; bağlantı hatalarında 0'dır.
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+}
+
+// DeliveryFilter, teslimat sorgusunu daraltır. Boş alanlar filtre uygulamaz.
+type DeliveryFilter struct {
+	TenantID string
+	Status   string
+	Limit    int
+}
+
+// Store, dispatcher'ın teslimat tablosu üzerindeki işlemlerini soyutlar.
+type Store interface {
+	// ClaimDue, zamanı gelmiş teslimatları lease süresince kiralar.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	// RecordAttempt, denemeyi kaydeder ve teslimatı status durumuna geçirir. status pending
+	// ise teslimat retryAfter sonra tekrar denenir.
+	RecordAttempt(ctx context.Context, a Attempt, status string, retryAfter time.Duration) error
+	// Prune, before'dan önce oluşturulmuş ve artık beklemeyen (delivered, dead) teslimatları
+	// denemeleriyle birlikte siler ve silinen teslimat sayısını döner.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ValidateURL, abonelik adresinin mutlak bir https adresi olduğunu ve açıkça iç ağı (loopback,
+// private, link-local) hedeflemediğini doğrular. Alan adları gönderim anında çözümlenip yine
+// kontrol edilir. allowInsecure (yalnızca geliştirme profilleri) http ve iç ağ adreslerine izin verir.
+func ValidateURL(raw string, allowInsecure bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("geçersiz webhook adresi: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("geçersiz webhook adresi: %q (http veya https mutlak adres olmalı)", raw)
+	}
+	if allowInsecure {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("geçersiz webhook adresi: %q (geliştirme profili dışında https zorunludur)", raw)
+	}
+	return checkHost(u.Hostname())
+}